@@ -15,6 +15,234 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/v1/admin/payment/discrepancies": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "current",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "paid_but_failed",
+                            "mismatch",
+                            "query_failed",
+                            "refund_failed"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "DiscrepancyPaidButFailed",
+                            "DiscrepancyMismatch",
+                            "DiscrepancyQueryFailed",
+                            "DiscrepancyRefundFailed"
+                        ],
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "resolved",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payment.ListDiscrepanciesResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/payment/discrepancies/{id}/resolve": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "差异ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "处理说明",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.ResolveDiscrepancyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payment.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/payment/notify-logs": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "current",
+                        "in": "query"
+                    },
+                    {
+                        "maxLength": 64,
+                        "type": "string",
+                        "name": "out_trade_no",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "success",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payment.ListNotifyLogsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/payment/notify-logs/{id}/replay": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "回调记录ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/payment.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/payment.PaymentNotifyLog"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/payment/orders/{out_trade_no}/retry-refund": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "订单号",
+                        "name": "out_trade_no",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/payment.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/payment.PaymentOrder"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/payment.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/payment.Response"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/admin/projects": {
             "get": {
                 "produces": [
@@ -220,210 +448,197 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/oauth/callback": {
-            "post": {
+        "/api/v1/notifications": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "oauth"
+                    "notification"
                 ],
                 "parameters": [
                     {
-                        "description": "request body",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/oauth.CallbackRequest"
-                        }
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "current",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "unread_only",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/oauth.CallbackResponse"
+                            "$ref": "#/definitions/notification.ListNotificationsResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/oauth/login": {
-            "get": {
+        "/api/v1/notifications/read-all": {
+            "put": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "oauth"
+                    "notification"
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/oauth.GetLoginURLResponse"
+                            "$ref": "#/definitions/notification.NotificationResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/oauth/logout": {
+        "/api/v1/notifications/unread-count": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "oauth"
+                    "notification"
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/oauth.LogoutResponse"
+                            "$ref": "#/definitions/notification.UnreadCountResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/oauth/user-info": {
-            "get": {
+        "/api/v1/notifications/{id}/read": {
+            "put": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "oauth"
+                    "notification"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "通知ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/oauth.UserInfoResponse"
+                            "$ref": "#/definitions/notification.NotificationResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/projects": {
-            "get": {
+        "/api/v1/oauth/callback": {
+            "post": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "project"
+                    "oauth"
                 ],
                 "parameters": [
                     {
-                        "minimum": 1,
-                        "type": "integer",
-                        "name": "current",
-                        "in": "query"
-                    },
-                    {
-                        "maximum": 100,
-                        "minimum": 1,
-                        "type": "integer",
-                        "name": "size",
-                        "in": "query"
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "csv",
-                        "name": "tags",
-                        "in": "query"
+                        "description": "request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/oauth.CallbackRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/project.ListProjectsResponse"
+                            "$ref": "#/definitions/oauth.CallbackResponse"
                         }
                     }
                 }
-            },
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/api/v1/oauth/login": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "project"
+                    "oauth"
                 ],
-                "parameters": [
-                    {
-                        "description": "项目信息",
-                        "name": "project",
-                        "in": "body",
-                        "required": true,
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/project.CreateProjectRequestBody"
+                            "$ref": "#/definitions/oauth.GetLoginURLResponse"
                         }
                     }
+                }
+            }
+        },
+        "/api/v1/oauth/logout": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/project.ProjectResponse"
+                            "$ref": "#/definitions/oauth.LogoutResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/projects/mine": {
+        "/api/v1/oauth/user-info": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "project"
-                ],
-                "parameters": [
-                    {
-                        "minimum": 1,
-                        "type": "integer",
-                        "name": "current",
-                        "in": "query"
-                    },
-                    {
-                        "maximum": 100,
-                        "minimum": 1,
-                        "type": "integer",
-                        "name": "size",
-                        "in": "query"
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "csv",
-                        "name": "tags",
-                        "in": "query"
-                    }
+                    "oauth"
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/project.ListProjectsResponse"
+                            "$ref": "#/definitions/oauth.UserInfoResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/projects/received": {
+        "/api/v1/payment/coupons": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "project"
+                    "payment"
                 ],
                 "parameters": [
                     {
@@ -433,9 +648,9 @@ const docTemplate = `{
                         "in": "query"
                     },
                     {
-                        "maxLength": 255,
+                        "maxLength": 64,
                         "type": "string",
-                        "name": "search",
+                        "name": "project_id",
                         "in": "query"
                     },
                     {
@@ -450,56 +665,30 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/project.ListReceiveHistoryResponse"
+                            "$ref": "#/definitions/payment.ListCouponsResponse"
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/projects/received/chart": {
-            "get": {
-                "produces": [
+            },
+            "post": {
+                "consumes": [
                     "application/json"
                 ],
-                "tags": [
-                    "project"
-                ],
-                "parameters": [
-                    {
-                        "maximum": 180,
-                        "minimum": 1,
-                        "type": "integer",
-                        "name": "day",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/project.ListReceiveHistoryChartResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/v1/projects/{id}": {
-            "get": {
-                "description": "获取指定项目所有信息以及领取情况 (Get all information and claim status for a specific project)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "project"
+                    "payment"
                 ],
-                "summary": "获取指定项目信息 (Get specific project information)",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "项目ID (Project ID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "优惠码信息",
+                        "name": "coupon",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payment.CreateCouponRequestBody"
+                        }
                     }
                 ],
                 "responses": {
@@ -508,13 +697,13 @@ const docTemplate = `{
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/project.ProjectResponse"
+                                    "$ref": "#/definitions/payment.Response"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/project.GetProjectResponseData"
+                                            "$ref": "#/definitions/payment.Coupon"
                                         }
                                     }
                                 }
@@ -522,150 +711,270 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/api/v1/payment/coupons/{id}": {
+            "delete": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "project"
+                    "payment"
                 ],
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "项目ID",
+                        "type": "integer",
+                        "description": "优惠码ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "项目信息",
-                        "name": "project",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/project.UpdateProjectRequestBody"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/project.ProjectResponse"
+                            "$ref": "#/definitions/payment.Response"
                         }
                     }
                 }
-            },
-            "delete": {
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/api/v1/payment/orders/paid": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "project"
+                    "payment"
                 ],
                 "parameters": [
                     {
+                        "enum": [
+                            "LDC",
+                            "CNY",
+                            "CREDIT",
+                            "LDC"
+                        ],
                         "type": "string",
-                        "description": "项目ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "x-enum-comments": {
+                            "CurrencyCNY": "人民币，精确到 0.01",
+                            "CurrencyCredit": "整数积分，仅以整数单位结算",
+                            "CurrencyLDC": "LDC 积分，精确到 0.01"
+                        },
+                        "x-enum-descriptions": [
+                            "LDC 积分，精确到 0.01",
+                            "人民币，精确到 0.01",
+                            "整数积分，仅以整数单位结算"
+                        ],
+                        "x-enum-varnames": [
+                            "CurrencyLDC",
+                            "CurrencyCNY",
+                            "CurrencyCredit",
+                            "DefaultCurrency"
+                        ],
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "current",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "maxLength": 64,
+                        "type": "string",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            0,
+                            1,
+                            2,
+                            3,
+                            4,
+                            5,
+                            6
+                        ],
+                        "type": "integer",
+                        "format": "int32",
+                        "x-enum-varnames": [
+                            "OrderStatusPending",
+                            "OrderStatusPaid",
+                            "OrderStatusCompleted",
+                            "OrderStatusRefunding",
+                            "OrderStatusRefunded",
+                            "OrderStatusFailed",
+                            "OrderStatusRefundFailed"
+                        ],
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/project.ProjectResponse"
+                            "$ref": "#/definitions/payment.ListOrdersResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/projects/{id}/pending-payment": {
+        "/api/v1/payment/orders/sold": {
             "get": {
-                "description": "只返回指定项目下当前用户已有且未过期的待支付订单，不重新占用库存或刷新有效期",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "payment"
                 ],
-                "summary": "获取当前用户的待支付订单",
                 "parameters": [
                     {
+                        "enum": [
+                            "LDC",
+                            "CNY",
+                            "CREDIT",
+                            "LDC"
+                        ],
                         "type": "string",
-                        "description": "项目ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/payment.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/payment.PendingPaymentResponseData"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
+                        "x-enum-comments": {
+                            "CurrencyCNY": "人民币，精确到 0.01",
+                            "CurrencyCredit": "整数积分，仅以整数单位结算",
+                            "CurrencyLDC": "LDC 积分，精确到 0.01"
+                        },
+                        "x-enum-descriptions": [
+                            "LDC 积分，精确到 0.01",
+                            "人民币，精确到 0.01",
+                            "整数积分，仅以整数单位结算"
+                        ],
+                        "x-enum-varnames": [
+                            "CurrencyLDC",
+                            "CurrencyCNY",
+                            "CurrencyCredit",
+                            "DefaultCurrency"
+                        ],
+                        "name": "currency",
+                        "in": "query"
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/payment.Response"
-                        }
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "current",
+                        "in": "query"
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/project.ProjectResponse"
-                        }
+                    {
+                        "type": "string",
+                        "name": "end_time",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    {
+                        "maxLength": 64,
+                        "type": "string",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            0,
+                            1,
+                            2,
+                            3,
+                            4,
+                            5,
+                            6
+                        ],
+                        "type": "integer",
+                        "format": "int32",
+                        "x-enum-varnames": [
+                            "OrderStatusPending",
+                            "OrderStatusPaid",
+                            "OrderStatusCompleted",
+                            "OrderStatusRefunding",
+                            "OrderStatusRefunded",
+                            "OrderStatusFailed",
+                            "OrderStatusRefundFailed"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/payment.Response"
+                            "$ref": "#/definitions/payment.ListOrdersResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/projects/{id}/receivers": {
+        "/api/v1/payment/orders/sold/summary": {
             "get": {
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "project"
+                    "payment"
                 ],
                 "parameters": [
                     {
+                        "enum": [
+                            "LDC",
+                            "CNY",
+                            "CREDIT",
+                            "LDC"
+                        ],
                         "type": "string",
-                        "description": "项目ID (Project ID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "x-enum-comments": {
+                            "CurrencyCNY": "人民币，精确到 0.01",
+                            "CurrencyCredit": "整数积分，仅以整数单位结算",
+                            "CurrencyLDC": "LDC 积分，精确到 0.01"
+                        },
+                        "x-enum-descriptions": [
+                            "LDC 积分，精确到 0.01",
+                            "人民币，精确到 0.01",
+                            "整数积分，仅以整数单位结算"
+                        ],
+                        "x-enum-varnames": [
+                            "CurrencyLDC",
+                            "CurrencyCNY",
+                            "CurrencyCredit",
+                            "DefaultCurrency"
+                        ],
+                        "name": "currency",
+                        "in": "query"
                     },
                     {
                         "minimum": 1,
@@ -674,9 +983,14 @@ const docTemplate = `{
                         "in": "query"
                     },
                     {
-                        "maxLength": 1024,
                         "type": "string",
-                        "name": "search",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "maxLength": 64,
+                        "type": "string",
+                        "name": "project_id",
                         "in": "query"
                     },
                     {
@@ -685,19 +999,24 @@ const docTemplate = `{
                         "type": "integer",
                         "name": "size",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "start_time",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/project.ProjectResponse"
+                            "$ref": "#/definitions/payment.SalesSummaryResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/projects/{id}/report": {
+        "/api/v1/payment/orders/{out_trade_no}/refund": {
             "post": {
                 "consumes": [
                     "application/json"
@@ -706,23 +1025,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "project"
+                    "payment"
                 ],
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "项目ID",
-                        "name": "id",
+                        "description": "订单号",
+                        "name": "out_trade_no",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "举报信息",
-                        "name": "project",
+                        "description": "退款原因及是否收回并作废已发放的 item",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/project.ReportProjectRequestBody"
+                            "$ref": "#/definitions/payment.RefundOrderRequest"
                         }
                     }
                 ],
@@ -730,32 +1049,72 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/project.ProjectResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/payment.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/payment.PaymentOrder"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/payment.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/payment.PaymentOrder"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/payment.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/payment.Response"
                         }
                     }
                 }
             }
         },
-        "/api/v1/ready": {
+        "/api/v1/project-templates": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "project"
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/health.ReadyResponse"
+                            "$ref": "#/definitions/project.ListProjectTemplatesResponse"
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/tags": {
-            "get": {
+            },
+            "post": {
                 "consumes": [
                     "application/json"
                 ],
@@ -765,403 +1124,3623 @@ const docTemplate = `{
                 "tags": [
                     "project"
                 ],
+                "parameters": [
+                    {
+                        "description": "模板信息",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/project.ProjectTemplateRequestBody"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/project.ListTagsResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/project.ProjectResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/project.ProjectTemplate"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "admin.ListProjectsResponse": {
-            "type": "object",
-            "properties": {
-                "data": {
-                    "$ref": "#/definitions/admin.ListProjectsResponseData"
-                },
-                "error_msg": {
-                    "type": "string"
-                }
-            }
         },
-        "admin.ListProjectsResponseData": {
-            "type": "object",
-            "properties": {
-                "results": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/admin.ListProjectsResponseDataResult"
+        "/api/v1/project-templates/{id}": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "模板ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "模板信息",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/project.ProjectTemplateRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ProjectResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "模板ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ProjectResponse"
+                        }
                     }
-                },
-                "total": {
-                    "type": "integer"
                 }
             }
         },
-        "admin.ListProjectsResponseDataResult": {
-            "type": "object",
-            "properties": {
-                "description": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
+        "/api/v1/project-templates/{id}/projects": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "模板ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "新项目的时间与库存",
+                        "name": "project",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/project.CreateFromSettingsRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ProjectResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "current",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "name": "tags",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ListProjectsResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "description": "项目信息",
+                        "name": "project",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/project.CreateProjectRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ProjectResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/mine": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "current",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "name": "tags",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ListProjectsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/received": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "current",
+                        "in": "query"
+                    },
+                    {
+                        "maxLength": 255,
+                        "type": "string",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ListReceiveHistoryResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/received/chart": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "maximum": 180,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "day",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ListReceiveHistoryChartResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/{id}": {
+            "get": {
+                "description": "获取指定项目所有信息以及领取情况 (Get all information and claim status for a specific project)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "summary": "获取指定项目信息 (Get specific project information)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID (Project ID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/project.ProjectResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/project.GetProjectResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "项目信息",
+                        "name": "project",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/project.UpdateProjectRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ProjectResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ProjectResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/{id}/clone": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "新项目的时间与库存",
+                        "name": "project",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/project.CreateFromSettingsRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ProjectResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/{id}/draw": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.GetProjectDrawResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/{id}/entries": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ProjectResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/{id}/invites": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "current",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ListProjectInvitesResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "邀请码生成参数",
+                        "name": "invites",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/project.CreateProjectInvitesRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/project.ProjectResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/project.ProjectInvite"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/{id}/invites/{invite_id}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "邀请码ID",
+                        "name": "invite_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ProjectResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/{id}/items": {
+            "delete": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "待删除的 item",
+                        "name": "items",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/project.DeleteProjectItemsRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.DeleteProjectItemsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/{id}/items/export": {
+            "get": {
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV 或 JSONL 文件",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/project.ProjectResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/{id}/items/import": {
+            "post": {
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "导入文件(.csv 或 .txt)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "enable_filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ImportProjectItemsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/{id}/items/{item_id}": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "item ID",
+                        "name": "item_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "新内容",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/project.ReplaceProjectItemRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ProjectResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/{id}/pending-payment": {
+            "get": {
+                "description": "只返回指定项目下当前用户已有且未过期的待支付订单，不重新占用库存或刷新有效期",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "summary": "获取当前用户的待支付订单",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/payment.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/payment.PendingPaymentResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/payment.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/project.ProjectResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/payment.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/{id}/price": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.GetProjectPriceResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/{id}/receivers": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID (Project ID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "current",
+                        "in": "query"
+                    },
+                    {
+                        "maxLength": 1024,
+                        "type": "string",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ProjectResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/{id}/recurrence": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.RecurrenceResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "周期规则",
+                        "name": "recurrence",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/project.RecurrenceRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.RecurrenceResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ProjectResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/{id}/report": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "举报信息",
+                        "name": "project",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/project.ReportProjectRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ProjectResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/{id}/reserve": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "预备 item",
+                        "name": "items",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/project.AddReserveItemsRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/project.ProjectResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/{id}/rounds": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "current",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ListProjectRoundsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/{id}/waitlist": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.GetProjectWaitlistResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/project.ProjectResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/project.ProjectWaitlist"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ProjectResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/projects/{id}/waitlist/claim": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "项目ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/project.ProjectResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/project.ClaimProjectWaitlistResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ready": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.ReadyResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tags": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "project"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/project.ListTagsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/tokens": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "current",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/oauth.ListAPITokensResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "parameters": [
+                    {
+                        "description": "Token 信息",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/oauth.CreateAPITokenRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/oauth.CreateAPITokenResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/tokens/{id}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Token ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/oauth.RevokeAPITokenResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.ListWebhooksResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "parameters": [
+                    {
+                        "description": "订阅信息",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/webhook.CreateWebhookRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.CreateWebhookResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/{id}": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "订阅信息",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/webhook.UpdateWebhookRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/webhook.WebhookResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/webhook.Webhook"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.WebhookResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/{id}/deliveries": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "current",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            0,
+                            1,
+                            2,
+                            3
+                        ],
+                        "type": "integer",
+                        "format": "int32",
+                        "x-enum-varnames": [
+                            "DeliveryStatusPending",
+                            "DeliveryStatusSucceeded",
+                            "DeliveryStatusRetrying",
+                            "DeliveryStatusDead"
+                        ],
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.ListWebhookDeliveriesResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/{id}/deliveries/{delivery_id}/redeliver": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "投递记录 ID",
+                        "name": "delivery_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/webhook.WebhookResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/webhook.WebhookDelivery"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "admin.ListProjectsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/admin.ListProjectsResponseData"
+                },
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.ListProjectsResponseData": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/admin.ListProjectsResponseDataResult"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "admin.ListProjectsResponseDataResult": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "nickname": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/project.ProjectStatus"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.ReviewProjectRequest": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "enum": [
+                        0,
+                        1,
+                        2
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/project.ProjectStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "admin.ReviewProjectResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.listUsersResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object",
+                    "properties": {
+                        "total": {
+                            "type": "integer"
+                        },
+                        "users": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/oauth.User"
+                            }
+                        }
+                    }
+                },
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "dashboard.DashboardDataResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "health.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "health.ReadyResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "notification.ListNotificationsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/notification.ListNotificationsResponseData"
+                },
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "notification.ListNotificationsResponseData": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/notification.Notification"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "notification.Notification": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "project_id": {
+                    "type": "string"
+                },
+                "read_at": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/notification.Type"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "notification.NotificationResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "notification.Type": {
+            "type": "string",
+            "enum": [
+                "waitlist.offered",
+                "order.refunded",
+                "report.reviewed",
+                "project.hidden",
+                "project.reviewed",
+                "item.replaced"
+            ],
+            "x-enum-varnames": [
+                "TypeWaitlistOffered",
+                "TypeOrderRefunded",
+                "TypeReportReviewed",
+                "TypeProjectHidden",
+                "TypeProjectReviewed",
+                "TypeItemReplaced"
+            ]
+        },
+        "notification.UnreadCountResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/notification.UnreadCountResponseData"
+                },
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "notification.UnreadCountResponseData": {
+            "type": "object",
+            "properties": {
+                "unread": {
+                    "type": "integer"
+                }
+            }
+        },
+        "oauth.APIToken": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expire_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "last_used_ip": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "oauth.BasicUserInfo": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "nickname": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "trust_level": {
+                    "$ref": "#/definitions/oauth.TrustLevel"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "oauth.CallbackRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "oauth.CallbackResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "oauth.CreateAPITokenRequestBody": {
+            "type": "object",
+            "required": [
+                "expire_at",
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expire_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 1
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/oauth.TokenScope"
+                    }
+                }
+            }
+        },
+        "oauth.CreateAPITokenResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/oauth.CreateAPITokenResponseData"
+                },
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "oauth.CreateAPITokenResponseData": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expire_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "last_used_ip": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "token": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "oauth.GetLoginURLResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "string"
+                },
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "oauth.ListAPITokensResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/oauth.ListAPITokensResponseData"
+                },
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "oauth.ListAPITokensResponseData": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/oauth.APIToken"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "oauth.LogoutResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "oauth.RevokeAPITokenResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "oauth.TokenScope": {
+            "type": "string",
+            "enum": [
+                "projects:write",
+                "items:read",
+                "orders:read"
+            ],
+            "x-enum-varnames": [
+                "TokenScopeProjectsWrite",
+                "TokenScopeItemsRead",
+                "TokenScopeOrdersRead"
+            ]
+        },
+        "oauth.TrustLevel": {
+            "type": "integer",
+            "format": "int32",
+            "enum": [
+                0,
+                1,
+                2,
+                3,
+                4
+            ],
+            "x-enum-varnames": [
+                "TrustLevelNewUser",
+                "TrustLevelBasicUser",
+                "TrustLevelUser",
+                "TrustLevelActiveUser",
+                "TrustLevelLeader"
+            ]
+        },
+        "oauth.User": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "is_admin": {
+                    "type": "boolean"
+                },
+                "last_login_at": {
+                    "type": "string"
+                },
                 "nickname": {
                     "type": "string"
                 },
-                "status": {
-                    "$ref": "#/definitions/project.ProjectStatus"
+                "score": {
+                    "type": "integer"
+                },
+                "trust_level": {
+                    "$ref": "#/definitions/oauth.TrustLevel"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                },
+                "violation_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "oauth.UserInfoResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/oauth.BasicUserInfo"
+                },
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "payment.Coupon": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "creator_id": {
+                    "type": "integer"
+                },
+                "expire_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max_uses": {
+                    "type": "integer"
+                },
+                "project_id": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/payment.CouponType"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "used_count": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "payment.CouponType": {
+            "type": "integer",
+            "format": "int32",
+            "enum": [
+                0,
+                1
+            ],
+            "x-enum-varnames": [
+                "CouponTypePercent",
+                "CouponTypeFixed"
+            ]
+        },
+        "payment.CreateCouponRequestBody": {
+            "type": "object",
+            "required": [
+                "code",
+                "expire_at",
+                "max_uses"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 4
+                },
+                "expire_at": {
+                    "type": "string"
+                },
+                "max_uses": {
+                    "type": "integer",
+                    "maximum": 100000,
+                    "minimum": 1
+                },
+                "project_id": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "type": {
+                    "enum": [
+                        0,
+                        1
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/payment.CouponType"
+                        }
+                    ]
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "payment.Currency": {
+            "type": "string",
+            "enum": [
+                "LDC",
+                "CNY",
+                "CREDIT",
+                "LDC"
+            ],
+            "x-enum-comments": {
+                "CurrencyCNY": "人民币，精确到 0.01",
+                "CurrencyCredit": "整数积分，仅以整数单位结算",
+                "CurrencyLDC": "LDC 积分，精确到 0.01"
+            },
+            "x-enum-descriptions": [
+                "LDC 积分，精确到 0.01",
+                "人民币，精确到 0.01",
+                "整数积分，仅以整数单位结算"
+            ],
+            "x-enum-varnames": [
+                "CurrencyLDC",
+                "CurrencyCNY",
+                "CurrencyCredit",
+                "DefaultCurrency"
+            ]
+        },
+        "payment.CurrencySalesTotal": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "$ref": "#/definitions/payment.Currency"
+                },
+                "gross": {
+                    "type": "number"
+                },
+                "net": {
+                    "type": "number"
+                },
+                "paid_count": {
+                    "type": "integer"
+                },
+                "refunded": {
+                    "type": "number"
+                },
+                "refunded_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "payment.DiscrepancyKind": {
+            "type": "string",
+            "enum": [
+                "paid_but_failed",
+                "mismatch",
+                "query_failed",
+                "refund_failed"
+            ],
+            "x-enum-varnames": [
+                "DiscrepancyPaidButFailed",
+                "DiscrepancyMismatch",
+                "DiscrepancyQueryFailed",
+                "DiscrepancyRefundFailed"
+            ]
+        },
+        "payment.ListCouponsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/payment.ListCouponsResponseData"
+                },
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "payment.ListCouponsResponseData": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/payment.Coupon"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "payment.ListDiscrepanciesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/payment.ListDiscrepanciesResponseData"
+                },
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "payment.ListDiscrepanciesResponseData": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/payment.PaymentDiscrepancy"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "payment.ListNotifyLogsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/payment.ListNotifyLogsResponseData"
+                },
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "payment.ListNotifyLogsResponseData": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/payment.PaymentNotifyLog"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "payment.ListOrdersResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/payment.ListOrdersResponseData"
+                },
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "payment.ListOrdersResponseData": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/payment.OrderView"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "payment.OrderStatus": {
+            "type": "integer",
+            "format": "int32",
+            "enum": [
+                0,
+                1,
+                2,
+                3,
+                4,
+                5,
+                6
+            ],
+            "x-enum-varnames": [
+                "OrderStatusPending",
+                "OrderStatusPaid",
+                "OrderStatusCompleted",
+                "OrderStatusRefunding",
+                "OrderStatusRefunded",
+                "OrderStatusFailed",
+                "OrderStatusRefundFailed"
+            ]
+        },
+        "payment.OrderView": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "counterpart": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "$ref": "#/definitions/payment.Currency"
+                },
+                "fail_reason": {
+                    "type": "string"
+                },
+                "out_trade_no": {
+                    "type": "string"
+                },
+                "paid_at": {
+                    "type": "string"
+                },
+                "project_id": {
+                    "type": "string"
+                },
+                "project_name": {
+                    "type": "string"
+                },
+                "refund_reason": {
+                    "type": "string"
+                },
+                "refunded_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/payment.OrderStatus"
+                },
+                "trade_no": {
+                    "type": "string"
+                }
+            }
+        },
+        "payment.PaymentDiscrepancy": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "$ref": "#/definitions/payment.DiscrepancyKind"
+                },
+                "local_status": {
+                    "$ref": "#/definitions/payment.OrderStatus"
+                },
+                "out_trade_no": {
+                    "type": "string"
+                },
+                "payee_id": {
+                    "type": "integer"
+                },
+                "payer_id": {
+                    "type": "integer"
+                },
+                "project_id": {
+                    "type": "string"
+                },
+                "resolve_note": {
+                    "type": "string"
+                },
+                "resolved_at": {
+                    "type": "string"
+                },
+                "resolved_by": {
+                    "type": "integer"
+                },
+                "trade_no": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "payment.PaymentNotifyLog": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "out_trade_no": {
+                    "type": "string"
+                },
+                "provider": {
+                    "$ref": "#/definitions/payment.ProviderType"
+                },
+                "raw_query": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "replay_by": {
+                    "type": "integer"
+                },
+                "replay_of": {
+                    "type": "integer"
+                },
+                "source_ip": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "verified": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "payment.PaymentOrder": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "client_ip": {
+                    "type": "string"
+                },
+                "coupon_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "$ref": "#/definitions/payment.Currency"
+                },
+                "expire_at": {
+                    "type": "string"
+                },
+                "fail_reason": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "item_id": {
+                    "type": "integer"
+                },
+                "next_refund_at": {
+                    "type": "string"
+                },
+                "out_trade_no": {
+                    "type": "string"
+                },
+                "paid_at": {
+                    "type": "string"
+                },
+                "payee_client_id": {
+                    "type": "string"
+                },
+                "payee_id": {
+                    "type": "integer"
+                },
+                "payer_id": {
+                    "type": "integer"
+                },
+                "project_id": {
+                    "type": "string"
+                },
+                "provider": {
+                    "$ref": "#/definitions/payment.ProviderType"
+                },
+                "refund_attempts": {
+                    "type": "integer"
+                },
+                "refund_by": {
+                    "type": "integer"
+                },
+                "refund_reason": {
+                    "type": "string"
+                },
+                "refunded_at": {
+                    "type": "string"
+                },
+                "revoke_item": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "$ref": "#/definitions/payment.OrderStatus"
+                },
+                "trade_no": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "payment.PendingPaymentResponseData": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "has_pending": {
+                    "type": "boolean"
+                },
+                "pay_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "payment.ProjectSalesSummary": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "$ref": "#/definitions/payment.Currency"
+                },
+                "gross": {
+                    "type": "number"
+                },
+                "net": {
+                    "type": "number"
+                },
+                "paid_count": {
+                    "type": "integer"
+                },
+                "project_id": {
+                    "type": "string"
+                },
+                "project_name": {
+                    "type": "string"
                 },
-                "tags": {
+                "refunded": {
+                    "type": "number"
+                },
+                "refunded_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "payment.ProviderType": {
+            "type": "string",
+            "enum": [
+                "epay"
+            ],
+            "x-enum-varnames": [
+                "ProviderTypeEpay"
+            ]
+        },
+        "payment.RefundOrderRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "revoke_item": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "payment.ResolveDiscrepancyRequest": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "payment.Response": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "payment.SalesSummaryResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/payment.SalesSummaryResponseData"
+                },
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "payment.SalesSummaryResponseData": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/payment.ProjectSalesSummary"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "totals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/payment.CurrencySalesTotal"
+                    }
+                }
+            }
+        },
+        "project.AddReserveItemsRequestBody": {
+            "type": "object",
+            "required": [
+                "items"
+            ],
+            "properties": {
+                "enable_filter": {
+                    "type": "boolean"
+                },
+                "items": {
+                    "type": "array",
+                    "maxItems": 10000,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "project.ClaimProjectWaitlistResponseData": {
+            "type": "object",
+            "properties": {
+                "itemContent": {
+                    "type": "string"
+                }
+            }
+        },
+        "project.CreateFromSettingsRequestBody": {
+            "type": "object",
+            "required": [
+                "end_time",
+                "project_items",
+                "start_time"
+            ],
+            "properties": {
+                "announce_progress": {
+                    "type": "boolean"
+                },
+                "announce_to_topic": {
+                    "type": "boolean"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "invite_count": {
+                    "type": "integer",
+                    "maximum": 10000,
+                    "minimum": 0
+                },
+                "invite_expire_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "project_items": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "topic_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "project.CreateProjectInvitesRequestBody": {
+            "type": "object",
+            "required": [
+                "count"
+            ],
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "maximum": 10000,
+                    "minimum": 1
+                },
+                "expire_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "project.CreateProjectRequestBody": {
+            "type": "object",
+            "required": [
+                "end_time",
+                "name",
+                "project_items",
+                "start_time"
+            ],
+            "properties": {
+                "allow_same_ip": {
+                    "type": "boolean"
+                },
+                "announce_progress": {
+                    "type": "boolean"
+                },
+                "announce_to_topic": {
+                    "type": "boolean"
+                },
+                "currency": {
+                    "enum": [
+                        "LDC",
+                        "CNY",
+                        "CREDIT"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/project.Currency"
+                        }
+                    ]
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 1024
+                },
+                "distribution_type": {
+                    "enum": [
+                        0,
+                        1,
+                        2,
+                        3
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/project.DistributionType"
+                        }
+                    ]
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "hide_from_explore": {
+                    "type": "boolean"
+                },
+                "invite_count": {
+                    "type": "integer",
+                    "maximum": 10000,
+                    "minimum": 0
+                },
+                "invite_expire_at": {
+                    "type": "string"
+                },
+                "max_per_user": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0
+                },
+                "max_per_user_overrides": {
+                    "$ref": "#/definitions/project.TrustLevelQuota"
+                },
+                "minimum_trust_level": {
+                    "enum": [
+                        0,
+                        1,
+                        2,
+                        3,
+                        4
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/oauth.TrustLevel"
+                        }
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 1
+                },
+                "price": {
+                    "type": "number"
+                },
+                "pricing_rules": {
+                    "$ref": "#/definitions/project.PricingRules"
+                },
+                "project_items": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "project_tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "risk_level": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "topic_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "project.Currency": {
+            "type": "string",
+            "enum": [
+                "LDC",
+                "CNY",
+                "CREDIT",
+                "LDC"
+            ],
+            "x-enum-comments": {
+                "CurrencyCNY": "人民币，精确到 0.01",
+                "CurrencyCredit": "整数积分，仅以整数单位结算",
+                "CurrencyLDC": "LDC 积分，精确到 0.01"
+            },
+            "x-enum-descriptions": [
+                "LDC 积分，精确到 0.01",
+                "人民币，精确到 0.01",
+                "整数积分，仅以整数单位结算"
+            ],
+            "x-enum-varnames": [
+                "CurrencyLDC",
+                "CurrencyCNY",
+                "CurrencyCredit",
+                "DefaultCurrency"
+            ]
+        },
+        "project.DeleteProjectItemsRequestBody": {
+            "type": "object",
+            "properties": {
+                "contents": {
                     "type": "array",
+                    "maxItems": 1000,
                     "items": {
                         "type": "string"
                     }
                 },
-                "username": {
+                "item_ids": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "project.DeleteProjectItemsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/project.DeleteProjectItemsResponseData"
+                },
+                "error_msg": {
                     "type": "string"
                 }
             }
         },
-        "admin.ReviewProjectRequest": {
+        "project.DeleteProjectItemsResponseData": {
             "type": "object",
             "properties": {
-                "status": {
-                    "enum": [
-                        0,
-                        1,
-                        2
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/project.ProjectStatus"
-                        }
-                    ]
+                "deleted_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "skipped_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 }
             }
         },
-        "admin.ReviewProjectResponse": {
+        "project.DistributionType": {
+            "type": "integer",
+            "format": "int32",
+            "enum": [
+                0,
+                1,
+                2,
+                3
+            ],
+            "x-enum-varnames": [
+                "DistributionTypeOneForEach",
+                "DistributionTypeLottery",
+                "DistributionTypeInvite",
+                "DistributionTypeDraw"
+            ]
+        },
+        "project.GetProjectDrawResponse": {
             "type": "object",
             "properties": {
-                "data": {},
+                "data": {
+                    "$ref": "#/definitions/project.GetProjectDrawResponseData"
+                },
                 "error_msg": {
                     "type": "string"
                 }
             }
         },
-        "admin.listUsersResponse": {
+        "project.GetProjectDrawResponseData": {
             "type": "object",
             "properties": {
-                "data": {
-                    "type": "object",
-                    "properties": {
-                        "total": {
-                            "type": "integer"
-                        },
-                        "users": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/oauth.User"
-                            }
-                        }
+                "draw_seed": {
+                    "type": "string"
+                },
+                "draw_seed_hash": {
+                    "type": "string"
+                },
+                "drawn_at": {
+                    "type": "string"
+                },
+                "entrant_count": {
+                    "type": "integer"
+                },
+                "entrants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/project.ProjectDrawEntrant"
                     }
+                }
+            }
+        },
+        "project.GetProjectPriceResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/project.GetProjectPriceResponseData"
                 },
                 "error_msg": {
                     "type": "string"
                 }
             }
         },
-        "dashboard.DashboardDataResponse": {
+        "project.GetProjectPriceResponseData": {
             "type": "object",
             "properties": {
-                "data": {},
-                "error_msg": {
+                "base_price": {
+                    "type": "number"
+                },
+                "currency": {
+                    "$ref": "#/definitions/project.Currency"
+                },
+                "price": {
+                    "type": "number"
+                }
+            }
+        },
+        "project.GetProjectResponseData": {
+            "type": "object",
+            "properties": {
+                "allow_same_ip": {
+                    "type": "boolean"
+                },
+                "announce_progress": {
+                    "type": "boolean"
+                },
+                "announce_to_topic": {
+                    "type": "boolean"
+                },
+                "available_items_count": {
+                    "type": "integer"
+                },
+                "claim_quota": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "creator_id": {
+                    "type": "integer"
+                },
+                "creator_nickname": {
+                    "type": "string"
+                },
+                "creator_username": {
+                    "type": "string"
+                },
+                "currency": {
+                    "$ref": "#/definitions/project.Currency"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "distribution_type": {
+                    "$ref": "#/definitions/project.DistributionType"
+                },
+                "draw_seed": {
+                    "type": "string"
+                },
+                "draw_seed_hash": {
+                    "type": "string"
+                },
+                "drawn_at": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "hide_from_explore": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_completed": {
+                    "type": "boolean"
+                },
+                "is_entered": {
+                    "type": "boolean"
+                },
+                "is_received": {
+                    "type": "boolean"
+                },
+                "max_per_user": {
+                    "type": "integer"
+                },
+                "max_per_user_overrides": {
+                    "$ref": "#/definitions/project.TrustLevelQuota"
+                },
+                "minimum_trust_level": {
+                    "$ref": "#/definitions/oauth.TrustLevel"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_round_at": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "pricing_rules": {
+                    "$ref": "#/definitions/project.PricingRules"
+                },
+                "received_content": {
+                    "type": "string"
+                },
+                "received_contents": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "received_count": {
+                    "type": "integer"
+                },
+                "received_replaced_at": {
+                    "type": "string"
+                },
+                "recurrence_cron": {
+                    "type": "string"
+                },
+                "report_count": {
+                    "type": "integer"
+                },
+                "risk_level": {
+                    "type": "integer"
+                },
+                "round": {
+                    "type": "integer"
+                },
+                "round_duration": {
+                    "type": "integer"
+                },
+                "round_size": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/project.ProjectStatus"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "topic_id": {
+                    "type": "integer"
+                },
+                "total_items": {
+                    "type": "integer"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "health.HealthResponse": {
+        "project.GetProjectWaitlistResponse": {
             "type": "object",
             "properties": {
-                "data": {},
+                "data": {
+                    "$ref": "#/definitions/project.GetProjectWaitlistResponseData"
+                },
                 "error_msg": {
                     "type": "string"
                 }
             }
         },
-        "health.ReadyResponse": {
+        "project.GetProjectWaitlistResponseData": {
             "type": "object",
             "properties": {
-                "data": {},
+                "entry": {
+                    "$ref": "#/definitions/project.ProjectWaitlist"
+                },
+                "joined": {
+                    "type": "boolean"
+                },
+                "position": {
+                    "type": "integer"
+                }
+            }
+        },
+        "project.ImportProjectItemsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/project.ItemImportReport"
+                },
                 "error_msg": {
                     "type": "string"
                 }
             }
         },
-        "oauth.BasicUserInfo": {
+        "project.ItemImportReject": {
             "type": "object",
             "properties": {
-                "avatar_url": {
-                    "type": "string"
-                },
-                "id": {
+                "line": {
                     "type": "integer"
                 },
-                "nickname": {
+                "reason": {
                     "type": "string"
+                }
+            }
+        },
+        "project.ItemImportReport": {
+            "type": "object",
+            "properties": {
+                "imported_count": {
+                    "type": "integer"
                 },
-                "score": {
+                "rejected_count": {
                     "type": "integer"
                 },
-                "trust_level": {
-                    "$ref": "#/definitions/oauth.TrustLevel"
+                "rejects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/project.ItemImportReject"
+                    }
                 },
-                "username": {
+                "total_lines": {
+                    "type": "integer"
+                }
+            }
+        },
+        "project.ListProjectInvitesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/project.ListProjectInvitesResponseData"
+                },
+                "error_msg": {
                     "type": "string"
                 }
             }
         },
-        "oauth.CallbackRequest": {
+        "project.ListProjectInvitesResponseData": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "string"
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/project.ProjectInvite"
+                    }
                 },
-                "state": {
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "oauth.CallbackResponse": {
+        "project.ListProjectRoundsResponse": {
             "type": "object",
             "properties": {
-                "data": {},
+                "data": {
+                    "$ref": "#/definitions/project.ListProjectRoundsResponseData"
+                },
                 "error_msg": {
                     "type": "string"
                 }
             }
         },
-        "oauth.GetLoginURLResponse": {
+        "project.ListProjectRoundsResponseData": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/project.ProjectRound"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "project.ListProjectTemplatesResponse": {
             "type": "object",
             "properties": {
                 "data": {
-                    "type": "string"
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/project.ProjectTemplate"
+                    }
                 },
                 "error_msg": {
                     "type": "string"
                 }
             }
         },
-        "oauth.LogoutResponse": {
+        "project.ListProjectsResponse": {
             "type": "object",
             "properties": {
-                "data": {},
+                "data": {
+                    "$ref": "#/definitions/project.ListProjectsResponseData"
+                },
                 "error_msg": {
                     "type": "string"
                 }
             }
         },
-        "oauth.TrustLevel": {
-            "type": "integer",
-            "format": "int32",
-            "enum": [
-                0,
-                1,
-                2,
-                3,
-                4
-            ],
-            "x-enum-varnames": [
-                "TrustLevelNewUser",
-                "TrustLevelBasicUser",
-                "TrustLevelUser",
-                "TrustLevelActiveUser",
-                "TrustLevelLeader"
-            ]
+        "project.ListProjectsResponseData": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/project.ListProjectsResponseDataResult"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
         },
-        "oauth.User": {
+        "project.ListProjectsResponseDataResult": {
             "type": "object",
             "properties": {
-                "avatar_url": {
-                    "type": "string"
+                "allow_same_ip": {
+                    "type": "boolean"
                 },
                 "created_at": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
+                "currency": {
+                    "$ref": "#/definitions/project.Currency"
                 },
-                "is_active": {
-                    "type": "boolean"
+                "description": {
+                    "type": "string"
                 },
-                "is_admin": {
-                    "type": "boolean"
+                "distribution_type": {
+                    "$ref": "#/definitions/project.DistributionType"
                 },
-                "last_login_at": {
+                "end_time": {
                     "type": "string"
                 },
-                "nickname": {
-                    "type": "string"
+                "hide_from_explore": {
+                    "type": "boolean"
                 },
-                "score": {
-                    "type": "integer"
+                "id": {
+                    "type": "string"
                 },
-                "trust_level": {
+                "minimum_trust_level": {
                     "$ref": "#/definitions/oauth.TrustLevel"
                 },
-                "updated_at": {
+                "name": {
                     "type": "string"
                 },
-                "username": {
+                "price": {
+                    "type": "number"
+                },
+                "risk_level": {
+                    "type": "integer"
+                },
+                "start_time": {
                     "type": "string"
                 },
-                "violation_count": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "total_items": {
                     "type": "integer"
                 }
             }
         },
-        "oauth.UserInfoResponse": {
+        "project.ListReceiveHistoryChartResponse": {
             "type": "object",
             "properties": {
                 "data": {
-                    "$ref": "#/definitions/oauth.BasicUserInfo"
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/project.ReceiveHistoryChartPoint"
+                    }
                 },
                 "error_msg": {
                     "type": "string"
                 }
             }
         },
-        "payment.PendingPaymentResponseData": {
+        "project.ListReceiveHistoryResponse": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "type": "string"
-                },
-                "has_pending": {
-                    "type": "boolean"
+                "data": {
+                    "$ref": "#/definitions/project.ListReceiveHistoryResponseData"
                 },
-                "pay_url": {
+                "error_msg": {
                     "type": "string"
                 }
             }
         },
-        "payment.Response": {
+        "project.ListReceiveHistoryResponseData": {
             "type": "object",
             "properties": {
-                "data": {},
-                "error_msg": {
-                    "type": "string"
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/project.ListReceiveHistoryResponseDataResult"
+                    }
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "project.CreateProjectRequestBody": {
+        "project.ListReceiveHistoryResponseDataResult": {
             "type": "object",
-            "required": [
-                "end_time",
-                "name",
-                "project_items",
-                "start_time"
-            ],
             "properties": {
-                "allow_same_ip": {
-                    "type": "boolean"
-                },
-                "description": {
-                    "type": "string",
-                    "maxLength": 1024
-                },
-                "distribution_type": {
-                    "enum": [
-                        0,
-                        1
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/project.DistributionType"
-                        }
-                    ]
+                "content": {
+                    "type": "string"
                 },
-                "end_time": {
+                "project_creator": {
                     "type": "string"
                 },
-                "hide_from_explore": {
-                    "type": "boolean"
+                "project_creator_nickname": {
+                    "type": "string"
                 },
-                "minimum_trust_level": {
-                    "enum": [
-                        0,
-                        1,
-                        2,
-                        3,
-                        4
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/oauth.TrustLevel"
-                        }
-                    ]
+                "project_id": {
+                    "type": "string"
                 },
-                "name": {
-                    "type": "string",
-                    "maxLength": 32,
-                    "minLength": 1
+                "project_name": {
+                    "type": "string"
                 },
-                "price": {
-                    "type": "number"
+                "received_at": {
+                    "type": "string"
                 },
-                "project_items": {
-                    "type": "array",
-                    "minItems": 1,
-                    "items": {
-                        "type": "string"
-                    }
+                "replace_reason": {
+                    "type": "string"
                 },
-                "project_tags": {
+                "replaced_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "project.ListTagsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "risk_level": {
-                    "type": "integer",
-                    "maximum": 100,
-                    "minimum": 0
-                },
-                "start_time": {
+                "error_msg": {
                     "type": "string"
+                }
+            }
+        },
+        "project.PriceStep": {
+            "type": "object",
+            "properties": {
+                "price": {
+                    "type": "number"
                 },
-                "topic_id": {
+                "remaining": {
                     "type": "integer"
                 }
             }
         },
-        "project.DistributionType": {
-            "type": "integer",
-            "format": "int32",
-            "enum": [
-                0,
-                1,
-                2
-            ],
-            "x-enum-varnames": [
-                "DistributionTypeOneForEach",
-                "DistributionTypeLottery",
-                "DistributionTypeInvite"
-            ]
+        "project.PricingRules": {
+            "type": "object",
+            "properties": {
+                "early_bird_price": {
+                    "type": "number"
+                },
+                "early_bird_until": {
+                    "type": "string"
+                },
+                "stock_steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/project.PriceStep"
+                    }
+                },
+                "trust_level_discounts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
         },
-        "project.GetProjectResponseData": {
+        "project.Project": {
             "type": "object",
             "properties": {
                 "allow_same_ip": {
                     "type": "boolean"
                 },
-                "available_items_count": {
-                    "type": "integer"
+                "announce_progress": {
+                    "type": "boolean"
+                },
+                "announce_to_topic": {
+                    "type": "boolean"
                 },
                 "created_at": {
                     "type": "string"
@@ -1169,11 +4748,8 @@ const docTemplate = `{
                 "creator_id": {
                     "type": "integer"
                 },
-                "creator_nickname": {
-                    "type": "string"
-                },
-                "creator_username": {
-                    "type": "string"
+                "currency": {
+                    "$ref": "#/definitions/project.Currency"
                 },
                 "description": {
                     "type": "string"
@@ -1181,6 +4757,12 @@ const docTemplate = `{
                 "distribution_type": {
                     "$ref": "#/definitions/project.DistributionType"
                 },
+                "draw_seed_hash": {
+                    "type": "string"
+                },
+                "drawn_at": {
+                    "type": "string"
+                },
                 "end_time": {
                     "type": "string"
                 },
@@ -1193,8 +4775,11 @@ const docTemplate = `{
                 "is_completed": {
                     "type": "boolean"
                 },
-                "is_received": {
-                    "type": "boolean"
+                "max_per_user": {
+                    "type": "integer"
+                },
+                "max_per_user_overrides": {
+                    "$ref": "#/definitions/project.TrustLevelQuota"
                 },
                 "minimum_trust_level": {
                     "$ref": "#/definitions/oauth.TrustLevel"
@@ -1202,10 +4787,16 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
+                "next_round_at": {
+                    "type": "string"
+                },
                 "price": {
                     "type": "number"
                 },
-                "received_content": {
+                "pricing_rules": {
+                    "$ref": "#/definitions/project.PricingRules"
+                },
+                "recurrence_cron": {
                     "type": "string"
                 },
                 "report_count": {
@@ -1214,17 +4805,23 @@ const docTemplate = `{
                 "risk_level": {
                     "type": "integer"
                 },
+                "round": {
+                    "type": "integer"
+                },
+                "round_duration": {
+                    "type": "integer"
+                },
+                "round_size": {
+                    "type": "integer"
+                },
                 "start_time": {
                     "type": "string"
                 },
                 "status": {
                     "$ref": "#/definitions/project.ProjectStatus"
                 },
-                "tags": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "topic_id": {
+                    "type": "integer"
                 },
                 "total_items": {
                     "type": "integer"
@@ -1234,191 +4831,336 @@ const docTemplate = `{
                 }
             }
         },
-        "project.ListProjectsResponse": {
+        "project.ProjectDrawEntrant": {
             "type": "object",
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/project.ListProjectsResponseData"
+                "created_at": {
+                    "type": "string"
                 },
-                "error_msg": {
+                "is_winner": {
+                    "type": "boolean"
+                },
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "project.ListProjectsResponseData": {
+        "project.ProjectInvite": {
             "type": "object",
             "properties": {
-                "results": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/project.ListProjectsResponseDataResult"
-                    }
+                "created_at": {
+                    "type": "string"
                 },
-                "total": {
+                "expire_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "project_id": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "used_at": {
+                    "type": "string"
+                },
+                "used_by": {
                     "type": "integer"
                 }
             }
         },
-        "project.ListProjectsResponseDataResult": {
+        "project.ProjectResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "project.ProjectRound": {
             "type": "object",
             "properties": {
-                "allow_same_ip": {
-                    "type": "boolean"
-                },
                 "created_at": {
                     "type": "string"
                 },
-                "description": {
+                "end_time": {
                     "type": "string"
                 },
-                "distribution_type": {
-                    "$ref": "#/definitions/project.DistributionType"
+                "id": {
+                    "type": "integer"
                 },
-                "end_time": {
+                "project_id": {
+                    "type": "string"
+                },
+                "received_count": {
+                    "type": "integer"
+                },
+                "remaining_stock": {
+                    "type": "integer"
+                },
+                "round": {
+                    "type": "integer"
+                },
+                "start_time": {
                     "type": "string"
+                }
+            }
+        },
+        "project.ProjectSettings": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "allow_same_ip": {
+                    "type": "boolean"
+                },
+                "currency": {
+                    "enum": [
+                        "LDC",
+                        "CNY",
+                        "CREDIT"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/project.Currency"
+                        }
+                    ]
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 1024
                 },
                 "hide_from_explore": {
                     "type": "boolean"
                 },
-                "id": {
-                    "type": "string"
+                "max_per_user": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0
+                },
+                "max_per_user_overrides": {
+                    "$ref": "#/definitions/project.TrustLevelQuota"
                 },
                 "minimum_trust_level": {
-                    "$ref": "#/definitions/oauth.TrustLevel"
+                    "enum": [
+                        0,
+                        1,
+                        2,
+                        3,
+                        4
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/oauth.TrustLevel"
+                        }
+                    ]
                 },
                 "name": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 1
                 },
                 "price": {
                     "type": "number"
                 },
-                "risk_level": {
-                    "type": "integer"
-                },
-                "start_time": {
-                    "type": "string"
+                "pricing_rules": {
+                    "$ref": "#/definitions/project.PricingRules"
                 },
-                "tags": {
+                "project_tags": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "total_items": {
-                    "type": "integer"
+                "risk_level": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0
                 }
             }
         },
-        "project.ListReceiveHistoryChartResponse": {
+        "project.ProjectStatus": {
+            "type": "integer",
+            "format": "int32",
+            "enum": [
+                0,
+                1,
+                2
+            ],
+            "x-enum-varnames": [
+                "ProjectStatusNormal",
+                "ProjectStatusHidden",
+                "ProjectStatusViolation"
+            ]
+        },
+        "project.ProjectTemplate": {
             "type": "object",
             "properties": {
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/project.ReceiveHistoryChartPoint"
-                    }
+                "created_at": {
+                    "type": "string"
                 },
-                "error_msg": {
+                "distribution_type": {
+                    "$ref": "#/definitions/project.DistributionType"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "settings": {
+                    "$ref": "#/definitions/project.ProjectSettings"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "project.ListReceiveHistoryResponse": {
+        "project.ProjectTemplateRequestBody": {
             "type": "object",
+            "required": [
+                "title"
+            ],
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/project.ListReceiveHistoryResponseData"
+                "distribution_type": {
+                    "enum": [
+                        0,
+                        1,
+                        2,
+                        3
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/project.DistributionType"
+                        }
+                    ]
                 },
-                "error_msg": {
-                    "type": "string"
+                "settings": {
+                    "$ref": "#/definitions/project.ProjectSettings"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 1
                 }
             }
         },
-        "project.ListReceiveHistoryResponseData": {
+        "project.ProjectWaitlist": {
             "type": "object",
             "properties": {
-                "results": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/project.ListReceiveHistoryResponseDataResult"
-                    }
+                "created_at": {
+                    "type": "string"
                 },
-                "total": {
+                "hold_expire_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "item_id": {
+                    "type": "integer"
+                },
+                "out_trade_no": {
+                    "type": "string"
+                },
+                "project_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/project.WaitlistStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "integer"
                 }
             }
         },
-        "project.ListReceiveHistoryResponseDataResult": {
+        "project.ReceiveHistoryChartPoint": {
             "type": "object",
             "properties": {
-                "content": {
-                    "type": "string"
-                },
-                "project_creator": {
-                    "type": "string"
+                "count": {
+                    "type": "integer"
                 },
-                "project_creator_nickname": {
+                "date": {
                     "type": "string"
                 },
-                "project_id": {
+                "label": {
                     "type": "string"
+                }
+            }
+        },
+        "project.RecurrenceRequestBody": {
+            "type": "object",
+            "required": [
+                "cron",
+                "round_duration",
+                "round_size"
+            ],
+            "properties": {
+                "cron": {
+                    "type": "string",
+                    "maxLength": 64
                 },
-                "project_name": {
-                    "type": "string"
+                "round_duration": {
+                    "type": "integer",
+                    "maximum": 10080,
+                    "minimum": 1
                 },
-                "received_at": {
-                    "type": "string"
+                "round_size": {
+                    "type": "integer",
+                    "maximum": 10000,
+                    "minimum": 1
                 }
             }
         },
-        "project.ListTagsResponse": {
+        "project.RecurrenceResponse": {
             "type": "object",
             "properties": {
                 "data": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                    "$ref": "#/definitions/project.RecurrenceResponseData"
                 },
                 "error_msg": {
                     "type": "string"
                 }
             }
         },
-        "project.ProjectResponse": {
+        "project.RecurrenceResponseData": {
             "type": "object",
             "properties": {
-                "data": {},
-                "error_msg": {
-                    "type": "string"
+                "project": {
+                    "$ref": "#/definitions/project.Project"
+                },
+                "reserve_count": {
+                    "type": "integer"
                 }
             }
         },
-        "project.ProjectStatus": {
-            "type": "integer",
-            "format": "int32",
-            "enum": [
-                0,
-                1,
-                2
-            ],
-            "x-enum-varnames": [
-                "ProjectStatusNormal",
-                "ProjectStatusHidden",
-                "ProjectStatusViolation"
-            ]
-        },
-        "project.ReceiveHistoryChartPoint": {
+        "project.ReplaceProjectItemRequestBody": {
             "type": "object",
+            "required": [
+                "content"
+            ],
             "properties": {
-                "count": {
-                    "type": "integer"
-                },
-                "date": {
-                    "type": "string"
+                "content": {
+                    "type": "string",
+                    "maxLength": 1024,
+                    "minLength": 1
                 },
-                "label": {
-                    "type": "string"
+                "reason": {
+                    "type": "string",
+                    "maxLength": 255
                 }
             }
         },
@@ -1435,6 +5177,12 @@ const docTemplate = `{
                 }
             }
         },
+        "project.TrustLevelQuota": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "integer"
+            }
+        },
         "project.UpdateProjectRequestBody": {
             "type": "object",
             "required": [
@@ -1446,6 +5194,18 @@ const docTemplate = `{
                 "allow_same_ip": {
                     "type": "boolean"
                 },
+                "currency": {
+                    "enum": [
+                        "LDC",
+                        "CNY",
+                        "CREDIT"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/project.Currency"
+                        }
+                    ]
+                },
                 "description": {
                     "type": "string",
                     "maxLength": 1024
@@ -1459,6 +5219,14 @@ const docTemplate = `{
                 "hide_from_explore": {
                     "type": "boolean"
                 },
+                "max_per_user": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0
+                },
+                "max_per_user_overrides": {
+                    "$ref": "#/definitions/project.TrustLevelQuota"
+                },
                 "minimum_trust_level": {
                     "enum": [
                         0,
@@ -1481,6 +5249,9 @@ const docTemplate = `{
                 "price": {
                     "type": "number"
                 },
+                "pricing_rules": {
+                    "$ref": "#/definitions/project.PricingRules"
+                },
                 "project_items": {
                     "type": "array",
                     "items": {
@@ -1502,6 +5273,261 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "project.WaitlistStatus": {
+            "type": "integer",
+            "format": "int32",
+            "enum": [
+                0,
+                1,
+                2,
+                3,
+                4
+            ],
+            "x-enum-varnames": [
+                "WaitlistStatusWaiting",
+                "WaitlistStatusOffered",
+                "WaitlistStatusFulfilled",
+                "WaitlistStatusExpired",
+                "WaitlistStatusCancelled"
+            ]
+        },
+        "webhook.CreateWebhookRequestBody": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/webhook.Event"
+                    }
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 512
+                }
+            }
+        },
+        "webhook.CreateWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/webhook.CreateWebhookResponseData"
+                },
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhook.CreateWebhookResponseData": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "webhook.DeliveryStatus": {
+            "type": "integer",
+            "format": "int32",
+            "enum": [
+                0,
+                1,
+                2,
+                3
+            ],
+            "x-enum-varnames": [
+                "DeliveryStatusPending",
+                "DeliveryStatusSucceeded",
+                "DeliveryStatusRetrying",
+                "DeliveryStatusDead"
+            ]
+        },
+        "webhook.Event": {
+            "type": "string",
+            "enum": [
+                "item.received",
+                "project.completed",
+                "order.paid",
+                "order.refunded",
+                "project.reported"
+            ],
+            "x-enum-varnames": [
+                "EventItemReceived",
+                "EventProjectCompleted",
+                "EventOrderPaid",
+                "EventOrderRefunded",
+                "EventProjectReported"
+            ]
+        },
+        "webhook.ListWebhookDeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/webhook.ListWebhookDeliveriesResponseData"
+                },
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhook.ListWebhookDeliveriesResponseData": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhook.WebhookDelivery"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "webhook.ListWebhooksResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhook.Webhook"
+                    }
+                },
+                "error_msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhook.UpdateWebhookRequestBody": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/webhook.Event"
+                    }
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 512
+                }
+            }
+        },
+        "webhook.Webhook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "webhook.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "$ref": "#/definitions/webhook.Event"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "response_status": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/webhook.DeliveryStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "webhook.WebhookResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "error_msg": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -25,10 +25,15 @@
 package admin
 
 import (
+	"context"
 	"net/http"
+	"time"
 
+	"github.com/linux-do/cdk/internal/apps/forum"
+	"github.com/linux-do/cdk/internal/apps/notification"
 	"github.com/linux-do/cdk/internal/apps/oauth"
 	"github.com/linux-do/cdk/internal/apps/project"
+	"github.com/linux-do/cdk/internal/apps/webhook"
 	"github.com/linux-do/cdk/internal/db"
 	"gorm.io/gorm"
 
@@ -104,6 +109,7 @@ func ReviewProject(c *gin.Context) {
 		c.JSON(http.StatusNotFound, ReviewProjectResponse{ErrorMsg: err.Error()})
 		return
 	}
+	version := p.UpdatedAt
 
 	updates := map[string]interface{}{
 		"status": req.Status,
@@ -113,12 +119,28 @@ func ReviewProject(c *gin.Context) {
 	switch req.Status {
 	case project.ProjectStatusNormal:
 		updates["report_count"] = 0
-		if err := db.DB(c.Request.Context()).Model(p).Updates(updates).Error; err != nil {
+		if err := db.DB(c.Request.Context()).Transaction(
+			func(tx *gorm.DB) error {
+				if err := tx.Model(p).Updates(updates).Error; err != nil {
+					return err
+				}
+				emitProjectReviewed(c.Request.Context(), tx, p, req.Status)
+				return notifyProjectReviewed(tx, p, req.Status)
+			},
+		); err != nil {
 			c.JSON(http.StatusInternalServerError, ReviewProjectResponse{ErrorMsg: err.Error()})
 			return
 		}
 	case project.ProjectStatusHidden:
-		if err := db.DB(c.Request.Context()).Model(p).Updates(updates).Error; err != nil {
+		if err := db.DB(c.Request.Context()).Transaction(
+			func(tx *gorm.DB) error {
+				if err := tx.Model(p).Updates(updates).Error; err != nil {
+					return err
+				}
+				emitProjectReviewed(c.Request.Context(), tx, p, req.Status)
+				return notifyProjectReviewed(tx, p, req.Status)
+			},
+		); err != nil {
 			c.JSON(http.StatusInternalServerError, ReviewProjectResponse{ErrorMsg: err.Error()})
 			return
 		}
@@ -132,7 +154,8 @@ func ReviewProject(c *gin.Context) {
 					UpdateColumn("violation_count", gorm.Expr("violation_count + 1")).Error; err != nil {
 					return err
 				}
-				return nil
+				emitProjectReviewed(c.Request.Context(), tx, p, req.Status)
+				return notifyProjectReviewed(tx, p, req.Status)
 			},
 		); err != nil {
 			c.JSON(http.StatusInternalServerError, ReviewProjectResponse{ErrorMsg: err.Error()})
@@ -140,9 +163,63 @@ func ReviewProject(c *gin.Context) {
 		}
 	}
 
+	// 通知创建者项目被隐藏或判定违规
+	if kind, ok := reviewPrivateMessageKinds[req.Status]; ok {
+		forum.EnqueuePrivateMessage(c.Request.Context(), kind, forum.ReviewKey(kind, p.ID, version), p.Creator.Username, p.ID, p.Name)
+	}
+
 	c.JSON(http.StatusOK, ReviewProjectResponse{})
 }
 
+// reviewPrivateMessageKinds 需要私信通知创建者的审核结果
+var reviewPrivateMessageKinds = map[project.ProjectStatus]forum.Kind{
+	project.ProjectStatusHidden:    forum.KindHidden,
+	project.ProjectStatusViolation: forum.KindViolation,
+}
+
+// emitProjectReviewed 向项目创建者推送审核结果，驳回举报(恢复正常)时 Status 为 0
+func emitProjectReviewed(ctx context.Context, tx *gorm.DB, p *project.Project, status project.ProjectStatus) {
+	webhook.Emit(ctx, tx, p.CreatorID, webhook.EventProjectReported, webhook.ProjectReportedData{
+		ProjectID:   p.ID,
+		ProjectName: p.Name,
+		Source:      webhook.ReportSourceReview,
+		Status:      uint8(status),
+		ReportCount: p.ReportCount,
+	})
+}
+
+// notifyProjectReviewed 通知本轮尚未审核的举报人审核结果并将其举报标记为已审核，项目被隐藏或判定违规时同时通知创建者
+func notifyProjectReviewed(tx *gorm.DB, p *project.Project, status project.ProjectStatus) error {
+	verdict := notification.VerdictDismissed
+	switch status {
+	case project.ProjectStatusHidden:
+		verdict = notification.VerdictHidden
+	case project.ProjectStatusViolation:
+		verdict = notification.VerdictViolation
+	}
+
+	var reporterIDs []uint64
+	if err := tx.Model(&project.ProjectReport{}).
+		Where("project_id = ? AND reviewed_at IS NULL", p.ID).
+		Pluck("reporter_id", &reporterIDs).Error; err != nil {
+		return err
+	}
+	if len(reporterIDs) > 0 {
+		if err := tx.Model(&project.ProjectReport{}).
+			Where("project_id = ? AND reporter_id IN ? AND reviewed_at IS NULL", p.ID, reporterIDs).
+			Update("reviewed_at", time.Now()).Error; err != nil {
+			return err
+		}
+	}
+	if err := notification.Send(tx, notification.ReportReviewed(p.ID, p.Name, verdict), reporterIDs...); err != nil {
+		return err
+	}
+	if verdict == notification.VerdictDismissed {
+		return nil
+	}
+	return notification.Send(tx, notification.ProjectReviewed(p.ID, p.Name, verdict), p.CreatorID)
+}
+
 type listUsersRequest struct {
 	Current       int               `json:"current" form:"current" binding:"min=1"`
 	Size          int               `json:"size" form:"size" binding:"min=1,max=100"`
@@ -0,0 +1,140 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/cdk/internal/apps/notification"
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/apps/project"
+	"github.com/linux-do/cdk/internal/apps/webhook"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/db/dbtest"
+)
+
+// reviewedReporters 收到举报处理通知的用户
+func reviewedReporters(t *testing.T) []uint64 {
+	t.Helper()
+	var ids []uint64
+	if err := db.DB(context.Background()).Model(&notification.Notification{}).
+		Where("type = ?", notification.TypeReportReviewed).
+		Order("id ASC").
+		Pluck("user_id", &ids).Error; err != nil {
+		t.Fatalf("load notifications: %v", err)
+	}
+	return ids
+}
+
+func TestNotifyProjectReviewedOnlyNewReports(t *testing.T) {
+	dbtest.Setup(t, &project.ProjectReport{}, &notification.Notification{})
+	ctx := context.Background()
+	p := &project.Project{ID: "p1", Name: "项目", CreatorID: 1}
+
+	report := func(reporterID uint64) {
+		if err := db.DB(ctx).Create(&project.ProjectReport{ProjectID: p.ID, ReporterID: reporterID}).Error; err != nil {
+			t.Fatalf("create report: %v", err)
+		}
+	}
+
+	report(10)
+	report(11)
+	if err := notifyProjectReviewed(db.DB(ctx), p, project.ProjectStatusNormal); err != nil {
+		t.Fatalf("first review: %v", err)
+	}
+	if got := reviewedReporters(t); len(got) != 2 {
+		t.Fatalf("want both reporters notified, got %v", got)
+	}
+
+	// 再次审核只通知上次审核后的新举报人
+	report(12)
+	if err := notifyProjectReviewed(db.DB(ctx), p, project.ProjectStatusHidden); err != nil {
+		t.Fatalf("second review: %v", err)
+	}
+	if got := reviewedReporters(t); len(got) != 3 || got[2] != 12 {
+		t.Fatalf("want only the new reporter notified again, got %v", got)
+	}
+
+	// 没有新举报时不通知举报人
+	if err := notifyProjectReviewed(db.DB(ctx), p, project.ProjectStatusNormal); err != nil {
+		t.Fatalf("third review: %v", err)
+	}
+	if got := reviewedReporters(t); len(got) != 3 {
+		t.Fatalf("want no reporter notified, got %v", got)
+	}
+}
+
+func TestReviewProjectDismissEmitsWebhook(t *testing.T) {
+	dbtest.Setup(t, &oauth.User{}, &project.Project{}, &project.ProjectReport{}, &notification.Notification{},
+		&webhook.Webhook{}, &webhook.WebhookDelivery{})
+	ctx := context.Background()
+	creator := &oauth.User{ID: 1, Username: "creator"}
+	if err := db.DB(ctx).Create(creator).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	p := &project.Project{ID: "p1", Name: "项目", CreatorID: creator.ID, Status: project.ProjectStatusHidden, ReportCount: 3}
+	if err := db.DB(ctx).Create(p).Error; err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	hook := &webhook.Webhook{UserID: creator.ID, URL: "https://example.com/hook", Secret: "secret",
+		Events: []string{string(webhook.EventProjectReported)}, IsActive: true}
+	if err := db.DB(ctx).Create(hook).Error; err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+
+	body, _ := json.Marshal(ReviewProjectRequest{Status: project.ProjectStatusNormal})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: p.ID}}
+	ReviewProject(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("review: %d %s", w.Code, w.Body.String())
+	}
+
+	// 驳回举报同样推送审核结果
+	var deliveries []webhook.WebhookDelivery
+	db.DB(ctx).Where("webhook_id = ?", hook.ID).Find(&deliveries)
+	if len(deliveries) != 1 || deliveries[0].Event != webhook.EventProjectReported {
+		t.Fatalf("want one project.reported delivery, got %+v", deliveries)
+	}
+	var payload struct {
+		Data webhook.ProjectReportedData `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(deliveries[0].Payload), &payload); err != nil {
+		t.Fatalf("parse payload: %v", err)
+	}
+	if payload.Data.Source != webhook.ReportSourceReview || payload.Data.Status != uint8(project.ProjectStatusNormal) ||
+		payload.Data.ProjectID != p.ID || payload.Data.ReportCount != 0 {
+		t.Fatalf("want dismissed review payload, got %+v", payload.Data)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package forum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/cdk/internal/config"
+	"github.com/linux-do/cdk/internal/logger"
+	"github.com/linux-do/cdk/internal/task"
+	"github.com/linux-do/cdk/internal/task/schedule"
+)
+
+// replyTaskPayload 话题回帖任务参数,Data 在下发时确定，重试期间保持不变
+type replyTaskPayload struct {
+	Key     string       `json:"key"`
+	Kind    Kind         `json:"kind"`
+	TopicID uint64       `json:"topic_id"`
+	Data    TemplateData `json:"data"`
+}
+
+// AnnounceKey 项目上架回帖去重键
+func AnnounceKey(projectID string) string {
+	return fmt.Sprintf("announce:%s", projectID)
+}
+
+// ProgressKey 项目结束时领取进度回帖去重键
+func ProgressKey(projectID string) string {
+	return fmt.Sprintf("progress:%s", projectID)
+}
+
+// EnqueueTopicReply 下发话题回帖任务，需在业务事务提交后调用。
+// 以去重键作为任务 ID,重复下发会被忽略;回帖不影响业务流程，失败时仅记录日志。
+func EnqueueTopicReply(ctx context.Context, kind Kind, key string, topicID uint64, data TemplateData) {
+	if !config.Config.ForumNotify.AnnounceEnabled || topicID == 0 {
+		return
+	}
+	payload, _ := json.Marshal(replyTaskPayload{Key: key, Kind: kind, TopicID: topicID, Data: data})
+	_, err := schedule.AsynqClient.EnqueueContext(
+		ctx,
+		asynq.NewTask(task.SendForumTopicReplyTask, payload),
+		asynq.TaskID("forum:reply:"+key),
+		asynq.MaxRetry(replyMaxRetry),
+	)
+	if err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		logger.ErrorF(ctx, "下发话题[%d]回帖[%s]任务失败: %v", topicID, key, err)
+	}
+}
+
+// reply 渲染模板并回复话题
+func reply(ctx context.Context, client *Client, payload *replyTaskPayload) error {
+	_, body, err := render(payload.Kind, payload.Data)
+	if err != nil {
+		return fmt.Errorf("渲染回帖模板失败: %v: %w", err, asynq.SkipRetry)
+	}
+	_, err = client.ReplyToTopic(ctx, payload.TopicID, body)
+	return err
+}
+
+// HandleSendTopicReply 处理话题回帖任务：已回帖则跳过，限流时等待下一窗口重试
+func HandleSendTopicReply(ctx context.Context, t *asynq.Task) error {
+	var payload replyTaskPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("解析任务参数失败: %w", err)
+	}
+
+	if err := sendOnce(ctx, fmt.Sprintf(replySentKeyFormat, payload.Key), func() error {
+		return reply(ctx, NewClient(), &payload)
+	}); err != nil {
+		return err
+	}
+	logger.InfoF(ctx, "话题[%d]回帖[%s]已处理", payload.TopicID, payload.Key)
+	return nil
+}
@@ -0,0 +1,138 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package forum
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/cdk/internal/config"
+	"github.com/linux-do/cdk/internal/utils"
+)
+
+// Client Discourse API 客户端
+type Client struct {
+	BaseURL     string
+	APIKey      string
+	APIUsername string
+}
+
+// NewClient 以 linuxDo 配置创建客户端
+func NewClient() *Client {
+	baseURL := config.Config.LinuxDo.BaseURL
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Client{
+		BaseURL:     strings.TrimRight(baseURL, "/"),
+		APIKey:      config.Config.LinuxDo.ApiKey,
+		APIUsername: config.Config.LinuxDo.ApiUsername,
+	}
+}
+
+// createPostRequest Discourse POST /posts.json 请求体
+type createPostRequest struct {
+	Title            string `json:"title,omitempty"`
+	Raw              string `json:"raw"`
+	TopicID          uint64 `json:"topic_id,omitempty"`
+	Archetype        string `json:"archetype,omitempty"`
+	TargetRecipients string `json:"target_recipients,omitempty"`
+}
+
+// createPostResponse Discourse 创建帖子的响应
+type createPostResponse struct {
+	ID      uint64 `json:"id"`
+	TopicID uint64 `json:"topic_id"`
+}
+
+// errorResponse Discourse 错误响应
+type errorResponse struct {
+	Errors []string `json:"errors"`
+}
+
+// SendPrivateMessage 以 APIUsername 身份向指定用户发送私信，返回新建私信话题 ID
+func (c *Client) SendPrivateMessage(ctx context.Context, username, title, raw string) (uint64, error) {
+	resp, err := c.createPost(ctx, &createPostRequest{
+		Title:            title,
+		Raw:              raw,
+		Archetype:        "private_message",
+		TargetRecipients: username,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return resp.TopicID, nil
+}
+
+// ReplyToTopic 以 APIUsername 身份回复指定话题，返回新建帖子 ID
+func (c *Client) ReplyToTopic(ctx context.Context, topicID uint64, raw string) (uint64, error) {
+	resp, err := c.createPost(ctx, &createPostRequest{Raw: raw, TopicID: topicID})
+	if err != nil {
+		return 0, err
+	}
+	return resp.ID, nil
+}
+
+// createPost 调用 Discourse 创建帖子接口。
+// 429 返回 ErrRateLimited;其余 4xx 为请求本身无效(如用户不存在或不接收私信),标记为不再重试。
+func (c *Client) createPost(ctx context.Context, req *createPostRequest) (*createPostResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	headers := map[string]string{
+		"Api-Key":      c.APIKey,
+		"Api-Username": c.APIUsername,
+		"Content-Type": "application/json",
+	}
+	resp, err := utils.Request(ctx, http.MethodPost, c.BaseURL+"/posts.json", bytes.NewReader(body), headers, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func(body io.ReadCloser) { _ = body.Close() }(resp.Body)
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		var result createPostResponse
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return nil, fmt.Errorf("解析论坛响应失败: %w", err)
+		}
+		return &result, nil
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return nil, ErrRateLimited
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		var result errorResponse
+		_ = json.NewDecoder(resp.Body).Decode(&result)
+		return nil, fmt.Errorf("论坛拒绝请求，状态码: %d %s: %w", resp.StatusCode, strings.Join(result.Errors, "; "), asynq.SkipRetry)
+	default:
+		return nil, fmt.Errorf("论坛请求失败，状态码: %d", resp.StatusCode)
+	}
+}
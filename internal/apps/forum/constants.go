@@ -0,0 +1,48 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package forum
+
+import "time"
+
+const (
+	// defaultBaseURL 未配置 linuxDo.base_url 时使用的论坛地址
+	defaultBaseURL = "https://linux.do"
+	// defaultRateLimitPerMinute 未配置时每分钟最多发送的私信数量
+	defaultRateLimitPerMinute = 20
+	// pmMaxRetry 私信发送的最大重试次数
+	pmMaxRetry = 5
+	// replyMaxRetry 话题回帖的最大重试次数
+	replyMaxRetry = 5
+	// sentTTL 已发送标记的保留时间，期间相同去重键的消息不再重复发送
+	sentTTL = 30 * 24 * time.Hour
+	// rateLimitWindow 发送限流窗口
+	rateLimitWindow = time.Minute
+)
+
+const (
+	pmSentKeyFormat      = "forum:pm:sent:%s"
+	replySentKeyFormat   = "forum:reply:sent:%s"
+	pmRateLimitKeyFormat = "forum:pm:rate:%d"
+)
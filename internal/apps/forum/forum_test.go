@@ -0,0 +1,215 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package forum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/cdk/internal/config"
+)
+
+// fakeDiscourse 模拟 Discourse 的 POST /posts.json,记录收到的请求
+type fakeDiscourse struct {
+	status   int
+	requests []createPostRequest
+	headers  []http.Header
+}
+
+func (f *fakeDiscourse) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/posts.json" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var req createPostRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	f.requests = append(f.requests, req)
+	f.headers = append(f.headers, r.Header.Clone())
+
+	switch f.status {
+	case 0, http.StatusOK:
+		_ = json.NewEncoder(w).Encode(createPostResponse{ID: 100, TopicID: 200})
+	case http.StatusTooManyRequests:
+		w.WriteHeader(f.status)
+		_, _ = w.Write([]byte(`{"errors":["请求过于频繁"],"error_type":"rate_limit","extras":{"wait_seconds":30}}`))
+	default:
+		w.WriteHeader(f.status)
+		_, _ = w.Write([]byte(`{"errors":["用户不接收私信"]}`))
+	}
+}
+
+func newTestClient(t *testing.T, f *fakeDiscourse) *Client {
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return &Client{BaseURL: srv.URL, APIKey: "key", APIUsername: "system"}
+}
+
+func TestSendPrivateMessage(t *testing.T) {
+	f := &fakeDiscourse{}
+	client := newTestClient(t, f)
+
+	topicID, err := client.SendPrivateMessage(context.Background(), "alice", "标题", "正文")
+	if err != nil || topicID != 200 {
+		t.Fatalf("want topic 200, got %d (%v)", topicID, err)
+	}
+	if len(f.requests) != 1 {
+		t.Fatalf("want 1 request, got %d", len(f.requests))
+	}
+	req, header := f.requests[0], f.headers[0]
+	if req.Archetype != "private_message" || req.TargetRecipients != "alice" || req.Title != "标题" || req.Raw != "正文" {
+		t.Fatalf("unexpected request %+v", req)
+	}
+	if header.Get("Api-Key") != "key" || header.Get("Api-Username") != "system" {
+		t.Fatalf("unexpected auth headers %v", header)
+	}
+}
+
+func TestSendPrivateMessageErrors(t *testing.T) {
+	cases := map[int]struct {
+		rateLimited bool
+		skipRetry   bool
+	}{
+		http.StatusTooManyRequests:     {rateLimited: true},
+		http.StatusUnprocessableEntity: {skipRetry: true},
+		http.StatusForbidden:           {skipRetry: true},
+		http.StatusBadGateway:          {},
+	}
+	for status, want := range cases {
+		client := newTestClient(t, &fakeDiscourse{status: status})
+		_, err := client.SendPrivateMessage(context.Background(), "alice", "标题", "正文")
+		if err == nil {
+			t.Fatalf("%d: want error", status)
+		}
+		if errors.Is(err, ErrRateLimited) != want.rateLimited || errors.Is(err, asynq.SkipRetry) != want.skipRetry {
+			t.Fatalf("%d: unexpected error %v", status, err)
+		}
+	}
+}
+
+func TestSendRendersTemplates(t *testing.T) {
+	origin := config.Config.ForumNotify
+	defer func() { config.Config.ForumNotify = origin }()
+	config.Config.ForumNotify.SiteURL = "https://cdk.example.com/"
+	config.Config.ForumNotify.Templates = map[string]config.ForumNotifyTemplate{
+		string(KindViolation): {Body: "{{.ProjectName}} 违规：{{.ProjectURL}}"},
+	}
+
+	f := &fakeDiscourse{}
+	client := newTestClient(t, f)
+	payload := &pmTaskPayload{Key: "k", Username: "bob", ProjectID: "p1", ProjectName: "测试项目"}
+
+	payload.Kind = KindWinner
+	if err := send(context.Background(), client, payload); err != nil {
+		t.Fatal(err)
+	}
+	payload.Kind = KindViolation
+	if err := send(context.Background(), client, payload); err != nil {
+		t.Fatal(err)
+	}
+
+	winner, violation := f.requests[0], f.requests[1]
+	if winner.TargetRecipients != "bob" || !strings.Contains(winner.Raw, "https://cdk.example.com/receive/p1") {
+		t.Fatalf("unexpected winner message %+v", winner)
+	}
+	// 仅覆盖正文时标题沿用内置模板
+	if violation.Title != "你的项目「测试项目」被判定违规" || violation.Raw != "测试项目 违规：https://cdk.example.com/receive/p1" {
+		t.Fatalf("unexpected violation message %+v", violation)
+	}
+}
+
+func TestRenderInvalidTemplate(t *testing.T) {
+	origin := config.Config.ForumNotify
+	defer func() { config.Config.ForumNotify = origin }()
+	config.Config.ForumNotify.Templates = map[string]config.ForumNotifyTemplate{
+		string(KindHidden): {Title: "{{.Unknown}}"},
+	}
+
+	if _, _, err := render(KindHidden, TemplateData{}); err == nil {
+		t.Fatal("unknown field should fail")
+	}
+	if _, _, err := render(Kind("other"), TemplateData{}); err == nil {
+		t.Fatal("unknown kind should fail")
+	}
+}
+
+func TestRateLimitDelay(t *testing.T) {
+	if d := RateLimitDelay(); d <= 0 || d > rateLimitWindow+time.Second {
+		t.Fatalf("unexpected delay %s", d)
+	}
+}
+
+func TestReviewKey(t *testing.T) {
+	v1 := time.Unix(1700000000, 0)
+	if ReviewKey(KindHidden, "p1", v1) == ReviewKey(KindHidden, "p1", v1.Add(time.Second)) {
+		t.Fatal("different versions should not share a key")
+	}
+	if ReviewKey(KindHidden, "p1", v1) == ReviewKey(KindViolation, "p1", v1) {
+		t.Fatal("different kinds should not share a key")
+	}
+}
+
+func TestReplyToTopic(t *testing.T) {
+	origin := config.Config.ForumNotify
+	defer func() { config.Config.ForumNotify = origin }()
+	config.Config.ForumNotify.SiteURL = "https://cdk.example.com"
+	config.Config.ForumNotify.Templates = nil
+
+	f := &fakeDiscourse{}
+	client := newTestClient(t, f)
+
+	postID, err := client.ReplyToTopic(context.Background(), 42, "回帖")
+	if err != nil || postID != 100 {
+		t.Fatalf("want post 100, got %d (%v)", postID, err)
+	}
+	payload := &replyTaskPayload{
+		Key:     ProgressKey("p1"),
+		Kind:    KindProgress,
+		TopicID: 42,
+		Data:    TemplateData{ProjectName: "测试项目", ProjectURL: ProjectURL("p1"), Claimed: 3, Total: 5},
+	}
+	if err := reply(context.Background(), client, payload); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, req := range f.requests {
+		if req.TopicID != 42 || req.Archetype != "" || req.TargetRecipients != "" || req.Title != "" {
+			t.Fatalf("reply should only carry topic and raw: %+v", req)
+		}
+	}
+	progress := f.requests[1].Raw
+	if !strings.Contains(progress, "5 位中奖者中共 3 位") || !strings.Contains(progress, "https://cdk.example.com/receive/p1") {
+		t.Fatalf("unexpected progress reply %q", progress)
+	}
+}
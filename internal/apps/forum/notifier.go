@@ -0,0 +1,165 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package forum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/cdk/internal/config"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/logger"
+	"github.com/linux-do/cdk/internal/task"
+	"github.com/linux-do/cdk/internal/task/schedule"
+)
+
+// pmTaskPayload 私信任务参数,Key 为去重键，同一 Key 只会成功发送一次
+type pmTaskPayload struct {
+	Key         string `json:"key"`
+	Kind        Kind   `json:"kind"`
+	Username    string `json:"username"`
+	ProjectID   string `json:"project_id"`
+	ProjectName string `json:"project_name"`
+}
+
+// ProjectURL 项目领取页地址
+func ProjectURL(projectID string) string {
+	return strings.TrimRight(config.Config.ForumNotify.SiteURL, "/") + "/receive/" + projectID
+}
+
+// WinnerKey 中奖私信去重键
+func WinnerKey(projectID, username string) string {
+	return fmt.Sprintf("winner:%s:%s", projectID, username)
+}
+
+// ReviewKey 项目状态变更私信去重键,version 取变更前项目的更新时间，区分同一项目的多次隐藏
+func ReviewKey(kind Kind, projectID string, version time.Time) string {
+	return fmt.Sprintf("%s:%s:%d", kind, projectID, version.UnixNano())
+}
+
+// EnqueuePrivateMessage 下发论坛私信任务，需在业务事务提交后调用。
+// 以去重键作为任务 ID,重复下发会被忽略;私信不影响业务流程，失败时仅记录日志。
+func EnqueuePrivateMessage(ctx context.Context, kind Kind, key, username, projectID, projectName string) {
+	if !config.Config.ForumNotify.Enabled || username == "" {
+		return
+	}
+	payload, _ := json.Marshal(pmTaskPayload{
+		Key:         key,
+		Kind:        kind,
+		Username:    username,
+		ProjectID:   projectID,
+		ProjectName: projectName,
+	})
+	_, err := schedule.AsynqClient.EnqueueContext(
+		ctx,
+		asynq.NewTask(task.SendForumPrivateMessageTask, payload),
+		asynq.TaskID("forum:pm:"+key),
+		asynq.MaxRetry(pmMaxRetry),
+	)
+	if err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		logger.ErrorF(ctx, "下发论坛私信[%s]任务失败: %v", key, err)
+	}
+}
+
+// acquireRateLimit 占用当前窗口的发送额度，超出时返回 ErrRateLimited
+func acquireRateLimit(ctx context.Context) error {
+	limit := config.Config.ForumNotify.RateLimitPerMinute
+	if limit <= 0 {
+		limit = defaultRateLimitPerMinute
+	}
+	key := fmt.Sprintf(pmRateLimitKeyFormat, time.Now().Unix()/int64(rateLimitWindow.Seconds()))
+	count, err := db.Redis.Incr(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if count == 1 {
+		db.Redis.Expire(ctx, key, 2*rateLimitWindow)
+	}
+	if count > int64(limit) {
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// RateLimitDelay 限流时的重试等待时间：等到下一个限流窗口
+func RateLimitDelay() time.Duration {
+	now := time.Now()
+	return now.Truncate(rateLimitWindow).Add(rateLimitWindow).Sub(now) + time.Second
+}
+
+// send 渲染模板并发送私信
+func send(ctx context.Context, client *Client, payload *pmTaskPayload) error {
+	title, body, err := render(payload.Kind, TemplateData{
+		Username:    payload.Username,
+		ProjectName: payload.ProjectName,
+		ProjectURL:  ProjectURL(payload.ProjectID),
+	})
+	if err != nil {
+		return fmt.Errorf("渲染私信模板失败: %v: %w", err, asynq.SkipRetry)
+	}
+	_, err = client.SendPrivateMessage(ctx, payload.Username, title, body)
+	return err
+}
+
+// sendOnce 以 sentKey 标记保证同一条消息只成功发送一次，发送前占用限流额度
+func sendOnce(ctx context.Context, sentKey string, fn func() error) error {
+	if exists, err := db.Redis.Exists(ctx, sentKey).Result(); err != nil {
+		return err
+	} else if exists > 0 {
+		return nil
+	}
+	if err := acquireRateLimit(ctx); err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+	if err := db.Redis.Set(ctx, sentKey, time.Now().Unix(), sentTTL).Err(); err != nil {
+		logger.ErrorF(ctx, "记录论坛消息[%s]已发送失败: %v", sentKey, err)
+	}
+	return nil
+}
+
+// HandleSendPrivateMessage 处理论坛私信任务：已发送则跳过，限流时等待下一窗口重试
+func HandleSendPrivateMessage(ctx context.Context, t *asynq.Task) error {
+	var payload pmTaskPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("解析任务参数失败: %w", err)
+	}
+
+	if err := sendOnce(ctx, fmt.Sprintf(pmSentKeyFormat, payload.Key), func() error {
+		return send(ctx, NewClient(), &payload)
+	}); err != nil {
+		return err
+	}
+	logger.InfoF(ctx, "论坛私信[%s]已处理，收件人 %s", payload.Key, payload.Username)
+	return nil
+}
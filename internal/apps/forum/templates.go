@@ -0,0 +1,123 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package forum
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"text/template"
+
+	"github.com/linux-do/cdk/internal/config"
+)
+
+// Kind 消息类型，同时作为 forum_notify.templates 的配置键;回帖类消息仅使用 Body
+type Kind string
+
+const (
+	KindWinner    Kind = "winner"
+	KindHidden    Kind = "hidden"
+	KindViolation Kind = "violation"
+	KindAnnounce  Kind = "announce"
+	KindProgress  Kind = "progress"
+)
+
+// TemplateData 模板可用字段,Claimed / Total 仅用于领取进度回帖
+type TemplateData struct {
+	Username    string
+	ProjectName string
+	ProjectURL  string
+	Claimed     int64
+	Total       int64
+}
+
+// defaultTemplates 内置模板，配置项留空时使用
+var defaultTemplates = map[Kind]config.ForumNotifyTemplate{
+	KindWinner: {
+		Title: "你在「{{.ProjectName}}」中奖了",
+		Body:  "@{{.Username}} 你好，你在「{{.ProjectName}}」中奖了，奖品已准备好，请前往 {{.ProjectURL}} 领取。",
+	},
+	KindHidden: {
+		Title: "你的项目「{{.ProjectName}}」已被隐藏",
+		Body:  "@{{.Username}} 你好，你的项目「{{.ProjectName}}」因收到多次举报已被隐藏，等待管理员审核。\n\n{{.ProjectURL}}",
+	},
+	KindViolation: {
+		Title: "你的项目「{{.ProjectName}}」被判定违规",
+		Body:  "@{{.Username}} 你好，你的项目「{{.ProjectName}}」经管理员审核被判定违规，已停止分发。\n\n{{.ProjectURL}}",
+	},
+	KindAnnounce: {
+		Body: "本次抽奖的奖品已上架「{{.ProjectName}}」，中奖的佬友请登录后前往领取：\n\n{{.ProjectURL}}",
+	},
+	KindProgress: {
+		Body: "「{{.ProjectName}}」已结束，{{.Total}} 位中奖者中共 {{.Claimed}} 位完成领取，感谢参与。\n\n{{.ProjectURL}}",
+	},
+}
+
+// templateFor 返回私信模板，配置中未填写的标题或正文使用内置模板
+func templateFor(kind Kind) (config.ForumNotifyTemplate, error) {
+	tpl, ok := defaultTemplates[kind]
+	if !ok {
+		return tpl, errors.New(TemplateNotFound)
+	}
+	if custom, ok := config.Config.ForumNotify.Templates[string(kind)]; ok {
+		if strings.TrimSpace(custom.Title) != "" {
+			tpl.Title = custom.Title
+		}
+		if strings.TrimSpace(custom.Body) != "" {
+			tpl.Body = custom.Body
+		}
+	}
+	return tpl, nil
+}
+
+// render 渲染私信标题与正文
+func render(kind Kind, data TemplateData) (string, string, error) {
+	tpl, err := templateFor(kind)
+	if err != nil {
+		return "", "", err
+	}
+	title, err := execute(string(kind)+":title", tpl.Title, data)
+	if err != nil {
+		return "", "", err
+	}
+	body, err := execute(string(kind)+":body", tpl.Body, data)
+	if err != nil {
+		return "", "", err
+	}
+	return title, body, nil
+}
+
+// execute 执行单个模板，引用不存在的字段时返回错误以便尽早发现配置问题
+func execute(name, text string, data TemplateData) (string, error) {
+	t, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
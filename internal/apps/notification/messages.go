@@ -0,0 +1,122 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package notification
+
+import (
+	"fmt"
+	"time"
+)
+
+// Message 待发送的通知内容，由各业务状态变更处构造
+type Message struct {
+	Type      Type
+	ProjectID string
+	Title     string
+	Content   string
+}
+
+// Verdict 管理员对被举报项目的审核结论
+type Verdict string
+
+const (
+	VerdictDismissed Verdict = "dismissed"
+	VerdictHidden    Verdict = "hidden"
+	VerdictViolation Verdict = "violation"
+)
+
+// describe 审核结论的展示文案
+func (v Verdict) describe() string {
+	switch v {
+	case VerdictHidden:
+		return "已被隐藏"
+	case VerdictViolation:
+		return "已被判定违规"
+	default:
+		return "经审核未发现违规，已恢复展示"
+	}
+}
+
+// WaitlistOffered 候补排到时通知用户在保留期内领取或支付
+func WaitlistOffered(projectID, projectName string, paid bool, holdExpireAt time.Time) Message {
+	action := "领取"
+	if paid {
+		action = "完成支付"
+	}
+	return Message{
+		Type:      TypeWaitlistOffered,
+		ProjectID: projectID,
+		Title:     "候补已轮到你",
+		Content: fmt.Sprintf("项目「%s」已为你保留一份内容，请在 %s 前%s，逾期将顺延给下一位候补",
+			projectName, holdExpireAt.Format(time.DateTime), action),
+	}
+}
+
+// OrderRefunded 付费订单退款完成后通知付款人
+func OrderRefunded(projectID, outTradeNo, amount, reason string) Message {
+	content := fmt.Sprintf("订单 %s 已退款 %s", outTradeNo, amount)
+	if reason != "" {
+		content += "，原因：" + reason
+	}
+	return Message{Type: TypeOrderRefunded, ProjectID: projectID, Title: "订单已退款", Content: content}
+}
+
+// ReportReviewed 通知举报人其举报的项目审核结果
+func ReportReviewed(projectID, projectName string, verdict Verdict) Message {
+	return Message{
+		Type:      TypeReportReviewed,
+		ProjectID: projectID,
+		Title:     "举报已处理",
+		Content:   fmt.Sprintf("你举报的项目「%s」%s，感谢你的反馈", projectName, verdict.describe()),
+	}
+}
+
+// ProjectHidden 举报数达到阈值、项目被自动隐藏时通知创建者
+func ProjectHidden(projectID, projectName string, reportCount uint8) Message {
+	return Message{
+		Type:      TypeProjectHidden,
+		ProjectID: projectID,
+		Title:     "项目已被隐藏",
+		Content:   fmt.Sprintf("你的项目「%s」收到 %d 次举报，已被自动隐藏，等待管理员审核", projectName, reportCount),
+	}
+}
+
+// ProjectReviewed 管理员审核项目后通知创建者
+func ProjectReviewed(projectID, projectName string, verdict Verdict) Message {
+	return Message{
+		Type:      TypeProjectReviewed,
+		ProjectID: projectID,
+		Title:     "项目审核结果",
+		Content:   fmt.Sprintf("你的项目「%s」%s", projectName, verdict.describe()),
+	}
+}
+
+// ItemReplaced 创建者替换已领取的内容后通知领取人
+func ItemReplaced(projectID, projectName, reason string) Message {
+	content := fmt.Sprintf("你在项目「%s」领取的内容已被创建者替换，请重新查看", projectName)
+	if reason != "" {
+		content += "，原因：" + reason
+	}
+	return Message{Type: TypeItemReplaced, ProjectID: projectID, Title: "领取内容已更新", Content: content}
+}
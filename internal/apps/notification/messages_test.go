@@ -0,0 +1,76 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package notification
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitlistOffered(t *testing.T) {
+	expireAt := time.Date(2025, 1, 2, 15, 4, 5, 0, time.Local)
+	free := WaitlistOffered("p1", "测试项目", false, expireAt)
+	if free.Type != TypeWaitlistOffered || free.ProjectID != "p1" {
+		t.Fatalf("unexpected message %+v", free)
+	}
+	if !strings.Contains(free.Content, "2025-01-02 15:04:05") || !strings.Contains(free.Content, "前领取") {
+		t.Fatalf("unexpected content %q", free.Content)
+	}
+	if paid := WaitlistOffered("p1", "测试项目", true, expireAt); !strings.Contains(paid.Content, "前完成支付") {
+		t.Fatalf("unexpected content %q", paid.Content)
+	}
+}
+
+func TestVerdict(t *testing.T) {
+	cases := map[Verdict]string{
+		VerdictDismissed: "未发现违规",
+		VerdictHidden:    "已被隐藏",
+		VerdictViolation: "已被判定违规",
+	}
+	for verdict, want := range cases {
+		if msg := ReportReviewed("p1", "测试项目", verdict); !strings.Contains(msg.Content, want) {
+			t.Fatalf("%s: want %q in %q", verdict, want, msg.Content)
+		}
+	}
+}
+
+func TestOptionalReason(t *testing.T) {
+	if msg := OrderRefunded("p1", "T1", "1.00 LDC", ""); strings.Contains(msg.Content, "原因") {
+		t.Fatalf("empty reason should be omitted: %q", msg.Content)
+	}
+	if msg := ItemReplaced("p1", "测试项目", "原 CDK 失效"); !strings.HasSuffix(msg.Content, "原因：原 CDK 失效") {
+		t.Fatalf("unexpected content %q", msg.Content)
+	}
+}
+
+func TestClip(t *testing.T) {
+	if got := clip("通知内容", 2); got != "通知" {
+		t.Fatalf("want 通知, got %q", got)
+	}
+	if got := clip("abc", 5); got != "abc" {
+		t.Fatalf("want abc, got %q", got)
+	}
+}
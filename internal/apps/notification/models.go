@@ -0,0 +1,63 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package notification
+
+import "time"
+
+// Type 站内通知类型
+type Type string
+
+const (
+	TypeWaitlistOffered Type = "waitlist.offered"
+	TypeOrderRefunded   Type = "order.refunded"
+	TypeReportReviewed  Type = "report.reviewed"
+	TypeProjectHidden   Type = "project.hidden"
+	TypeProjectReviewed Type = "project.reviewed"
+	TypeItemReplaced    Type = "item.replaced"
+)
+
+// Notification 站内通知,ReadAt 为空表示未读。
+//
+// 联合索引：
+//   - idx_user_id (user_id, id)：按用户分页查询
+//   - idx_user_read (user_id, read_at)：统计未读数量
+type Notification struct {
+	ID        uint64     `json:"id" gorm:"primaryKey;autoIncrement;index:idx_user_id,priority:2"`
+	UserID    uint64     `json:"user_id" gorm:"not null;index:idx_user_id,priority:1;index:idx_user_read,priority:1"`
+	Type      Type       `json:"type" gorm:"size:32;not null"`
+	ProjectID string     `json:"project_id" gorm:"size:64"`
+	Title     string     `json:"title" gorm:"size:64;not null"`
+	Content   string     `json:"content" gorm:"size:512"`
+	ReadAt    *time.Time `json:"read_at" gorm:"index:idx_user_read,priority:2"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName 自定义表名
+func (Notification) TableName() string { return "notifications" }
+
+// IsRead 是否已读
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
@@ -0,0 +1,100 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package notification
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/linux-do/cdk/internal/db"
+	"gorm.io/gorm"
+)
+
+// clip 按字符截断至 limit
+func clip(s string, limit int) string {
+	r := []rune(s)
+	if len(r) <= limit {
+		return s
+	}
+	return string(r[:limit])
+}
+
+// Send 在调用方事务中为指定用户写入通知，与触发通知的状态变更一同提交或回滚
+func Send(tx *gorm.DB, msg Message, userIDs ...uint64) error {
+	if len(userIDs) <= 0 {
+		return nil
+	}
+	seen := make(map[uint64]bool, len(userIDs))
+	notifications := make([]Notification, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		notifications = append(notifications, Notification{
+			UserID:    userID,
+			Type:      msg.Type,
+			ProjectID: msg.ProjectID,
+			Title:     clip(msg.Title, maxTitleLength),
+			Content:   clip(msg.Content, maxContentLength),
+		})
+	}
+	return tx.CreateInBatches(&notifications, 100).Error
+}
+
+// CountUnread 统计用户未读通知数量
+func CountUnread(ctx context.Context, userID uint64) (int64, error) {
+	var count int64
+	err := db.DB(ctx).Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead 将用户的单条通知标记为已读，重复标记无副作用
+func MarkRead(ctx context.Context, userID, notificationID uint64) error {
+	n := &Notification{}
+	if err := db.DB(ctx).Where("id = ? AND user_id = ?", notificationID, userID).First(n).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New(NotificationNotFound)
+		}
+		return err
+	}
+	if n.IsRead() {
+		return nil
+	}
+	return db.DB(ctx).Model(&Notification{}).
+		Where("id = ? AND read_at IS NULL", n.ID).
+		Update("read_at", time.Now()).Error
+}
+
+// MarkAllRead 将用户的全部未读通知标记为已读，返回本次标记数量
+func MarkAllRead(ctx context.Context, userID uint64) (int64, error) {
+	result := db.DB(ctx).Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,153 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package notification
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/db"
+)
+
+type NotificationResponse struct {
+	ErrorMsg string      `json:"error_msg"`
+	Data     interface{} `json:"data"`
+}
+
+type ListNotificationsRequest struct {
+	Current    int  `json:"current" form:"current" binding:"min=1"`
+	Size       int  `json:"size" form:"size" binding:"min=1,max=100"`
+	UnreadOnly bool `json:"unread_only" form:"unread_only"`
+}
+
+type ListNotificationsResponseData struct {
+	Total   int64          `json:"total"`
+	Results []Notification `json:"results"`
+}
+
+type ListNotificationsResponse struct {
+	ErrorMsg string                        `json:"error_msg"`
+	Data     ListNotificationsResponseData `json:"data"`
+}
+
+// ListNotifications 分页获取当前用户的站内通知
+// @Tags notification
+// @Produce json
+// @Param request query ListNotificationsRequest true "request query"
+// @Success 200 {object} ListNotificationsResponse
+// @Router /api/v1/notifications [get]
+func ListNotifications(c *gin.Context) {
+	req := &ListNotificationsRequest{}
+	if err := c.ShouldBindQuery(req); err != nil {
+		c.JSON(http.StatusBadRequest, ListNotificationsResponse{ErrorMsg: err.Error()})
+		return
+	}
+	offset := (req.Current - 1) * req.Size
+
+	query := db.DB(c.Request.Context()).Model(&Notification{}).Where("user_id = ?", oauth.GetUserIDFromContext(c))
+	if req.UnreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ListNotificationsResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	var notifications []Notification
+	if err := query.Order("id DESC").Offset(offset).Limit(req.Size).Find(&notifications).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ListNotificationsResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListNotificationsResponse{
+		Data: ListNotificationsResponseData{Total: total, Results: notifications},
+	})
+}
+
+type UnreadCountResponseData struct {
+	Unread int64 `json:"unread"`
+}
+
+type UnreadCountResponse struct {
+	ErrorMsg string                  `json:"error_msg"`
+	Data     UnreadCountResponseData `json:"data"`
+}
+
+// GetUnreadCount 获取当前用户的未读通知数量
+// @Tags notification
+// @Produce json
+// @Success 200 {object} UnreadCountResponse
+// @Router /api/v1/notifications/unread-count [get]
+func GetUnreadCount(c *gin.Context) {
+	unread, err := CountUnread(c.Request.Context(), oauth.GetUserIDFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, UnreadCountResponse{ErrorMsg: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, UnreadCountResponse{Data: UnreadCountResponseData{Unread: unread}})
+}
+
+// MarkNotificationRead 将单条通知标记为已读
+// @Tags notification
+// @Produce json
+// @Param id path int true "通知ID"
+// @Success 200 {object} NotificationResponse
+// @Router /api/v1/notifications/{id}/read [put]
+func MarkNotificationRead(c *gin.Context) {
+	notificationID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NotificationResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	if err := MarkRead(c.Request.Context(), oauth.GetUserIDFromContext(c), notificationID); err != nil {
+		if err.Error() == NotificationNotFound {
+			c.JSON(http.StatusNotFound, NotificationResponse{ErrorMsg: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, NotificationResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, NotificationResponse{})
+}
+
+// MarkAllNotificationsRead 将当前用户的全部未读通知标记为已读，返回本次标记数量
+// @Tags notification
+// @Produce json
+// @Success 200 {object} NotificationResponse
+// @Router /api/v1/notifications/read-all [put]
+func MarkAllNotificationsRead(c *gin.Context) {
+	marked, err := MarkAllRead(c.Request.Context(), oauth.GetUserIDFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NotificationResponse{ErrorMsg: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, NotificationResponse{Data: gin.H{"marked": marked}})
+}
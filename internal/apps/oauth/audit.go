@@ -40,6 +40,9 @@ func LogForAudit(ctx context.Context, user *User, c *gin.Context) {
 		UserAgent:  c.Request.UserAgent(),
 		Referer:    c.Request.Referer(),
 	}
+	if token, ok := GetAPITokenFromContext(c); ok {
+		auditLog.TokenID = token.ID
+	}
 	auditJSON, err := json.Marshal(auditLog)
 	if err != nil {
 		logger.ErrorF(ctx, "[LoginRequiredAudit] marshal failed: %v", err)
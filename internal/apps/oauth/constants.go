@@ -50,6 +50,14 @@ const (
 	UserAllBadges                = "user:badges"
 )
 
+const (
+	APITokenObjKey        = "api_token_obj"
+	apiTokenPrefix        = "cdk_"
+	apiTokenMaxLifetime   = 365 * 24 * time.Hour
+	apiTokenTouchInterval = time.Minute
+	maxAPITokensPerUser   = 20
+)
+
 const (
 	BaseUserScore = 100
 	MaxUserScore  = 100
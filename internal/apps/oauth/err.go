@@ -28,4 +28,11 @@ const (
 	UnAuthorized  = "未登录"
 	InvalidState  = "非法登录请求"
 	BannedAccount = "账号已被封禁"
+	// API Token 相关
+	APITokenInvalid       = "API Token 无效、已撤销或已过期"
+	APITokenScopeDenied   = "API Token 无权访问该接口"
+	APITokenScopeInvalid  = "API Token 授权范围无效"
+	APITokenExpireInvalid = "API Token 过期时间需晚于当前时间且不超过一年"
+	APITokenTooMany       = "有效的 API Token 数量已达上限"
+	APITokenNotFound      = "API Token 不存在或已撤销"
 )
@@ -41,8 +41,11 @@ type loginRequiredAuditLog struct {
 	RequestURI string `json:"request_uri"`
 	UserAgent  string `json:"user_agent"`
 	Referer    string `json:"referer"`
+	TokenID    uint64 `json:"token_id,omitempty"`
 }
 
+// LoginRequired 校验 session 登录，或通过 Authorization: Bearer 携带的 API Token 登录;
+// Token 仅能访问经 AllowTokenRoute 声明且授权范围匹配的接口。
 func LoginRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// init trace
@@ -51,6 +54,19 @@ func LoginRequired() gin.HandlerFunc {
 
 		// load user
 		userId := GetUserIDFromContext(c)
+		if plain, ok := bearerToken(c); ok {
+			token, err := AuthenticateAPIToken(ctx, plain, c.ClientIP())
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error_msg": err.Error(), "data": nil})
+				return
+			}
+			if scope, allowed := tokenRouteScope(c.Request.Method, c.FullPath()); !allowed || !token.HasScope(scope) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error_msg": APITokenScopeDenied, "data": nil})
+				return
+			}
+			c.Set(APITokenObjKey, token)
+			userId = token.UserID
+		}
 		if userId <= 0 {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error_msg": UnAuthorized, "data": nil})
 			return
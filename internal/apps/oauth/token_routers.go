@@ -0,0 +1,154 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package oauth
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/cdk/internal/db"
+)
+
+type ListAPITokensRequest struct {
+	Current int `json:"current" form:"current" binding:"min=1"`
+	Size    int `json:"size" form:"size" binding:"min=1,max=100"`
+}
+
+type ListAPITokensResponseData struct {
+	Total   int64      `json:"total"`
+	Results []APIToken `json:"results"`
+}
+
+type ListAPITokensResponse struct {
+	ErrorMsg string                    `json:"error_msg"`
+	Data     ListAPITokensResponseData `json:"data"`
+}
+
+// ListAPITokens 获取当前用户签发的 API Token(不含明文)
+// @Tags oauth
+// @Produce json
+// @Param request query ListAPITokensRequest true "request query"
+// @Success 200 {object} ListAPITokensResponse
+// @Router /api/v1/users/tokens [get]
+func ListAPITokens(c *gin.Context) {
+	req := &ListAPITokensRequest{}
+	if err := c.ShouldBindQuery(req); err != nil {
+		c.JSON(http.StatusBadRequest, ListAPITokensResponse{ErrorMsg: err.Error()})
+		return
+	}
+	offset := (req.Current - 1) * req.Size
+
+	query := db.DB(c.Request.Context()).Model(&APIToken{}).Where("user_id = ?", GetUserIDFromContext(c))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ListAPITokensResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	var tokens []APIToken
+	if err := query.Order("id DESC").Offset(offset).Limit(req.Size).Find(&tokens).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ListAPITokensResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListAPITokensResponse{Data: ListAPITokensResponseData{Total: total, Results: tokens}})
+}
+
+type CreateAPITokenRequestBody struct {
+	Name     string       `json:"name" binding:"required,min=1,max=64"`
+	Scopes   []TokenScope `json:"scopes" binding:"required,min=1,dive,oneof=projects:write items:read orders:read"`
+	ExpireAt time.Time    `json:"expire_at" binding:"required"`
+}
+
+type CreateAPITokenResponseData struct {
+	APIToken `json:",inline"`
+	Token    string `json:"token"`
+}
+
+type CreateAPITokenResponse struct {
+	ErrorMsg string                     `json:"error_msg"`
+	Data     CreateAPITokenResponseData `json:"data"`
+}
+
+// CreateAPIToken 签发 API Token,明文仅在本次响应中返回
+// @Tags oauth
+// @Accept json
+// @Produce json
+// @Param token body CreateAPITokenRequestBody true "Token 信息"
+// @Success 200 {object} CreateAPITokenResponse
+// @Router /api/v1/users/tokens [post]
+func CreateAPIToken(c *gin.Context) {
+	var req CreateAPITokenRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreateAPITokenResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	token, plain, err := IssueAPIToken(c.Request.Context(), GetUserIDFromContext(c), req.Name, req.Scopes, req.ExpireAt)
+	if err != nil {
+		switch err.Error() {
+		case APITokenScopeInvalid, APITokenExpireInvalid, APITokenTooMany:
+			c.JSON(http.StatusBadRequest, CreateAPITokenResponse{ErrorMsg: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, CreateAPITokenResponse{ErrorMsg: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, CreateAPITokenResponse{Data: CreateAPITokenResponseData{APIToken: *token, Token: plain}})
+}
+
+type RevokeAPITokenResponse struct {
+	ErrorMsg string      `json:"error_msg"`
+	Data     interface{} `json:"data"`
+}
+
+// RevokeAPIToken 撤销 API Token,撤销后立即失效
+// @Tags oauth
+// @Produce json
+// @Param id path int true "Token ID"
+// @Success 200 {object} RevokeAPITokenResponse
+// @Router /api/v1/users/tokens/{id} [delete]
+func RevokeAPIToken(c *gin.Context) {
+	tokenID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, RevokeAPITokenResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	if err := RevokeUserAPIToken(c.Request.Context(), GetUserIDFromContext(c), tokenID); err != nil {
+		if err.Error() == APITokenNotFound {
+			c.JSON(http.StatusNotFound, RevokeAPITokenResponse{ErrorMsg: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, RevokeAPITokenResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, RevokeAPITokenResponse{})
+}
@@ -0,0 +1,232 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/utils"
+	"gorm.io/gorm"
+)
+
+// TokenScope API Token 的授权范围
+type TokenScope string
+
+const (
+	TokenScopeProjectsWrite TokenScope = "projects:write"
+	TokenScopeItemsRead     TokenScope = "items:read"
+	TokenScopeOrdersRead    TokenScope = "orders:read"
+)
+
+// APIToken 用户签发的个人 API Token,仅保存 SHA-256 摘要,明文只在创建时返回一次。
+// Prefix 为明文前缀，便于用户辨认;LastUsedAt/LastUsedIP 按 apiTokenTouchInterval 节流更新。
+type APIToken struct {
+	ID         uint64            `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID     uint64            `json:"user_id" gorm:"index;not null"`
+	Name       string            `json:"name" gorm:"size:64;not null"`
+	Prefix     string            `json:"prefix" gorm:"size:16;not null"`
+	TokenHash  string            `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	Scopes     utils.StringArray `json:"scopes" gorm:"type:json"`
+	ExpireAt   time.Time         `json:"expire_at" gorm:"index"`
+	LastUsedAt *time.Time        `json:"last_used_at"`
+	LastUsedIP string            `json:"last_used_ip" gorm:"size:64"`
+	RevokedAt  *time.Time        `json:"revoked_at"`
+	CreatedAt  time.Time         `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName 自定义表名
+func (APIToken) TableName() string { return "api_tokens" }
+
+// HasScope 是否包含指定授权范围
+func (t *APIToken) HasScope(scope TokenScope) bool {
+	return slices.Contains(t.Scopes, string(scope))
+}
+
+// IsValidTokenScope 是否为支持的授权范围
+func IsValidTokenScope(scope TokenScope) bool {
+	switch scope {
+	case TokenScopeProjectsWrite, TokenScopeItemsRead, TokenScopeOrdersRead:
+		return true
+	}
+	return false
+}
+
+var (
+	tokenRoutesMu sync.RWMutex
+	tokenRoutes   = map[string]TokenScope{}
+)
+
+// tokenRouteKey 接口标识:请求方法 + gin 注册路径
+func tokenRouteKey(method, fullPath string) string {
+	return method + " " + fullPath
+}
+
+// AllowTokenRoute 声明接口可通过 API Token 访问及所需的授权范围，未声明的接口仅支持 session 登录
+func AllowTokenRoute(method, fullPath string, scope TokenScope) {
+	tokenRoutesMu.Lock()
+	defer tokenRoutesMu.Unlock()
+	tokenRoutes[tokenRouteKey(method, fullPath)] = scope
+}
+
+// tokenRouteScope 返回接口所需的授权范围
+func tokenRouteScope(method, fullPath string) (TokenScope, bool) {
+	tokenRoutesMu.RLock()
+	defer tokenRoutesMu.RUnlock()
+	scope, ok := tokenRoutes[tokenRouteKey(method, fullPath)]
+	return scope, ok
+}
+
+// genAPIToken 生成带固定前缀的 API Token 明文
+func genAPIToken() (string, error) {
+	var b [24]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return apiTokenPrefix + hex.EncodeToString(b[:]), nil
+}
+
+// hashAPIToken 计算 Token 摘要;Token 为高熵随机串，无需加盐
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// bearerToken 从 Authorization 头中解析 Bearer Token
+func bearerToken(c *gin.Context) (string, bool) {
+	header := c.GetHeader("Authorization")
+	if len(header) <= len("Bearer ") || !strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return "", false
+	}
+	return strings.TrimSpace(header[len("Bearer "):]), true
+}
+
+// IssueAPIToken 为用户签发 API Token,返回记录及仅此一次可见的明文
+func IssueAPIToken(ctx context.Context, userID uint64, name string, scopes []TokenScope, expireAt time.Time) (*APIToken, string, error) {
+	if len(scopes) <= 0 {
+		return nil, "", errors.New(APITokenScopeInvalid)
+	}
+	scopeNames := make(utils.StringArray, 0, len(scopes))
+	for _, scope := range scopes {
+		if !IsValidTokenScope(scope) {
+			return nil, "", errors.New(APITokenScopeInvalid)
+		}
+		if !slices.Contains(scopeNames, string(scope)) {
+			scopeNames = append(scopeNames, string(scope))
+		}
+	}
+	now := time.Now()
+	if !expireAt.After(now) || expireAt.After(now.Add(apiTokenMaxLifetime)) {
+		return nil, "", errors.New(APITokenExpireInvalid)
+	}
+
+	var count int64
+	if err := db.DB(ctx).Model(&APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expire_at > ?", userID, now).
+		Count(&count).Error; err != nil {
+		return nil, "", err
+	}
+	if count >= maxAPITokensPerUser {
+		return nil, "", errors.New(APITokenTooMany)
+	}
+
+	plain, err := genAPIToken()
+	if err != nil {
+		return nil, "", err
+	}
+	token := &APIToken{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    plain[:len(apiTokenPrefix)+6],
+		TokenHash: hashAPIToken(plain),
+		Scopes:    scopeNames,
+		ExpireAt:  expireAt,
+	}
+	if err := db.DB(ctx).Create(token).Error; err != nil {
+		return nil, "", err
+	}
+	return token, plain, nil
+}
+
+// RevokeUserAPIToken 撤销用户自己的 API Token
+func RevokeUserAPIToken(ctx context.Context, userID, tokenID uint64) error {
+	now := time.Now()
+	result := db.DB(ctx).Model(&APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", tokenID, userID).
+		Update("revoked_at", &now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(APITokenNotFound)
+	}
+	return nil
+}
+
+// AuthenticateAPIToken 校验 Token 是否存在、未撤销且未过期，并节流记录最近使用时间与 IP
+func AuthenticateAPIToken(ctx context.Context, plain, clientIP string) (*APIToken, error) {
+	if !strings.HasPrefix(plain, apiTokenPrefix) {
+		return nil, errors.New(APITokenInvalid)
+	}
+	now := time.Now()
+	token := &APIToken{}
+	err := db.DB(ctx).
+		Where("token_hash = ? AND revoked_at IS NULL AND expire_at > ?", hashAPIToken(plain), now).
+		First(token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New(APITokenInvalid)
+	} else if err != nil {
+		return nil, err
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= apiTokenTouchInterval || token.LastUsedIP != clientIP {
+		if err := db.DB(ctx).Model(token).
+			Updates(map[string]interface{}{"last_used_at": &now, "last_used_ip": clientIP}).Error; err != nil {
+			return nil, err
+		}
+		token.LastUsedAt = &now
+		token.LastUsedIP = clientIP
+	}
+	return token, nil
+}
+
+// GetAPITokenFromContext 获取当前请求使用的 API Token,session 登录时返回 false
+func GetAPITokenFromContext(c *gin.Context) (*APIToken, bool) {
+	token, exists := c.Get(APITokenObjKey)
+	if !exists {
+		return nil, false
+	}
+	t, ok := token.(*APIToken)
+	return t, ok
+}
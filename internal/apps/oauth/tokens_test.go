@@ -0,0 +1,85 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package oauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/cdk/internal/utils"
+)
+
+func TestBearerToken(t *testing.T) {
+	cases := map[string]struct {
+		header string
+		want   string
+		ok     bool
+	}{
+		"bearer":     {"Bearer cdk_abc", "cdk_abc", true},
+		"lower case": {"bearer cdk_abc", "cdk_abc", true},
+		"missing":    {"", "", false},
+		"basic":      {"Basic dXNlcjpwYXNz", "", false},
+		"empty":      {"Bearer ", "", false},
+	}
+	for name, c := range cases {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		if c.header != "" {
+			ctx.Request.Header.Set("Authorization", c.header)
+		}
+		got, ok := bearerToken(ctx)
+		if got != c.want || ok != c.ok {
+			t.Fatalf("%s: want (%q, %v), got (%q, %v)", name, c.want, c.ok, got, ok)
+		}
+	}
+}
+
+func TestAPITokenScopes(t *testing.T) {
+	plain, err := genAPIToken()
+	if err != nil || !strings.HasPrefix(plain, apiTokenPrefix) {
+		t.Fatalf("unexpected token %q, err=%v", plain, err)
+	}
+	if hashAPIToken(plain) == hashAPIToken(plain+"x") || len(hashAPIToken(plain)) != 64 {
+		t.Fatalf("unexpected token hash")
+	}
+
+	AllowTokenRoute(http.MethodPost, "/api/v1/projects", TokenScopeProjectsWrite)
+	token := &APIToken{Scopes: utils.StringArray{string(TokenScopeProjectsWrite)}}
+	if scope, ok := tokenRouteScope(http.MethodPost, "/api/v1/projects"); !ok || !token.HasScope(scope) {
+		t.Fatalf("want project creation allowed")
+	}
+	if _, ok := tokenRouteScope(http.MethodGet, "/api/v1/users/tokens"); ok {
+		t.Fatalf("want undeclared route denied")
+	}
+	if token.HasScope(TokenScopeOrdersRead) {
+		t.Fatalf("want orders scope denied")
+	}
+	if IsValidTokenScope("admin") {
+		t.Fatalf("want unknown scope rejected")
+	}
+}
@@ -51,7 +51,11 @@ func GetUserIDFromSession(s sessions.Session) uint64 {
 	return userID
 }
 
+// GetUserIDFromContext 获取当前登录用户 ID,已通过 LoginRequired 时以其载入的用户为准(含 API Token 登录)
 func GetUserIDFromContext(c *gin.Context) uint64 {
+	if user, ok := GetUserFromContext(c); ok {
+		return user.ID
+	}
 	session := sessions.Default(c)
 	return GetUserIDFromSession(session)
 }
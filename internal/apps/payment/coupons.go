@@ -0,0 +1,188 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/linux-do/cdk/internal/apps/project"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// CouponType 优惠码的折扣方式
+type CouponType int8
+
+const (
+	// CouponTypePercent 按百分比减免,Value 为 1-100,100 表示免费
+	CouponTypePercent CouponType = 0
+	// CouponTypeFixed 固定金额减免,Value 为减免金额，减至 0 时免费
+	CouponTypeFixed CouponType = 1
+)
+
+var (
+	// maxCouponPercent 百分比优惠码的最大减免比例
+	maxCouponPercent = decimal.NewFromInt(100)
+	// maxCouponAmount 固定金额优惠码的上限，与金额列 decimal(10,2) 一致
+	maxCouponAmount = decimal.RequireFromString("99999999.99")
+)
+
+// Coupon 创建者发放的优惠码,ProjectID 为空时适用于该创建者的全部付费项目。
+// UsedCount 在订单创建(或免费领取)的事务内以 CAS 递增，订单超时失败时归还。
+type Coupon struct {
+	ID        uint64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatorID uint64          `gorm:"not null;uniqueIndex:idx_creator_code,priority:1" json:"creator_id"`
+	Code      string          `gorm:"size:32;not null;uniqueIndex:idx_creator_code,priority:2" json:"code"`
+	ProjectID string          `gorm:"size:64;index" json:"project_id"`
+	Type      CouponType      `gorm:"default:0" json:"type"`
+	Value     decimal.Decimal `gorm:"type:decimal(10,2);not null" json:"value"`
+	MaxUses   int             `gorm:"not null" json:"max_uses"`
+	UsedCount int             `gorm:"default:0;not null" json:"used_count"`
+	ExpireAt  time.Time       `json:"expire_at"`
+	RevokedAt *time.Time      `json:"revoked_at"`
+	CreatedAt time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 自定义表名
+func (Coupon) TableName() string { return "payment_coupons" }
+
+// normalizeCouponCode 优惠码不区分大小写，统一以大写存储与查询
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// validate 校验折扣取值:百分比为 1-100 的整数，固定金额为正且不超过上限，精度不超过币种精度(不低于其最小计价单位)
+func (c *Coupon) validate(currency Currency) error {
+	if !c.Value.IsPositive() {
+		return errors.New(ErrCouponValueInvalid)
+	}
+	switch c.Type {
+	case CouponTypePercent:
+		if c.Value.GreaterThan(maxCouponPercent) || !c.Value.IsInteger() {
+			return errors.New(ErrCouponValueInvalid)
+		}
+	case CouponTypeFixed:
+		if c.Value.LessThan(currency.MinUnit()) || !c.Value.Equal(currency.Round(c.Value)) {
+			return fmt.Errorf(ErrCouponAmountDecimals, currency.OrDefault(), currency.Precision())
+		}
+		if c.Value.GreaterThan(maxCouponAmount) {
+			return errors.New(ErrPriceTooLarge)
+		}
+	default:
+		return errors.New(ErrCouponValueInvalid)
+	}
+	if !c.ExpireAt.After(time.Now()) {
+		return errors.New(ErrCouponExpireInvalid)
+	}
+	return nil
+}
+
+// couponCurrency 返回优惠码适用项目的币种：限定项目时为该项目的币种(同时校验项目归属)，否则为创建者支付配置的结算币种
+func couponCurrency(ctx context.Context, creatorID uint64, projectID string) (Currency, error) {
+	if projectID == "" {
+		cfg, err := GetUserPaymentConfig(ctx, creatorID)
+		if err != nil {
+			return "", err
+		}
+		if cfg == nil {
+			return project.DefaultCurrency, nil
+		}
+		return cfg.currency(), nil
+	}
+	var p project.Project
+	err := db.DB(ctx).Select("id, currency").Where("id = ? AND creator_id = ?", projectID, creatorID).First(&p).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", errors.New(ErrCouponProjectInvalid)
+	} else if err != nil {
+		return "", err
+	}
+	return p.Currency.OrDefault(), nil
+}
+
+// Apply 计算使用优惠码后的金额，按项目币种精度取整，最低为 0
+func (c *Coupon) Apply(price decimal.Decimal, currency Currency) decimal.Decimal {
+	var amount decimal.Decimal
+	switch c.Type {
+	case CouponTypePercent:
+		amount = currency.Round(price.Mul(maxCouponPercent.Sub(c.Value)).Div(maxCouponPercent))
+	case CouponTypeFixed:
+		amount = currency.Round(price.Sub(c.Value))
+	default:
+		return price
+	}
+	if amount.IsNegative() {
+		return decimal.Zero
+	}
+	return amount
+}
+
+// LoadCoupon 查找适用于项目的有效优惠码
+func LoadCoupon(ctx context.Context, p *project.Project, code string) (*Coupon, error) {
+	code = normalizeCouponCode(code)
+	if code == "" {
+		return nil, nil
+	}
+	coupon := &Coupon{}
+	err := db.DB(ctx).
+		Where("creator_id = ? AND code = ? AND (project_id = '' OR project_id = ?)", p.CreatorID, code, p.ID).
+		Where("revoked_at IS NULL AND expire_at > ? AND used_count < max_uses", time.Now()).
+		First(coupon).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New(ErrCouponInvalid)
+	} else if err != nil {
+		return nil, err
+	}
+	return coupon, nil
+}
+
+// consumeCoupon 在下单事务中以 CAS 占用一次使用次数，事务回滚时一并撤销
+func consumeCoupon(tx *gorm.DB, couponID uint64) error {
+	result := tx.Model(&Coupon{}).
+		Where("id = ? AND revoked_at IS NULL AND expire_at > ? AND used_count < max_uses", couponID, time.Now()).
+		UpdateColumn("used_count", gorm.Expr("used_count + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(ErrCouponInvalid)
+	}
+	return nil
+}
+
+// releaseCoupon 订单失败时归还占用的使用次数
+func releaseCoupon(tx *gorm.DB, couponID *uint64) error {
+	if couponID == nil {
+		return nil
+	}
+	return tx.Model(&Coupon{}).
+		Where("id = ? AND used_count > 0", *couponID).
+		UpdateColumn("used_count", gorm.Expr("used_count - 1")).Error
+}
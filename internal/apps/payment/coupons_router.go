@@ -0,0 +1,193 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package payment
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type ListCouponsRequest struct {
+	Current   int    `json:"current" form:"current" binding:"min=1"`
+	Size      int    `json:"size" form:"size" binding:"min=1,max=100"`
+	ProjectID string `json:"project_id" form:"project_id" binding:"max=64"`
+}
+
+type ListCouponsResponseData struct {
+	Total   int64    `json:"total"`
+	Results []Coupon `json:"results"`
+}
+
+type ListCouponsResponse struct {
+	ErrorMsg string                  `json:"error_msg"`
+	Data     ListCouponsResponseData `json:"data"`
+}
+
+// ListCoupons 获取当前用户创建的优惠码
+// @Tags payment
+// @Produce json
+// @Param request query ListCouponsRequest true "request query"
+// @Success 200 {object} ListCouponsResponse
+// @Router /api/v1/payment/coupons [get]
+func ListCoupons(c *gin.Context) {
+	req := &ListCouponsRequest{}
+	if err := c.ShouldBindQuery(req); err != nil {
+		c.JSON(http.StatusBadRequest, ListCouponsResponse{ErrorMsg: err.Error()})
+		return
+	}
+	offset := (req.Current - 1) * req.Size
+
+	query := db.DB(c.Request.Context()).Model(&Coupon{}).Where("creator_id = ?", oauth.GetUserIDFromContext(c))
+	if req.ProjectID != "" {
+		query = query.Where("project_id = ?", req.ProjectID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ListCouponsResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	var coupons []Coupon
+	if err := query.Order("id DESC").Offset(offset).Limit(req.Size).Find(&coupons).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ListCouponsResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListCouponsResponse{Data: ListCouponsResponseData{Total: total, Results: coupons}})
+}
+
+type CreateCouponRequestBody struct {
+	Code      string          `json:"code" binding:"required,min=4,max=32,alphanum"`
+	ProjectID string          `json:"project_id" binding:"max=64"`
+	Type      CouponType      `json:"type" binding:"oneof=0 1"`
+	Value     decimal.Decimal `json:"value"`
+	MaxUses   int             `json:"max_uses" binding:"required,min=1,max=100000"`
+	ExpireAt  time.Time       `json:"expire_at" binding:"required"`
+}
+
+// CreateCoupon 创建优惠码,project_id 为空时适用于当前用户的全部付费项目
+// @Tags payment
+// @Accept json
+// @Produce json
+// @Param coupon body CreateCouponRequestBody true "优惠码信息"
+// @Success 200 {object} Response{data=Coupon}
+// @Router /api/v1/payment/coupons [post]
+func CreateCoupon(c *gin.Context) {
+	var req CreateCouponRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{ErrorMsg: err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := oauth.GetUserIDFromContext(c)
+	coupon := &Coupon{
+		CreatorID: userID,
+		Code:      normalizeCouponCode(req.Code),
+		ProjectID: req.ProjectID,
+		Type:      req.Type,
+		Value:     req.Value,
+		MaxUses:   req.MaxUses,
+		ExpireAt:  req.ExpireAt,
+	}
+
+	// 限定项目时校验项目归属，并按适用项目的币种校验金额精度
+	currency, err := couponCurrency(ctx, userID, coupon.ProjectID)
+	if err != nil {
+		if err.Error() == ErrCouponProjectInvalid {
+			c.JSON(http.StatusBadRequest, Response{ErrorMsg: err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, Response{ErrorMsg: err.Error()})
+		}
+		return
+	}
+	if err := coupon.validate(currency); err != nil {
+		c.JSON(http.StatusBadRequest, Response{ErrorMsg: err.Error()})
+		return
+	}
+
+	// 同一创建者下优惠码唯一
+	var exists int64
+	if err := db.DB(ctx).Model(&Coupon{}).
+		Where("creator_id = ? AND code = ?", userID, coupon.Code).
+		Count(&exists).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, Response{ErrorMsg: err.Error()})
+		return
+	}
+	if exists > 0 {
+		c.JSON(http.StatusBadRequest, Response{ErrorMsg: ErrCouponCodeExists})
+		return
+	}
+
+	if err := db.DB(ctx).Create(coupon).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, Response{ErrorMsg: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, Response{Data: coupon})
+}
+
+// RevokeCoupon 撤销优惠码，已使用该优惠码的订单不受影响
+// @Tags payment
+// @Produce json
+// @Param id path int true "优惠码ID"
+// @Success 200 {object} Response
+// @Router /api/v1/payment/coupons/{id} [delete]
+func RevokeCoupon(c *gin.Context) {
+	couponID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{ErrorMsg: err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	coupon := &Coupon{}
+	if err := db.DB(ctx).
+		Where("id = ? AND creator_id = ?", couponID, oauth.GetUserIDFromContext(c)).
+		First(coupon).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, Response{ErrorMsg: ErrCouponNotFound})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, Response{ErrorMsg: err.Error()})
+		return
+	}
+	if coupon.RevokedAt == nil {
+		now := time.Now()
+		if err := db.DB(ctx).Model(coupon).Update("revoked_at", &now).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, Response{ErrorMsg: err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, Response{})
+}
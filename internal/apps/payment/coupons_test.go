@@ -0,0 +1,117 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package payment
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/linux-do/cdk/internal/apps/project"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/db/dbtest"
+	"github.com/shopspring/decimal"
+)
+
+func TestCouponApply(t *testing.T) {
+	price := decimal.RequireFromString("10")
+	cases := []struct {
+		name     string
+		coupon   Coupon
+		currency Currency
+		want     string
+	}{
+		{"percent", Coupon{Type: CouponTypePercent, Value: decimal.NewFromInt(15)}, project.CurrencyLDC, "8.5"},
+		{"full percent", Coupon{Type: CouponTypePercent, Value: decimal.NewFromInt(100)}, project.CurrencyLDC, "0"},
+		{"fixed", Coupon{Type: CouponTypeFixed, Value: decimal.RequireFromString("2.5")}, project.CurrencyLDC, "7.5"},
+		{"fixed over price", Coupon{Type: CouponTypeFixed, Value: decimal.RequireFromString("20")}, project.CurrencyLDC, "0"},
+		{"credit percent", Coupon{Type: CouponTypePercent, Value: decimal.NewFromInt(15)}, project.CurrencyCredit, "9"},
+		{"credit fixed", Coupon{Type: CouponTypeFixed, Value: decimal.RequireFromString("2.5")}, project.CurrencyCredit, "8"},
+	}
+	for _, c := range cases {
+		if got := c.coupon.Apply(price, c.currency); !got.Equal(decimal.RequireFromString(c.want)) {
+			t.Fatalf("%s: want %s, got %s", c.name, c.want, got)
+		}
+	}
+}
+
+func TestCouponValidate(t *testing.T) {
+	expireAt := time.Now().Add(time.Hour)
+	cases := []struct {
+		name     string
+		coupon   Coupon
+		currency Currency
+		valid    bool
+	}{
+		{"full percent", Coupon{Type: CouponTypePercent, Value: decimal.NewFromInt(100)}, project.CurrencyLDC, true},
+		{"credit percent", Coupon{Type: CouponTypePercent, Value: decimal.NewFromInt(15)}, project.CurrencyCredit, true},
+		{"min fixed", Coupon{Type: CouponTypeFixed, Value: decimal.RequireFromString("0.01")}, project.CurrencyLDC, true},
+		{"credit fixed", Coupon{Type: CouponTypeFixed, Value: decimal.NewFromInt(1)}, project.CurrencyCredit, true},
+		{"percent over 100", Coupon{Type: CouponTypePercent, Value: decimal.NewFromInt(101)}, project.CurrencyLDC, false},
+		{"fractional percent", Coupon{Type: CouponTypePercent, Value: decimal.RequireFromString("12.5")}, project.CurrencyLDC, false},
+		{"fixed too precise", Coupon{Type: CouponTypeFixed, Value: decimal.RequireFromString("1.234")}, project.CurrencyLDC, false},
+		{"credit fixed below unit", Coupon{Type: CouponTypeFixed, Value: decimal.RequireFromString("0.5")}, project.CurrencyCredit, false},
+		{"credit fixed fractional", Coupon{Type: CouponTypeFixed, Value: decimal.RequireFromString("1.5")}, project.CurrencyCredit, false},
+		{"zero fixed", Coupon{Type: CouponTypeFixed, Value: decimal.Zero}, project.CurrencyLDC, false},
+	}
+	for _, c := range cases {
+		c.coupon.ExpireAt = expireAt
+		if err := c.coupon.validate(c.currency); (err == nil) != c.valid {
+			t.Fatalf("%s: want valid=%v, got %v", c.name, c.valid, err)
+		}
+	}
+
+	expired := Coupon{Type: CouponTypeFixed, Value: decimal.NewFromInt(1), ExpireAt: time.Now().Add(-time.Hour)}
+	if err := expired.validate(project.CurrencyLDC); err == nil {
+		t.Fatal("want expired coupon rejected")
+	}
+}
+
+func TestCouponCurrency(t *testing.T) {
+	dbtest.Setup(t, &project.Project{}, &UserPaymentConfig{})
+	ctx := context.Background()
+	if err := db.DB(ctx).Create(&project.Project{ID: "credit", CreatorID: testPayeeID, Currency: project.CurrencyCredit}).Error; err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+
+	// 限定项目时使用项目币种
+	if currency, err := couponCurrency(ctx, testPayeeID, "credit"); err != nil || currency != project.CurrencyCredit {
+		t.Fatalf("want project currency, got %s (%v)", currency, err)
+	}
+	if _, err := couponCurrency(ctx, testPayerID, "credit"); err == nil || err.Error() != ErrCouponProjectInvalid {
+		t.Fatalf("want other creator's project rejected, got %v", err)
+	}
+
+	// 不限定项目时使用支付配置的币种
+	if currency, err := couponCurrency(ctx, testPayeeID, ""); err != nil || currency != project.DefaultCurrency {
+		t.Fatalf("want default currency without config, got %s (%v)", currency, err)
+	}
+	if err := db.DB(ctx).Create(&UserPaymentConfig{UserID: testPayeeID, Currency: project.CurrencyCredit, ClientID: "id", ClientSecretEnc: "enc"}).Error; err != nil {
+		t.Fatalf("create config: %v", err)
+	}
+	if currency, err := couponCurrency(ctx, testPayeeID, ""); err != nil || currency != project.CurrencyCredit {
+		t.Fatalf("want config currency, got %s (%v)", currency, err)
+	}
+}
@@ -25,38 +25,28 @@
 package payment
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
 	"crypto/md5"
-	"crypto/rand"
 	"crypto/subtle"
-	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"io"
 	"sort"
 	"strings"
+
+	"github.com/linux-do/cdk/internal/config"
+	"github.com/linux-do/cdk/internal/utils"
 )
 
-// deriveAESKey 从配置的 key 派生出 32 字节 AES-256 密钥。
-// 优先尝试 base64 解码,失败则取原字节;不足 32 字节以 MD5 填充至稳定 32 字节。
+// secretEncPrefix 带密钥版本的 clientSecret 密文前缀，完整格式为 enc:<密钥版本>:<base64 密文>;
+// 无前缀的存量密文以 ConfigEncryptionKey 解密
+const secretEncPrefix = "enc:"
+
+// deriveAESKey 从配置的 key 派生出 32 字节 AES-256 密钥,规则见 utils.DeriveAESKey。
 func deriveAESKey(raw string) ([]byte, error) {
 	if raw == "" {
 		return nil, errors.New(ErrEncryptionKeyMissing)
 	}
-	if b, err := base64.StdEncoding.DecodeString(raw); err == nil && len(b) == 32 {
-		return b, nil
-	}
-	if len(raw) == 32 {
-		return []byte(raw), nil
-	}
-	sum := md5.Sum([]byte(raw))
-	key := make([]byte, 32)
-	copy(key[:16], sum[:])
-	sum2 := md5.Sum(sum[:])
-	copy(key[16:], sum2[:])
-	return key, nil
+	return utils.DeriveAESKey(raw)
 }
 
 // EncryptSecret 使用 AES-256-GCM 加密明文,输出 base64(nonce|ciphertext|tag)。
@@ -65,23 +55,7 @@ func EncryptSecret(plaintext, key string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	block, err := aes.NewCipher(k)
-	if err != nil {
-		return "", err
-	}
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", err
-	}
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return "", err
-	}
-	ct := gcm.Seal(nil, nonce, []byte(plaintext), nil)
-	out := make([]byte, 0, len(nonce)+len(ct))
-	out = append(out, nonce...)
-	out = append(out, ct...)
-	return base64.StdEncoding.EncodeToString(out), nil
+	return utils.EncryptAESGCM(plaintext, k)
 }
 
 // DecryptSecret 解密 EncryptSecret 的输出。
@@ -90,28 +64,59 @@ func DecryptSecret(encoded, key string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	raw, err := base64.StdEncoding.DecodeString(encoded)
+	return utils.DecryptAESGCM(encoded, k)
+}
+
+// secretKey 按版本号加载密钥环中的密钥，要求恰好 32 字节，不做填充派生
+func secretKey(version string) ([]byte, error) {
+	raw, ok := config.Config.Payment.ConfigEncryptionKeys[version]
+	if !ok || raw == "" || strings.Contains(version, ":") {
+		return nil, fmt.Errorf(ErrSecretKeyMissing, version)
+	}
+	key, err := utils.ParseAESKey(raw)
 	if err != nil {
-		return "", fmt.Errorf("decode secret: %w", err)
+		return nil, fmt.Errorf(ErrSecretKeyInvalid, version)
+	}
+	return key, nil
+}
+
+// encryptClientSecret 使用当前版本密钥加密 clientSecret,未配置版本时沿用 ConfigEncryptionKey 且不带前缀
+func encryptClientSecret(plain string) (string, error) {
+	version := config.Config.Payment.ConfigEncryptionKeyVersion
+	if version == "" {
+		return EncryptSecret(plain, config.Config.Payment.ConfigEncryptionKey)
 	}
-	block, err := aes.NewCipher(k)
+	key, err := secretKey(version)
 	if err != nil {
 		return "", err
 	}
-	gcm, err := cipher.NewGCM(block)
+	ct, err := utils.EncryptAESGCM(plain, key)
 	if err != nil {
 		return "", err
 	}
-	ns := gcm.NonceSize()
-	if len(raw) < ns+gcm.Overhead() {
-		return "", errors.New("ciphertext too short")
+	return secretEncPrefix + version + ":" + ct, nil
+}
+
+// decryptClientSecret 按密文携带的密钥版本解密，无前缀时视为以 ConfigEncryptionKey 加密的存量密文
+func decryptClientSecret(stored string) (string, error) {
+	if !strings.HasPrefix(stored, secretEncPrefix) {
+		return DecryptSecret(stored, config.Config.Payment.ConfigEncryptionKey)
+	}
+	version, ct, ok := strings.Cut(strings.TrimPrefix(stored, secretEncPrefix), ":")
+	if !ok {
+		return "", errors.New(ErrSecretCorrupted)
 	}
-	nonce, ct := raw[:ns], raw[ns:]
-	pt, err := gcm.Open(nil, nonce, ct, nil)
+	key, err := secretKey(version)
 	if err != nil {
 		return "", err
 	}
-	return string(pt), nil
+	return utils.DecryptAESGCM(ct, key)
+}
+
+// isCurrentSecretKey 密文是否已使用当前版本密钥加密
+func isCurrentSecretKey(stored string) bool {
+	version := config.Config.Payment.ConfigEncryptionKeyVersion
+	return version != "" && strings.HasPrefix(stored, secretEncPrefix+version+":")
 }
 
 // BuildSign 按易支付/CodePay/VPay 兼容协议生成 MD5 签名(小写十六进制)。
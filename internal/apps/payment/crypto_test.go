@@ -27,6 +27,8 @@ package payment
 import (
 	"strings"
 	"testing"
+
+	"github.com/linux-do/cdk/internal/config"
 )
 
 func TestBuildSignOrdering(t *testing.T) {
@@ -124,3 +126,45 @@ func TestEncryptNonceRandomness(t *testing.T) {
 		t.Fatal("two encryptions of the same plaintext must differ (random nonce)")
 	}
 }
+
+func TestClientSecretKeyring(t *testing.T) {
+	original := config.Config.Payment
+	defer func() { config.Config.Payment = original }()
+
+	legacyKey := "0123456789abcdef0123456789abcdef"
+	config.Config.Payment.ConfigEncryptionKey = legacyKey
+	config.Config.Payment.ConfigEncryptionKeyVersion = ""
+	config.Config.Payment.ConfigEncryptionKeys = map[string]string{
+		"k1":   "abcdefghijklmnopqrstuvwxyz012345",
+		"weak": "short",
+	}
+
+	// 未配置版本时沿用旧密钥且不带前缀
+	legacy, err := encryptClientSecret("secret")
+	if err != nil || strings.HasPrefix(legacy, secretEncPrefix) {
+		t.Fatalf("want legacy ciphertext, got %q err=%v", legacy, err)
+	}
+
+	config.Config.Payment.ConfigEncryptionKeyVersion = "k1"
+	if isCurrentSecretKey(legacy) {
+		t.Fatal("legacy ciphertext should need re-encryption")
+	}
+	rotated, err := encryptClientSecret("secret")
+	if err != nil || !strings.HasPrefix(rotated, "enc:k1:") || !isCurrentSecretKey(rotated) {
+		t.Fatalf("want k1 ciphertext, got %q err=%v", rotated, err)
+	}
+	for _, stored := range []string{legacy, rotated} {
+		if got, err := decryptClientSecret(stored); err != nil || got != "secret" {
+			t.Fatalf("decrypt %q: got %q err=%v", stored, got, err)
+		}
+	}
+
+	// 密钥环中的密钥不做填充派生
+	config.Config.Payment.ConfigEncryptionKeyVersion = "weak"
+	if _, err := encryptClientSecret("secret"); err == nil {
+		t.Fatal("weak keyring key should be rejected")
+	}
+	if _, err := decryptClientSecret("enc:missing:abc"); err == nil {
+		t.Fatal("unknown key version should be rejected")
+	}
+}
@@ -30,46 +30,128 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/linux-do/cdk/internal/apps/project"
 	"github.com/linux-do/cdk/internal/config"
 	"github.com/linux-do/cdk/internal/utils"
 )
 
-// submitURL 构造 /epay/pay/submit.php 的完整 GET 跳转 URL,由浏览器直接访问。
+// epayProvider 易支付兼容协议的实现
+type epayProvider struct{}
+
+func (epayProvider) Type() ProviderType { return ProviderTypeEpay }
+
+// Currencies 易支付以两位小数金额结算
+func (epayProvider) Currencies() []Currency {
+	return []Currency{project.CurrencyLDC, project.CurrencyCNY}
+}
+
+// epayEndpoint 拼接易支付网关地址
+func epayEndpoint(path string) string {
+	return strings.TrimRight(config.Config.Payment.ApiUrl, "/") + path
+}
+
+// CreateOrder 构造 /epay/pay/submit.php 的完整 GET 跳转 URL,由浏览器直接访问。
 // 不在后端跟随 302,以确保 credit.linux.do/paying 的付款会话对用户浏览器可见。
-func submitURL(clientID, secret, name, money, outTradeNo, notifyURL, returnURL string) string {
+func (epayProvider) CreateOrder(_ context.Context, merchant Merchant, req CreateOrderRequest) (string, error) {
 	params := map[string]string{
-		"pid":          clientID,
+		"pid":          merchant.ClientID,
 		"type":         "epay",
-		"name":         name,
-		"money":        money,
-		"out_trade_no": outTradeNo,
-		"notify_url":   notifyURL,
-		"return_url":   returnURL,
+		"name":         req.Name,
+		"money":        req.Money,
+		"out_trade_no": req.OutTradeNo,
+		"notify_url":   req.NotifyURL,
+		"return_url":   req.ReturnURL,
 		"sign_type":    "MD5",
 	}
-	params["sign"] = BuildSign(params, secret)
+	params["sign"] = BuildSign(params, merchant.ClientSecret)
 	values := url.Values{}
 	for k, v := range params {
 		values.Set(k, v)
 	}
-	return strings.TrimRight(config.Config.Payment.ApiUrl, "/") + "/pay/submit.php?" + values.Encode()
+	return epayEndpoint("/pay/submit.php?") + values.Encode(), nil
 }
 
-// callbackNotifyURL 返回异步通知地址,用于下单参数与前端展示。
-func callbackNotifyURL() string {
-	return strings.TrimRight(config.Config.Payment.NotifyBaseURL, "/") + "/api/v1/payment/notify"
+func (epayProvider) NotifyOutTradeNo(req *NotifyRequest) (string, error) {
+	outTradeNo := req.Query.Get("out_trade_no")
+	if outTradeNo == "" {
+		return "", errNotifyMissingOutTradeNo
+	}
+	return outTradeNo, nil
 }
 
-// callbackReturnURL 返回同步回跳地址。
-func callbackReturnURL(projectID string) string {
-	baseURL := strings.TrimRight(config.Config.Payment.RedirectBaseURL, "/")
-	if projectID == "" {
-		return baseURL + "/received"
+// VerifyNotify 校验 GET 回调的 MD5 签名
+func (epayProvider) VerifyNotify(_ context.Context, merchant Merchant, req *NotifyRequest) (*NotifyResult, error) {
+	q := extractQueryMap(req.Query)
+	if !VerifySign(q, merchant.ClientSecret) {
+		return nil, errors.New("sign mismatch")
 	}
-	return baseURL + "/receive/" + projectID
+	return &NotifyResult{
+		OutTradeNo: q["out_trade_no"],
+		TradeNo:    q["trade_no"],
+		ClientID:   q["pid"],
+		Money:      q["money"],
+		Paid:       q["trade_status"] == "TRADE_SUCCESS",
+	}, nil
+}
+
+// NotifyNonce 易支付回调签名随内容唯一，作为重放标识;携带 timestamp(秒级)参数时一并校验
+func (epayProvider) NotifyNonce(req *NotifyRequest) (string, time.Time) {
+	var timestamp time.Time
+	if ts, err := strconv.ParseInt(req.Query.Get("timestamp"), 10, 64); err == nil && ts > 0 {
+		timestamp = time.Unix(ts, 0)
+	}
+	return req.Query.Get("sign"), timestamp
+}
+
+// NotifyAck 易支付要求回调返回纯文本 "success" / "fail"
+func (epayProvider) NotifyAck(ok bool) (int, string) {
+	if ok {
+		return http.StatusOK, "success"
+	}
+	return http.StatusOK, "fail"
+}
+
+// epayOrderResponse 易支付订单查询接口响应,status=1 表示已支付
+type epayOrderResponse struct {
+	Code       int    `json:"code"`
+	Msg        string `json:"msg"`
+	TradeNo    string `json:"trade_no"`
+	OutTradeNo string `json:"out_trade_no"`
+	Money      string `json:"money"`
+	Status     int    `json:"status"`
+}
+
+// QueryOrder 调用易支付兼容 /api.php?act=order 查询订单
+func (epayProvider) QueryOrder(ctx context.Context, merchant Merchant, outTradeNo string) (*OrderQueryResult, error) {
+	values := url.Values{}
+	values.Set("act", "order")
+	values.Set("pid", merchant.ClientID)
+	values.Set("key", merchant.ClientSecret)
+	values.Set("out_trade_no", outTradeNo)
+
+	var or epayOrderResponse
+	if err := epayAPI(ctx, http.MethodGet, epayEndpoint("/api.php?")+values.Encode(), nil, &or); err != nil {
+		return nil, err
+	}
+	if or.Code != 1 {
+		// 订单在用户打开 submit.php 后才会在网关创建，放弃支付的订单查询结果为"订单不存在"
+		if strings.Contains(or.Msg, "不存在") {
+			return nil, ErrGatewayOrderNotFoundSentinel
+		}
+		return nil, fmt.Errorf("query order rejected: %s", or.Msg)
+	}
+	return &OrderQueryResult{
+		OutTradeNo: or.OutTradeNo,
+		TradeNo:    or.TradeNo,
+		Money:      or.Money,
+		Paid:       or.Status == 1,
+	}, nil
 }
 
 // refundResponse 易支付退款接口响应
@@ -78,27 +160,57 @@ type refundResponse struct {
 	Msg  string `json:"msg"`
 }
 
-// doEpayRefund 调用易支付兼容 /api.php 完成全额退款。
+// Refund 调用易支付兼容 /api.php 完成全额退款。
 // 参考文档:pid+key+trade_no+money 作为 form 参数,返回 {"code":1,"msg":"退款成功"}。
 // 不需要 sign,凭 key 直接鉴权。
-func doEpayRefund(ctx context.Context, clientID, clientSecret, tradeNo, money string) error {
+func (epayProvider) Refund(ctx context.Context, merchant Merchant, req RefundRequest) error {
 	form := url.Values{}
 	form.Set("act", "refund")
-	form.Set("pid", clientID)
-	form.Set("key", clientSecret)
-	form.Set("trade_no", tradeNo)
-	form.Set("money", money)
-
-	endpoint := strings.TrimRight(config.Config.Payment.ApiUrl, "/") + "/api.php"
-
-	resp, err := utils.Request(
-		ctx,
-		"POST",
-		endpoint,
-		strings.NewReader(form.Encode()),
-		map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
-		nil,
-	)
+	form.Set("pid", merchant.ClientID)
+	form.Set("key", merchant.ClientSecret)
+	form.Set("trade_no", req.TradeNo)
+	form.Set("money", req.Money)
+
+	var rr refundResponse
+	if err := epayAPI(ctx, http.MethodPost, epayEndpoint("/api.php"), strings.NewReader(form.Encode()), &rr); err != nil {
+		return err
+	}
+	if rr.Code != 1 {
+		return fmt.Errorf("refund rejected: %s", rr.Msg)
+	}
+	return nil
+}
+
+// merchantResponse 易支付商户信息查询接口响应
+type merchantResponse struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+// VerifyMerchant 调用易支付兼容 /api.php?act=query 查询商户信息，以 pid+key 鉴权，code=1 表示凭据有效
+func (epayProvider) VerifyMerchant(ctx context.Context, merchant Merchant) error {
+	values := url.Values{}
+	values.Set("act", "query")
+	values.Set("pid", merchant.ClientID)
+	values.Set("key", merchant.ClientSecret)
+
+	var mr merchantResponse
+	if err := epayAPI(ctx, http.MethodGet, epayEndpoint("/api.php?")+values.Encode(), nil, &mr); err != nil {
+		return err
+	}
+	if mr.Code != 1 {
+		return fmt.Errorf("query merchant rejected: %s", mr.Msg)
+	}
+	return nil
+}
+
+// epayAPI 调用易支付 /api.php 并解析 JSON 响应
+func epayAPI(ctx context.Context, method, endpoint string, form io.Reader, out interface{}) error {
+	var headers map[string]string
+	if form != nil {
+		headers = map[string]string{"Content-Type": "application/x-www-form-urlencoded"}
+	}
+	resp, err := utils.Request(ctx, method, endpoint, form, headers, nil)
 	if err != nil {
 		return err
 	}
@@ -108,16 +220,30 @@ func doEpayRefund(ctx context.Context, clientID, clientSecret, tradeNo, money st
 	if err != nil {
 		return err
 	}
-	var rr refundResponse
-	if err := json.Unmarshal(body, &rr); err != nil {
-		return fmt.Errorf("parse refund response: %w (raw=%s)", err, string(body))
-	}
-	if rr.Code != 1 {
-		return fmt.Errorf("refund rejected: %s", rr.Msg)
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("parse epay response: %w (raw=%s)", err, string(body))
 	}
 	return nil
 }
 
+// callbackNotifyURL 返回异步通知地址,用于下单参数与前端展示;易支付沿用原地址，其余渠道按类型区分。
+func callbackNotifyURL(providerType ProviderType) string {
+	notifyURL := strings.TrimRight(config.Config.Payment.NotifyBaseURL, "/") + "/api/v1/payment/notify"
+	if providerType == "" || providerType == ProviderTypeEpay {
+		return notifyURL
+	}
+	return notifyURL + "/" + string(providerType)
+}
+
+// callbackReturnURL 返回同步回跳地址。
+func callbackReturnURL(projectID string) string {
+	baseURL := strings.TrimRight(config.Config.Payment.RedirectBaseURL, "/")
+	if projectID == "" {
+		return baseURL + "/received"
+	}
+	return baseURL + "/receive/" + projectID
+}
+
 // extractQueryMap 把 gin 的 query 拉平成 map[string]string,便于签名校验
 func extractQueryMap(values url.Values) map[string]string {
 	m := make(map[string]string, len(values))
@@ -129,8 +255,12 @@ func extractQueryMap(values url.Values) map[string]string {
 	return m
 }
 
-// validateEncryptionKeyConfigured 检查加密密钥是否配置
+// validateEncryptionKeyConfigured 检查加密密钥是否配置，配置了密钥版本时要求该版本密钥有效
 func validateEncryptionKeyConfigured() error {
+	if version := config.Config.Payment.ConfigEncryptionKeyVersion; version != "" {
+		_, err := secretKey(version)
+		return err
+	}
 	if config.Config.Payment.ConfigEncryptionKey == "" {
 		return errors.New(ErrEncryptionKeyMissing)
 	}
@@ -34,8 +34,31 @@ const (
 	ErrEncryptionKeyMissing     = "服务端未配置支付密钥加密密钥"
 	ErrInvalidClientCredentials = "clientID 与 clientSecret 不能为空"
 	ErrOrderNotFound            = "订单不存在"
+	ErrGatewayOrderNotFound     = "支付渠道中不存在该订单"
 	ErrOrderExpired             = "订单已过期"
 	ErrCannotDeleteHasActive    = "存在未结束的付费项目,无法删除支付配置"
 	ErrInvalidPriceDecimals     = "金额最多保留 2 位小数"
 	ErrPriceTooLarge            = "金额超出允许范围"
+	ErrProviderUnsupported      = "不支持的支付渠道: %s"
+	ErrDiscrepancyNotFound      = "对账差异不存在或已处理"
+	ErrMerchantChanged          = "收款方支付凭据已变更,无法处理该订单"
+	ErrRefundNotAllowed         = "仅已完成的订单可以退款"
+	ErrRefundPending            = "退款已提交，支付渠道暂未确认，系统将自动重试"
+	ErrRefundRetryNotAllowed    = "仅自动退款失败的订单可以重新退款"
+	ErrCouponInvalid            = "优惠码无效、已过期或已用完"
+	ErrCouponValueInvalid       = "百分比优惠需为 1 到 100 的整数，固定金额优惠需大于 0"
+	ErrCouponAmountDecimals     = "%s 币种的固定金额优惠最多保留 %d 位小数"
+	ErrCouponExpireInvalid      = "优惠码过期时间必须晚于当前时间"
+	ErrCouponCodeExists         = "优惠码已存在"
+	ErrCouponNotFound           = "优惠码不存在"
+	ErrCouponProjectInvalid     = "仅能为自己的项目创建优惠码"
+	ErrNotifyLogNotFound        = "回调记录不存在"
+	ErrMerchantVerifyFailed     = "支付凭据校验失败，请检查 clientID 与 clientSecret"
+	ErrSecretKeyMissing         = "未配置版本为 %s 的支付密钥"
+	ErrSecretKeyInvalid         = "版本为 %s 的支付密钥必须恰好为 32 字节"
+	ErrSecretCorrupted          = "支付凭据密文格式错误"
+	ErrSecretKeyVersionMissing  = "未配置 config_encryption_key_version,无法轮换支付密钥"
+	ErrCurrencyUnsupported      = "当前支付渠道不支持该币种"
+	ErrCurrencyMismatch         = "项目币种 %s 与收款方支付配置的币种 %s 不一致,无法发起支付"
+	ErrCurrencyChangeHasActive  = "存在未结束的付费项目,无法变更支付配置的币种"
 )
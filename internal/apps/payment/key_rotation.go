@@ -0,0 +1,95 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package payment
+
+import (
+	"context"
+	"errors"
+
+	"github.com/linux-do/cdk/internal/config"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/logger"
+	"gorm.io/gorm"
+)
+
+// ReencryptClientSecrets 按 user_id 顺序分批读取商户凭据，以当前版本密钥重新加密未使用该版本的密文。
+// 更新以原密文为条件，期间用户重新保存凭据时跳过该行;返回重新加密与无需处理的行数。
+func ReencryptClientSecrets(ctx context.Context, batchSize int) (rotated, skipped int, err error) {
+	if config.Config.Payment.ConfigEncryptionKeyVersion == "" {
+		return 0, 0, errors.New(ErrSecretKeyVersionMissing)
+	}
+	if err := validateEncryptionKeyConfigured(); err != nil {
+		return 0, 0, err
+	}
+
+	var lastUserID uint64
+	for {
+		var configs []UserPaymentConfig
+		if err := db.DB(ctx).
+			Select("user_id, client_secret_enc").
+			Where("user_id > ?", lastUserID).
+			Order("user_id ASC").
+			Limit(batchSize).
+			Find(&configs).Error; err != nil {
+			return rotated, skipped, err
+		}
+		if len(configs) <= 0 {
+			return rotated, skipped, nil
+		}
+		lastUserID = configs[len(configs)-1].UserID
+
+		if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+			for _, cfg := range configs {
+				if isCurrentSecretKey(cfg.ClientSecretEnc) {
+					skipped++
+					continue
+				}
+				plain, err := decryptClientSecret(cfg.ClientSecretEnc)
+				if err != nil {
+					return err
+				}
+				enc, err := encryptClientSecret(plain)
+				if err != nil {
+					return err
+				}
+				result := tx.Model(&UserPaymentConfig{}).
+					Where("user_id = ? AND client_secret_enc = ?", cfg.UserID, cfg.ClientSecretEnc).
+					UpdateColumn("client_secret_enc", enc)
+				if result.Error != nil {
+					return result.Error
+				}
+				if result.RowsAffected == 0 {
+					skipped++
+					continue
+				}
+				rotated++
+			}
+			return nil
+		}); err != nil {
+			return rotated, skipped, err
+		}
+		logger.InfoF(ctx, "payment key rotation: processed up to user %d, rotated %d, skipped %d", lastUserID, rotated, skipped)
+	}
+}
@@ -27,59 +27,131 @@ package payment
 import (
 	"time"
 
+	"github.com/linux-do/cdk/internal/apps/project"
+	"github.com/linux-do/cdk/internal/apps/webhook"
 	"github.com/shopspring/decimal"
 )
 
+// Currency 计价币种，与项目共用同一套定义及精度
+type Currency = project.Currency
+
 // OrderStatus 订单状态机
 //
 //	PENDING(0)   -> PAID(1) -> COMPLETED(2)           // 正常路径
 //	                        -> REFUNDING(3) -> REFUNDED(4)  // 发放失败
 //	PENDING      -> FAILED(5)                         // 未付款超时 / 创建失败
+//	COMPLETED    -> REFUNDING(3) -> REFUNDED(4)       // 收款方或管理员手动退款
+//	REFUNDING    -> REFUND_FAILED(6) -> REFUNDING(3)  // 对账重试退款耗尽，管理员确认后重新退款
 type OrderStatus int8
 
 const (
-	OrderStatusPending   OrderStatus = 0
-	OrderStatusPaid      OrderStatus = 1
-	OrderStatusCompleted OrderStatus = 2
-	OrderStatusRefunding OrderStatus = 3
-	OrderStatusRefunded  OrderStatus = 4
-	OrderStatusFailed    OrderStatus = 5
+	OrderStatusPending      OrderStatus = 0
+	OrderStatusPaid         OrderStatus = 1
+	OrderStatusCompleted    OrderStatus = 2
+	OrderStatusRefunding    OrderStatus = 3
+	OrderStatusRefunded     OrderStatus = 4
+	OrderStatusFailed       OrderStatus = 5
+	OrderStatusRefundFailed OrderStatus = 6
 )
 
-// UserPaymentConfig 用户的商户凭据(一对一绑定 User)
+// UserPaymentConfig 用户的商户凭据(一对一绑定 User),VerifiedAt 为最近一次向渠道校验凭据成功的时间;
+// Currency 为商户在该渠道的结算币种，创建者的付费项目需使用相同币种。
 type UserPaymentConfig struct {
-	UserID          uint64    `gorm:"primaryKey" json:"user_id"`
-	ClientID        string    `gorm:"size:64;not null" json:"client_id"`
-	ClientSecretEnc string    `gorm:"size:512;not null" json:"-"`
-	SecretLast4     string    `gorm:"size:8" json:"secret_last4"`
-	CreatedAt       time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt       time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	UserID          uint64       `gorm:"primaryKey" json:"user_id"`
+	Provider        ProviderType `gorm:"size:16;default:'epay';not null" json:"provider"`
+	Currency        Currency     `gorm:"size:16;default:'LDC';not null" json:"currency"`
+	ClientID        string       `gorm:"size:64;not null" json:"client_id"`
+	ClientSecretEnc string       `gorm:"size:512;not null" json:"-"`
+	SecretLast4     string       `gorm:"size:8" json:"secret_last4"`
+	VerifiedAt      *time.Time   `json:"verified_at"`
+	CreatedAt       time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time    `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
-// PaymentOrder 支付订单(一次付费领取 = 一个订单)
+// PaymentOrder 支付订单(一次付费领取 = 一个订单),Provider 记录下单时的支付渠道，回调与退款均以此为准;
+// 手动退款时 RefundBy/RefundReason 记录发起人与原因,RevokeItem 表示是否收回并作废已发放的 item;
+// CouponID 为下单时使用的优惠码,Amount 为优惠后的实付金额,Currency 为下单时固化的项目币种;
+// RefundAttempts/NextRefundAt 为对账任务重试退款的已失败次数与下次重试时间。
 //
 // 联合索引：
 //   - idx_project_payer_status (project_id, payer_id, status)：查询某用户在某项目的待支付订单
 //   - idx_payer_status         (payer_id, status)：按用户快速查询待支付订单
 //   - idx_status_expire        (status, expire_at)：清理任务扫描超时 PENDING 订单
 type PaymentOrder struct {
-	ID            uint64          `gorm:"primaryKey;autoIncrement" json:"id"`
-	OutTradeNo    string          `gorm:"size:64;uniqueIndex;not null" json:"out_trade_no"`
-	TradeNo       string          `gorm:"size:64;index" json:"trade_no"`
-	ProjectID     string          `gorm:"size:64;not null;index:idx_project_payer_status,priority:1" json:"project_id"`
-	ItemID        uint64          `gorm:"index;not null" json:"item_id"`
-	PayerID       uint64          `gorm:"not null;index:idx_project_payer_status,priority:2;index:idx_payer_status,priority:1" json:"payer_id"`
-	PayeeID       uint64          `gorm:"index;not null" json:"payee_id"`
-	PayeeClientID string          `gorm:"size:64" json:"payee_client_id"`
-	Amount        decimal.Decimal `gorm:"type:decimal(10,2);not null" json:"amount"`
-	Status        OrderStatus     `gorm:"default:0;index:idx_project_payer_status,priority:3;index:idx_payer_status,priority:2;index:idx_status_expire,priority:1" json:"status"`
-	PaidAt        *time.Time      `json:"paid_at"`
-	RefundedAt    *time.Time      `json:"refunded_at"`
-	FailReason    string          `gorm:"size:255" json:"fail_reason"`
-	ExpireAt      time.Time       `gorm:"index:idx_status_expire,priority:2" json:"expire_at"`
-	ClientIP      string          `gorm:"size:64" json:"client_ip"`
-	CreatedAt     time.Time       `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt     time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+	ID             uint64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	OutTradeNo     string          `gorm:"size:64;uniqueIndex;not null" json:"out_trade_no"`
+	TradeNo        string          `gorm:"size:64;index" json:"trade_no"`
+	ProjectID      string          `gorm:"size:64;not null;index:idx_project_payer_status,priority:1" json:"project_id"`
+	ItemID         uint64          `gorm:"index;not null" json:"item_id"`
+	PayerID        uint64          `gorm:"not null;index:idx_project_payer_status,priority:2;index:idx_payer_status,priority:1" json:"payer_id"`
+	PayeeID        uint64          `gorm:"index;not null" json:"payee_id"`
+	PayeeClientID  string          `gorm:"size:64" json:"payee_client_id"`
+	Provider       ProviderType    `gorm:"size:16" json:"provider"`
+	Amount         decimal.Decimal `gorm:"type:decimal(10,2);not null" json:"amount"`
+	Currency       Currency        `gorm:"size:16;default:'LDC';not null" json:"currency"`
+	Status         OrderStatus     `gorm:"default:0;index:idx_project_payer_status,priority:3;index:idx_payer_status,priority:2;index:idx_status_expire,priority:1" json:"status"`
+	PaidAt         *time.Time      `json:"paid_at"`
+	RefundedAt     *time.Time      `json:"refunded_at"`
+	FailReason     string          `gorm:"size:255" json:"fail_reason"`
+	RefundBy       *uint64         `gorm:"index" json:"refund_by"`
+	RefundReason   string          `gorm:"size:255" json:"refund_reason"`
+	RevokeItem     bool            `json:"revoke_item"`
+	CouponID       *uint64         `gorm:"index" json:"coupon_id"`
+	RefundAttempts int             `gorm:"default:0;not null" json:"refund_attempts"`
+	NextRefundAt   *time.Time      `json:"next_refund_at"`
+	ExpireAt       time.Time       `gorm:"index:idx_status_expire,priority:2" json:"expire_at"`
+	ClientIP       string          `gorm:"size:64" json:"client_ip"`
+	CreatedAt      time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// providerType 返回配置的支付渠道，存量配置视为易支付
+func (c *UserPaymentConfig) providerType() ProviderType {
+	if c.Provider == "" {
+		return ProviderTypeEpay
+	}
+	return c.Provider
+}
+
+// currency 返回配置的结算币种，存量配置视为默认币种
+func (c *UserPaymentConfig) currency() Currency {
+	return c.Currency.OrDefault()
+}
+
+// providerType 返回订单的下单渠道，存量订单视为易支付
+func (o *PaymentOrder) providerType() ProviderType {
+	if o.Provider == "" {
+		return ProviderTypeEpay
+	}
+	return o.Provider
+}
+
+// money 按订单币种精度格式化订单金额
+func (o *PaymentOrder) money() string {
+	return o.Currency.Format(o.Amount)
+}
+
+// webhookData 订单事件的 Webhook 内容
+func (o *PaymentOrder) webhookData() webhook.OrderData {
+	return webhook.OrderData{
+		OutTradeNo: o.OutTradeNo,
+		ProjectID:  o.ProjectID,
+		PayerID:    o.PayerID,
+		Amount:     o.money(),
+		Currency:   string(o.Currency),
+		PaidAt:     o.PaidAt,
+		RefundedAt: o.RefundedAt,
+	}
+}
+
+// matchesMerchant 订单是否由商户当前的渠道与 ClientID 创建，不一致时无法以当前凭据重建支付链接
+func (o *PaymentOrder) matchesMerchant(cfg *UserPaymentConfig) bool {
+	return o.PayeeClientID == cfg.ClientID && o.providerType() == cfg.providerType()
+}
+
+// refundRequest 构造全额退款参数
+func (o *PaymentOrder) refundRequest() RefundRequest {
+	return RefundRequest{OutTradeNo: o.OutTradeNo, TradeNo: o.TradeNo, Money: o.money()}
 }
 
 // TableName 自定义表名
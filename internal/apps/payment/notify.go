@@ -0,0 +1,229 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/linux-do/cdk/internal/config"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/logger"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// errNotifyReplayed 同一回调已在重放窗口内成功处理
+var errNotifyReplayed = errors.New("notify replayed")
+
+// errNotifyInFlight 同一回调正在处理中
+var errNotifyInFlight = errors.New("notify in flight")
+
+// PaymentNotifyLog 支付回调审计记录，保存原始 query 与 body 以便排查和重放。
+// Verified 表示是否通过验签,Success 为向渠道确认的结果,Reason 为 HandleNotify 返回或被拒绝的原因;
+// 管理员重放产生的记录以 ReplayOf 指向原记录,ReplayBy 为操作人。
+type PaymentNotifyLog struct {
+	ID         uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Provider   ProviderType `gorm:"size:16" json:"provider"`
+	OutTradeNo string       `gorm:"size:64;index" json:"out_trade_no"`
+	RawQuery   string       `gorm:"type:text" json:"raw_query"`
+	Body       string       `gorm:"type:text" json:"body"`
+	SourceIP   string       `gorm:"size:64" json:"source_ip"`
+	Verified   bool         `json:"verified"`
+	Success    bool         `json:"success"`
+	Reason     string       `gorm:"size:255" json:"reason"`
+	ReplayOf   *uint64      `gorm:"index" json:"replay_of"`
+	ReplayBy   *uint64      `json:"replay_by"`
+	CreatedAt  time.Time    `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+// TableName 自定义表名
+func (PaymentNotifyLog) TableName() string { return "payment_notify_logs" }
+
+// newNotifyLog 根据原始回调构造审计记录,rawQuery 为未经解析的原始 query 字符串
+func newNotifyLog(provider PaymentProvider, req *NotifyRequest, rawQuery, sourceIP string) *PaymentNotifyLog {
+	outTradeNo, _ := provider.NotifyOutTradeNo(req)
+	return &PaymentNotifyLog{
+		Provider:   provider.Type(),
+		OutTradeNo: truncateRuneLen(outTradeNo, 64),
+		RawQuery:   rawQuery,
+		Body:       string(req.Body),
+		SourceIP:   sourceIP,
+	}
+}
+
+// notifyIPAllowed 校验回调来源 IP 是否在白名单内，白名单为空时不限制
+func notifyIPAllowed(sourceIP string, allowed []string) bool {
+	if len(allowed) <= 0 {
+		return true
+	}
+	ip := net.ParseIP(sourceIP)
+	if ip == nil {
+		return false
+	}
+	for _, entry := range allowed {
+		entry = strings.TrimSpace(entry)
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if cidr.Contains(ip) {
+				return true
+			}
+		} else if allowedIP := net.ParseIP(entry); allowedIP != nil && allowedIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// notifyNonceKey 回调标识在 Redis 中的 key,值为 notifyNonceProcessing 或 notifyNonceDone
+func notifyNonceKey(providerType ProviderType, nonce string) string {
+	return fmt.Sprintf("payment:notify:nonce:%s:%s", providerType, nonce)
+}
+
+const (
+	notifyNonceProcessing = "processing"
+	notifyNonceDone       = "done"
+)
+
+// checkNotifyReplay 校验回调时间戳，并以 SETNX 原子占用重放标识，返回占用的标识 key;
+// 标识已处理成功时返回 errNotifyReplayed,仍在处理中时返回 errNotifyInFlight。
+// 渠道未实现 NotifyReplayGuard 或未开启重放窗口时不做校验
+func checkNotifyReplay(ctx context.Context, provider PaymentProvider, req *NotifyRequest, now time.Time) (string, error) {
+	window := time.Duration(config.Config.Payment.NotifyReplayWindowSeconds) * time.Second
+	guard, ok := provider.(NotifyReplayGuard)
+	if window <= 0 || !ok {
+		return "", nil
+	}
+	nonce, timestamp := guard.NotifyNonce(req)
+	if !timestamp.IsZero() && (now.Sub(timestamp) > window || timestamp.Sub(now) > window) {
+		return "", errors.New("timestamp out of replay window")
+	}
+	if nonce == "" {
+		return "", nil
+	}
+	key := notifyNonceKey(provider.Type(), nonce)
+	claimed, err := db.Redis.SetNX(ctx, key, notifyNonceProcessing, window).Result()
+	if err != nil {
+		return "", err
+	}
+	if claimed {
+		return key, nil
+	}
+	state, err := db.Redis.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		// 上一次处理失败刚释放标识，交由渠道重试
+		return "", errNotifyInFlight
+	} else if err != nil {
+		return "", err
+	}
+	if state == notifyNonceDone {
+		return "", errNotifyReplayed
+	}
+	return "", errNotifyInFlight
+}
+
+// finishNotifyReplay 处理成功时将标识标记为已处理并保留整个重放窗口，失败时释放标识以便渠道重试
+func finishNotifyReplay(ctx context.Context, key string, success bool) error {
+	if key == "" {
+		return nil
+	}
+	if !success {
+		return db.Redis.Del(ctx, key).Err()
+	}
+	window := time.Duration(config.Config.Payment.NotifyReplayWindowSeconds) * time.Second
+	return db.Redis.Set(ctx, key, notifyNonceDone, window).Err()
+}
+
+// processNotify 依次执行来源 IP 校验、重放校验与 HandleNotify,并记录审计日志。
+// 窗口内重复到达的已成功回调不再进入状态机，直接向渠道确认成功以免其持续重试;
+// 与处理中的回调并发到达的副本返回失败，由渠道稍后重试。
+func processNotify(ctx context.Context, provider PaymentProvider, req *NotifyRequest, rawQuery, sourceIP string) bool {
+	entry := newNotifyLog(provider, req, rawQuery, sourceIP)
+	defer saveNotifyLog(ctx, entry)
+
+	if !notifyIPAllowed(sourceIP, config.Config.Payment.NotifyAllowedIPs) {
+		entry.Reason = "source ip not allowed"
+		return false
+	}
+	nonceKey, err := checkNotifyReplay(ctx, provider, req, time.Now())
+	if errors.Is(err, errNotifyReplayed) {
+		entry.Success, entry.Reason = true, err.Error()
+		return true
+	} else if err != nil {
+		entry.Reason = truncateRuneLen(err.Error(), 255)
+		return false
+	}
+
+	var reason string
+	entry.Success, entry.Verified, reason = handleNotify(ctx, provider, req)
+	entry.Reason = truncateRuneLen(reason, 255)
+	if err := finishNotifyReplay(ctx, nonceKey, entry.Success); err != nil {
+		logger.ErrorF(ctx, "payment notify: failed to update nonce for order %s: %v", entry.OutTradeNo, err)
+	}
+	return entry.Success
+}
+
+// saveNotifyLog 写入审计日志，失败仅记录错误不影响回调确认
+func saveNotifyLog(ctx context.Context, entry *PaymentNotifyLog) {
+	if err := db.DB(ctx).Create(entry).Error; err != nil {
+		logger.ErrorF(ctx, "payment notify: failed to save notify log for order %s: %v", entry.OutTradeNo, err)
+	}
+}
+
+// ReplayNotify 将已记录的回调重新交由 HandleNotify 处理，跳过来源与重放校验，用于故障恢复;
+// 结果作为新的审计记录保存并返回。
+func ReplayNotify(ctx context.Context, logID, adminID uint64, sourceIP string) (*PaymentNotifyLog, error) {
+	var original PaymentNotifyLog
+	if err := db.DB(ctx).Where("id = ?", logID).First(&original).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(ErrNotifyLogNotFound)
+		}
+		return nil, err
+	}
+	provider, err := GetProvider(original.Provider)
+	if err != nil {
+		return nil, err
+	}
+	query, err := url.ParseQuery(original.RawQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &NotifyRequest{Query: query, Body: []byte(original.Body)}
+	entry := newNotifyLog(provider, req, original.RawQuery, sourceIP)
+	entry.ReplayOf, entry.ReplayBy = &original.ID, &adminID
+	var reason string
+	entry.Success, entry.Verified, reason = handleNotify(ctx, provider, req)
+	entry.Reason = truncateRuneLen(reason, 255)
+	if err := db.DB(ctx).Create(entry).Error; err != nil {
+		return nil, err
+	}
+	logger.InfoF(ctx, "payment notify: admin %d replayed notify log %d for order %s, success=%t", adminID, logID, entry.OutTradeNo, entry.Success)
+	return entry, nil
+}
@@ -0,0 +1,116 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package payment
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/db"
+)
+
+type ListNotifyLogsRequest struct {
+	Current    int    `json:"current" form:"current" binding:"min=1"`
+	Size       int    `json:"size" form:"size" binding:"min=1,max=100"`
+	OutTradeNo string `json:"out_trade_no" form:"out_trade_no" binding:"max=64"`
+	Success    *bool  `json:"success" form:"success"`
+}
+
+type ListNotifyLogsResponseData struct {
+	Total   int64              `json:"total"`
+	Results []PaymentNotifyLog `json:"results"`
+}
+
+type ListNotifyLogsResponse struct {
+	ErrorMsg string                     `json:"error_msg"`
+	Data     ListNotifyLogsResponseData `json:"data"`
+}
+
+// ListNotifyLogs 获取支付回调审计记录
+// @Tags admin
+// @Produce json
+// @Param request query ListNotifyLogsRequest true "request query"
+// @Success 200 {object} ListNotifyLogsResponse
+// @Router /api/v1/admin/payment/notify-logs [get]
+func ListNotifyLogs(c *gin.Context) {
+	req := &ListNotifyLogsRequest{}
+	if err := c.ShouldBindQuery(req); err != nil {
+		c.JSON(http.StatusBadRequest, ListNotifyLogsResponse{ErrorMsg: err.Error()})
+		return
+	}
+	offset := (req.Current - 1) * req.Size
+
+	query := db.DB(c.Request.Context()).Model(&PaymentNotifyLog{})
+	if req.OutTradeNo != "" {
+		query = query.Where("out_trade_no = ?", req.OutTradeNo)
+	}
+	if req.Success != nil {
+		query = query.Where("success = ?", *req.Success)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ListNotifyLogsResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	var logs []PaymentNotifyLog
+	if err := query.Order("id DESC").Offset(offset).Limit(req.Size).Find(&logs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ListNotifyLogsResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListNotifyLogsResponse{
+		Data: ListNotifyLogsResponseData{Total: total, Results: logs},
+	})
+}
+
+// ReplayNotifyLog 将记录的回调重新交由 HandleNotify 处理，返回本次重放的审计记录
+// @Tags admin
+// @Produce json
+// @Param id path int true "回调记录ID"
+// @Success 200 {object} Response{data=PaymentNotifyLog}
+// @Router /api/v1/admin/payment/notify-logs/{id}/replay [post]
+func ReplayNotifyLog(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{ErrorMsg: err.Error()})
+		return
+	}
+
+	entry, err := ReplayNotify(c.Request.Context(), id, oauth.GetUserIDFromContext(c), c.ClientIP())
+	if err != nil {
+		if err.Error() == ErrNotifyLogNotFound {
+			c.JSON(http.StatusNotFound, Response{ErrorMsg: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, Response{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Data: entry})
+}
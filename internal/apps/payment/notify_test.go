@@ -0,0 +1,127 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/linux-do/cdk/internal/config"
+)
+
+func TestNotifyIPAllowed(t *testing.T) {
+	if !notifyIPAllowed("203.0.113.7", nil) {
+		t.Fatalf("want empty allow-list to allow all")
+	}
+	allowed := []string{"198.51.100.10", "203.0.113.0/24"}
+	cases := map[string]bool{
+		"198.51.100.10": true,
+		"203.0.113.7":   true,
+		"198.51.100.11": false,
+		"not-an-ip":     false,
+	}
+	for ip, want := range cases {
+		if got := notifyIPAllowed(ip, allowed); got != want {
+			t.Fatalf("ip %s: want %t, got %t", ip, want, got)
+		}
+	}
+}
+
+func TestCheckNotifyReplayTimestamp(t *testing.T) {
+	original := config.Config.Payment.NotifyReplayWindowSeconds
+	defer func() { config.Config.Payment.NotifyReplayWindowSeconds = original }()
+
+	now := time.Now()
+	req := func(ts time.Time) *NotifyRequest {
+		return &NotifyRequest{Query: url.Values{"timestamp": {strconv.FormatInt(ts.Unix(), 10)}}}
+	}
+
+	// 未开启窗口时不校验
+	config.Config.Payment.NotifyReplayWindowSeconds = 0
+	if _, err := checkNotifyReplay(context.Background(), epayProvider{}, req(now.Add(-time.Hour)), now); err != nil {
+		t.Fatalf("want disabled guard to pass, got %v", err)
+	}
+
+	config.Config.Payment.NotifyReplayWindowSeconds = 300
+	if _, err := checkNotifyReplay(context.Background(), epayProvider{}, req(now.Add(-time.Minute)), now); err != nil {
+		t.Fatalf("want fresh timestamp accepted, got %v", err)
+	}
+	if _, err := checkNotifyReplay(context.Background(), epayProvider{}, req(now.Add(-time.Hour)), now); err == nil {
+		t.Fatalf("want stale timestamp rejected")
+	}
+	if _, err := checkNotifyReplay(context.Background(), epayProvider{}, req(now.Add(time.Hour)), now); err == nil {
+		t.Fatalf("want future timestamp rejected")
+	}
+	// 未实现 NotifyReplayGuard 的渠道不校验
+	if _, err := checkNotifyReplay(context.Background(), &fakeProvider{}, req(now.Add(-time.Hour)), now); err != nil {
+		t.Fatalf("want provider without guard to pass, got %v", err)
+	}
+}
+
+func TestCheckNotifyReplayNonce(t *testing.T) {
+	setupPaymentStore(t)
+	ctx := context.Background()
+	original := config.Config.Payment.NotifyReplayWindowSeconds
+	defer func() { config.Config.Payment.NotifyReplayWindowSeconds = original }()
+	config.Config.Payment.NotifyReplayWindowSeconds = 300
+
+	now := time.Now()
+	req := func(sign string) *NotifyRequest {
+		return &NotifyRequest{Query: url.Values{"sign": {sign}, "timestamp": {strconv.FormatInt(now.Unix(), 10)}}}
+	}
+
+	// 首次到达占用标识，处理完成前的并发副本不确认
+	key, err := checkNotifyReplay(ctx, epayProvider{}, req("s1"), now)
+	if err != nil || key == "" {
+		t.Fatalf("want nonce claimed, got key=%q err=%v", key, err)
+	}
+	if _, err := checkNotifyReplay(ctx, epayProvider{}, req("s1"), now); !errors.Is(err, errNotifyInFlight) {
+		t.Fatalf("want in-flight duplicate rejected, got %v", err)
+	}
+
+	// 处理成功后窗口内的重复回调直接确认
+	if err := finishNotifyReplay(ctx, key, true); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+	if _, err := checkNotifyReplay(ctx, epayProvider{}, req("s1"), now); !errors.Is(err, errNotifyReplayed) {
+		t.Fatalf("want replay detected, got %v", err)
+	}
+
+	// 处理失败释放标识，渠道重试时可再次处理
+	key, err = checkNotifyReplay(ctx, epayProvider{}, req("s2"), now)
+	if err != nil {
+		t.Fatalf("claim s2: %v", err)
+	}
+	if err := finishNotifyReplay(ctx, key, false); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+	if key, err = checkNotifyReplay(ctx, epayProvider{}, req("s2"), now); err != nil || key == "" {
+		t.Fatalf("want released nonce reclaimable, got key=%q err=%v", key, err)
+	}
+}
@@ -0,0 +1,208 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package payment
+
+import (
+	"context"
+	"time"
+
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// OrderRole 查询订单时当前用户的身份
+type OrderRole int8
+
+const (
+	OrderRolePayer OrderRole = iota
+	OrderRolePayee
+)
+
+// paidOrderStatuses 已实际付款的订单状态，用于统计销售额
+var paidOrderStatuses = []OrderStatus{OrderStatusPaid, OrderStatusCompleted, OrderStatusRefunding, OrderStatusRefunded, OrderStatusRefundFailed}
+
+// OrderFilter 订单查询条件，时间范围作用于下单时间
+type OrderFilter struct {
+	Status    *OrderStatus
+	Currency  Currency
+	ProjectID string
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// OrderView 订单列表展示视图,Counterpart 为交易对方的用户名(买家视角为卖家，卖家视角为买家)
+type OrderView struct {
+	OutTradeNo   string          `json:"out_trade_no"`
+	TradeNo      string          `json:"trade_no"`
+	ProjectID    string          `json:"project_id"`
+	ProjectName  string          `json:"project_name"`
+	Counterpart  string          `json:"counterpart"`
+	Amount       decimal.Decimal `json:"amount"`
+	Currency     Currency        `json:"currency"`
+	Status       OrderStatus     `json:"status"`
+	PaidAt       *time.Time      `json:"paid_at"`
+	RefundedAt   *time.Time      `json:"refunded_at"`
+	FailReason   string          `json:"fail_reason"`
+	RefundReason string          `json:"refund_reason"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// userOrdersQuery 构造指定身份下的订单查询
+func userOrdersQuery(ctx context.Context, role OrderRole, userID uint64, filter *OrderFilter) *gorm.DB {
+	ownerColumn, counterpartColumn := "o.payer_id", "o.payee_id"
+	if role == OrderRolePayee {
+		ownerColumn, counterpartColumn = "o.payee_id", "o.payer_id"
+	}
+	query := db.DB(ctx).Table("payment_orders o").
+		Joins("LEFT JOIN projects p ON p.id = o.project_id").
+		Joins("LEFT JOIN users u ON u.id = "+counterpartColumn).
+		Where(ownerColumn+" = ?", userID)
+	if filter.Status != nil {
+		query = query.Where("o.status = ?", *filter.Status)
+	}
+	if filter.Currency != "" {
+		query = query.Where("o.currency = ?", filter.Currency)
+	}
+	if filter.ProjectID != "" {
+		query = query.Where("o.project_id = ?", filter.ProjectID)
+	}
+	if !filter.StartTime.IsZero() {
+		query = query.Where("o.created_at >= ?", filter.StartTime)
+	}
+	if !filter.EndTime.IsZero() {
+		query = query.Where("o.created_at < ?", filter.EndTime)
+	}
+	return query
+}
+
+// ListUserOrders 分页查询用户作为买家或卖家的订单
+func ListUserOrders(ctx context.Context, role OrderRole, userID uint64, filter *OrderFilter, offset, limit int) (int64, []OrderView, error) {
+	var total int64
+	if err := userOrdersQuery(ctx, role, userID, filter).Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+
+	var orders []OrderView
+	if err := userOrdersQuery(ctx, role, userID, filter).
+		Select(`o.out_trade_no, o.trade_no, o.project_id, p.name AS project_name, u.username AS counterpart,
+			o.amount, o.currency, o.status, o.paid_at, o.refunded_at, o.fail_reason, o.refund_reason, o.created_at`).
+		Order("o.id DESC").
+		Offset(offset).
+		Limit(limit).
+		Scan(&orders).Error; err != nil {
+		return 0, nil, err
+	}
+	return total, orders, nil
+}
+
+// ProjectSalesSummary 单个项目在单一币种下的销售汇总,Net = Gross - Refunded
+type ProjectSalesSummary struct {
+	ProjectID     string          `json:"project_id"`
+	ProjectName   string          `json:"project_name"`
+	Currency      Currency        `json:"currency"`
+	PaidCount     int64           `json:"paid_count"`
+	RefundedCount int64           `json:"refunded_count"`
+	Gross         decimal.Decimal `json:"gross"`
+	Refunded      decimal.Decimal `json:"refunded"`
+	Net           decimal.Decimal `json:"net"`
+}
+
+// CurrencySalesTotal 卖家在单一币种下的销售合计,Net = Gross - Refunded
+type CurrencySalesTotal struct {
+	Currency      Currency        `json:"currency"`
+	PaidCount     int64           `json:"paid_count"`
+	RefundedCount int64           `json:"refunded_count"`
+	Gross         decimal.Decimal `json:"gross"`
+	Refunded      decimal.Decimal `json:"refunded"`
+	Net           decimal.Decimal `json:"net"`
+}
+
+// salesAggregateSQL 销售汇总的聚合列，与 ProjectSalesSummary、CurrencySalesTotal 的字段对应
+const salesAggregateSQL = `COUNT(*) AS paid_count,
+			SUM(CASE WHEN o.status = ? THEN 1 ELSE 0 END) AS refunded_count,
+			SUM(o.amount) AS gross,
+			SUM(CASE WHEN o.status = ? THEN o.amount ELSE 0 END) AS refunded`
+
+// salesQuery 构造卖家已付款订单的查询，时间范围作用于付款时间
+func salesQuery(ctx context.Context, payeeID uint64, filter *OrderFilter) *gorm.DB {
+	query := db.DB(ctx).Table("payment_orders o").
+		Joins("LEFT JOIN projects p ON p.id = o.project_id").
+		Where("o.payee_id = ? AND o.status IN ?", payeeID, paidOrderStatuses)
+	if filter.Currency != "" {
+		query = query.Where("o.currency = ?", filter.Currency)
+	}
+	if filter.ProjectID != "" {
+		query = query.Where("o.project_id = ?", filter.ProjectID)
+	}
+	if !filter.StartTime.IsZero() {
+		query = query.Where("o.paid_at >= ?", filter.StartTime)
+	}
+	if !filter.EndTime.IsZero() {
+		query = query.Where("o.paid_at < ?", filter.EndTime)
+	}
+	return query
+}
+
+// SummarizeSales 按项目与币种汇总卖家已付款订单的销售额与退款额，不同币种的金额不做合并
+func SummarizeSales(ctx context.Context, payeeID uint64, filter *OrderFilter, offset, limit int) (int64, []ProjectSalesSummary, error) {
+	var total int64
+	groups := salesQuery(ctx, payeeID, filter).Select("o.project_id, o.currency").Group("o.project_id, o.currency")
+	if err := db.DB(ctx).Table("(?) AS g", groups).Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+
+	var summaries []ProjectSalesSummary
+	if err := salesQuery(ctx, payeeID, filter).
+		Select(`o.project_id, MAX(p.name) AS project_name, o.currency, `+salesAggregateSQL, OrderStatusRefunded, OrderStatusRefunded).
+		Group("o.project_id, o.currency").
+		Order("o.currency ASC").
+		Order("gross DESC").
+		Offset(offset).
+		Limit(limit).
+		Scan(&summaries).Error; err != nil {
+		return 0, nil, err
+	}
+	for i := range summaries {
+		summaries[i].Net = summaries[i].Gross.Sub(summaries[i].Refunded)
+	}
+	return total, summaries, nil
+}
+
+// SummarizeSalesByCurrency 按币种汇总卖家已付款订单的销售合计
+func SummarizeSalesByCurrency(ctx context.Context, payeeID uint64, filter *OrderFilter) ([]CurrencySalesTotal, error) {
+	var totals []CurrencySalesTotal
+	if err := salesQuery(ctx, payeeID, filter).
+		Select(`o.currency, `+salesAggregateSQL, OrderStatusRefunded, OrderStatusRefunded).
+		Group("o.currency").
+		Order("o.currency ASC").
+		Scan(&totals).Error; err != nil {
+		return nil, err
+	}
+	for i := range totals {
+		totals[i].Net = totals[i].Gross.Sub(totals[i].Refunded)
+	}
+	return totals, nil
+}
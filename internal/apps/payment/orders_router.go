@@ -0,0 +1,147 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package payment
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/cdk/internal/apps/oauth"
+)
+
+type ListOrdersRequest struct {
+	Current   int          `json:"current" form:"current" binding:"min=1"`
+	Size      int          `json:"size" form:"size" binding:"min=1,max=100"`
+	Status    *OrderStatus `json:"status" form:"status" binding:"omitempty,oneof=0 1 2 3 4 5 6"`
+	Currency  Currency     `json:"currency" form:"currency" binding:"omitempty,oneof=LDC CNY CREDIT"`
+	ProjectID string       `json:"project_id" form:"project_id" binding:"max=64"`
+	StartTime time.Time    `json:"start_time" form:"start_time"`
+	EndTime   time.Time    `json:"end_time" form:"end_time"`
+}
+
+func (r *ListOrdersRequest) filter() *OrderFilter {
+	return &OrderFilter{Status: r.Status, Currency: r.Currency, ProjectID: r.ProjectID, StartTime: r.StartTime, EndTime: r.EndTime}
+}
+
+type ListOrdersResponseData struct {
+	Total   int64       `json:"total"`
+	Results []OrderView `json:"results"`
+}
+
+type ListOrdersResponse struct {
+	ErrorMsg string                 `json:"error_msg"`
+	Data     ListOrdersResponseData `json:"data"`
+}
+
+// listOrders 按身份分页返回当前用户的订单
+func listOrders(c *gin.Context, role OrderRole) {
+	req := &ListOrdersRequest{}
+	if err := c.ShouldBindQuery(req); err != nil {
+		c.JSON(http.StatusBadRequest, ListOrdersResponse{ErrorMsg: err.Error()})
+		return
+	}
+	offset := (req.Current - 1) * req.Size
+
+	total, orders, err := ListUserOrders(c.Request.Context(), role, oauth.GetUserIDFromContext(c), req.filter(), offset, req.Size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ListOrdersResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListOrdersResponse{Data: ListOrdersResponseData{Total: total, Results: orders}})
+}
+
+// ListPaidOrders 获取当前用户作为买家的订单
+// @Tags payment
+// @Produce json
+// @Param request query ListOrdersRequest true "request query"
+// @Success 200 {object} ListOrdersResponse
+// @Router /api/v1/payment/orders/paid [get]
+func ListPaidOrders(c *gin.Context) {
+	listOrders(c, OrderRolePayer)
+}
+
+// ListSoldOrders 获取当前用户作为卖家的订单
+// @Tags payment
+// @Produce json
+// @Param request query ListOrdersRequest true "request query"
+// @Success 200 {object} ListOrdersResponse
+// @Router /api/v1/payment/orders/sold [get]
+func ListSoldOrders(c *gin.Context) {
+	listOrders(c, OrderRolePayee)
+}
+
+type SalesSummaryRequest struct {
+	Current   int       `json:"current" form:"current" binding:"min=1"`
+	Size      int       `json:"size" form:"size" binding:"min=1,max=100"`
+	Currency  Currency  `json:"currency" form:"currency" binding:"omitempty,oneof=LDC CNY CREDIT"`
+	ProjectID string    `json:"project_id" form:"project_id" binding:"max=64"`
+	StartTime time.Time `json:"start_time" form:"start_time"`
+	EndTime   time.Time `json:"end_time" form:"end_time"`
+}
+
+type SalesSummaryResponseData struct {
+	Total   int64                 `json:"total"`
+	Results []ProjectSalesSummary `json:"results"`
+	Totals  []CurrencySalesTotal  `json:"totals"`
+}
+
+type SalesSummaryResponse struct {
+	ErrorMsg string                   `json:"error_msg"`
+	Data     SalesSummaryResponseData `json:"data"`
+}
+
+// GetSalesSummary 按项目与币种汇总当前用户的销售额、退款额与净收入,totals 为各币种的合计，时间范围作用于付款时间
+// @Tags payment
+// @Produce json
+// @Param request query SalesSummaryRequest true "request query"
+// @Success 200 {object} SalesSummaryResponse
+// @Router /api/v1/payment/orders/sold/summary [get]
+func GetSalesSummary(c *gin.Context) {
+	req := &SalesSummaryRequest{}
+	if err := c.ShouldBindQuery(req); err != nil {
+		c.JSON(http.StatusBadRequest, SalesSummaryResponse{ErrorMsg: err.Error()})
+		return
+	}
+	offset := (req.Current - 1) * req.Size
+
+	userID := oauth.GetUserIDFromContext(c)
+	filter := &OrderFilter{Currency: req.Currency, ProjectID: req.ProjectID, StartTime: req.StartTime, EndTime: req.EndTime}
+	total, summaries, err := SummarizeSales(c.Request.Context(), userID, filter, offset, req.Size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, SalesSummaryResponse{ErrorMsg: err.Error()})
+		return
+	}
+	totals, err := SummarizeSalesByCurrency(c.Request.Context(), userID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, SalesSummaryResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SalesSummaryResponse{
+		Data: SalesSummaryResponseData{Total: total, Results: summaries, Totals: totals},
+	})
+}
@@ -0,0 +1,200 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package payment
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/apps/project"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/shopspring/decimal"
+)
+
+// seedSalesOrders 为收款方写入跨项目、跨币种、各状态的订单:
+//
+//	P1/LDC    : COMPLETED 10, REFUNDED 2.5, PENDING 99, FAILED 99
+//	P1/CREDIT : COMPLETED 30
+//	P2/LDC    : PAID 5, REFUNDING 4(上周付款)
+//	其他卖家  : COMPLETED 100
+func seedSalesOrders(t *testing.T) (lastWeek time.Time) {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now()
+	lastWeek = now.AddDate(0, 0, -7)
+
+	if err := db.DB(ctx).Create(&oauth.User{ID: 3, Username: "other"}).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	for _, p := range []project.Project{{ID: "P1", Name: "项目一"}, {ID: "P2", Name: "项目二"}} {
+		if err := db.DB(ctx).Create(&p).Error; err != nil {
+			t.Fatalf("create project: %v", err)
+		}
+	}
+	orders := []struct {
+		outTradeNo string
+		projectID  string
+		payeeID    uint64
+		amount     string
+		currency   Currency
+		status     OrderStatus
+		paidAt     *time.Time
+	}{
+		{"O1", "P1", testPayeeID, "10", project.CurrencyLDC, OrderStatusCompleted, &now},
+		{"O2", "P1", testPayeeID, "2.5", project.CurrencyLDC, OrderStatusRefunded, &now},
+		{"O3", "P1", testPayeeID, "99", project.CurrencyLDC, OrderStatusPending, nil},
+		{"O4", "P1", testPayeeID, "99", project.CurrencyLDC, OrderStatusFailed, nil},
+		{"O5", "P1", testPayeeID, "30", project.CurrencyCredit, OrderStatusCompleted, &now},
+		{"O6", "P2", testPayeeID, "5", project.CurrencyLDC, OrderStatusPaid, &now},
+		{"O7", "P2", testPayeeID, "4", project.CurrencyLDC, OrderStatusRefunding, &lastWeek},
+		{"O8", "P2", 3, "100", project.CurrencyLDC, OrderStatusCompleted, &now},
+	}
+	for i, o := range orders {
+		if err := db.DB(ctx).Create(&PaymentOrder{
+			OutTradeNo: o.outTradeNo,
+			ProjectID:  o.projectID,
+			ItemID:     uint64(i + 1),
+			PayerID:    testPayerID,
+			PayeeID:    o.payeeID,
+			Amount:     decimal.RequireFromString(o.amount),
+			Currency:   o.currency,
+			Status:     o.status,
+			PaidAt:     o.paidAt,
+			ExpireAt:   now,
+		}).Error; err != nil {
+			t.Fatalf("create order: %v", err)
+		}
+	}
+	return lastWeek
+}
+
+func TestListUserOrders(t *testing.T) {
+	setupPaymentStore(t)
+	ctx := context.Background()
+	seedSalesOrders(t)
+
+	// 买家视角包含所有卖家的订单，交易对方为卖家
+	total, orders, err := ListUserOrders(ctx, OrderRolePayer, testPayerID, &OrderFilter{}, 0, 100)
+	if err != nil {
+		t.Fatalf("list payer orders: %v", err)
+	}
+	if total != 8 || len(orders) != 8 || orders[0].OutTradeNo != "O8" || orders[0].Counterpart != "other" || orders[0].ProjectName != "项目二" {
+		t.Fatalf("unexpected payer orders total=%d first=%+v", total, orders[0])
+	}
+
+	// 卖家视角按状态、币种与项目过滤，交易对方为买家
+	completed := OrderStatusCompleted
+	total, orders, err = ListUserOrders(ctx, OrderRolePayee, testPayeeID, &OrderFilter{Status: &completed}, 0, 100)
+	if err != nil {
+		t.Fatalf("list payee orders: %v", err)
+	}
+	if total != 2 || orders[0].OutTradeNo != "O5" || orders[1].OutTradeNo != "O1" || orders[0].Counterpart != "payer" {
+		t.Fatalf("unexpected completed orders total=%d %+v", total, orders)
+	}
+	total, _, err = ListUserOrders(ctx, OrderRolePayee, testPayeeID, &OrderFilter{Currency: project.CurrencyLDC, ProjectID: "P1"}, 0, 100)
+	if err != nil || total != 4 {
+		t.Fatalf("want 4 LDC orders in P1, got %d (%v)", total, err)
+	}
+
+	// 分页只影响结果，不影响总数
+	total, orders, err = ListUserOrders(ctx, OrderRolePayee, testPayeeID, &OrderFilter{}, 2, 2)
+	if err != nil || total != 7 || len(orders) != 2 || orders[0].OutTradeNo != "O5" {
+		t.Fatalf("unexpected page total=%d orders=%+v (%v)", total, orders, err)
+	}
+}
+
+func TestSummarizeSales(t *testing.T) {
+	setupPaymentStore(t)
+	ctx := context.Background()
+	seedSalesOrders(t)
+
+	total, summaries, err := SummarizeSales(ctx, testPayeeID, &OrderFilter{}, 0, 100)
+	if err != nil {
+		t.Fatalf("summarize: %v", err)
+	}
+	if total != 3 || len(summaries) != 3 {
+		t.Fatalf("want 3 project/currency groups, got total=%d %+v", total, summaries)
+	}
+	want := []struct {
+		projectID                      string
+		currency                       Currency
+		paid, refunded                 int64
+		gross, refundedAmount, netSale string
+	}{
+		// 按币种升序，同币种按销售额降序;待支付与已关闭订单不计入
+		{"P1", project.CurrencyCredit, 1, 0, "30", "0", "30"},
+		{"P1", project.CurrencyLDC, 2, 1, "12.5", "2.5", "10"},
+		{"P2", project.CurrencyLDC, 2, 0, "9", "0", "9"},
+	}
+	for i, w := range want {
+		got := summaries[i]
+		if got.ProjectID != w.projectID || got.Currency != w.currency || got.PaidCount != w.paid || got.RefundedCount != w.refunded ||
+			!got.Gross.Equal(decimal.RequireFromString(w.gross)) || !got.Refunded.Equal(decimal.RequireFromString(w.refundedAmount)) ||
+			!got.Net.Equal(decimal.RequireFromString(w.netSale)) {
+			t.Fatalf("summary %d: want %+v, got %+v", i, w, got)
+		}
+	}
+	if summaries[1].ProjectName != "项目一" {
+		t.Fatalf("want project name joined, got %q", summaries[1].ProjectName)
+	}
+}
+
+func TestSummarizeSalesByCurrency(t *testing.T) {
+	setupPaymentStore(t)
+	ctx := context.Background()
+	lastWeek := seedSalesOrders(t)
+
+	totals, err := SummarizeSalesByCurrency(ctx, testPayeeID, &OrderFilter{})
+	if err != nil {
+		t.Fatalf("summarize: %v", err)
+	}
+	// 不同币种的金额分别合计，不做合并
+	if len(totals) != 2 || totals[0].Currency != project.CurrencyCredit || totals[1].Currency != project.CurrencyLDC {
+		t.Fatalf("want CREDIT and LDC totals, got %+v", totals)
+	}
+	ldc := totals[1]
+	if ldc.PaidCount != 4 || ldc.RefundedCount != 1 || !ldc.Gross.Equal(decimal.RequireFromString("21.5")) ||
+		!ldc.Refunded.Equal(decimal.RequireFromString("2.5")) || !ldc.Net.Equal(decimal.RequireFromString("19")) {
+		t.Fatalf("unexpected LDC total %+v", ldc)
+	}
+	if !totals[0].Gross.Equal(decimal.NewFromInt(30)) || totals[0].PaidCount != 1 {
+		t.Fatalf("unexpected CREDIT total %+v", totals[0])
+	}
+
+	// 时间范围作用于付款时间
+	totals, err = SummarizeSalesByCurrency(ctx, testPayeeID, &OrderFilter{
+		Currency:  project.CurrencyLDC,
+		StartTime: lastWeek.Add(-time.Hour),
+		EndTime:   lastWeek.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("summarize range: %v", err)
+	}
+	if len(totals) != 1 || totals[0].PaidCount != 1 || !totals[0].Gross.Equal(decimal.NewFromInt(4)) {
+		t.Fatalf("want only last week's refunding order, got %+v", totals)
+	}
+}
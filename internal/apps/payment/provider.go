@@ -0,0 +1,173 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ProviderType 支付渠道类型，记录在商户配置与订单上
+type ProviderType string
+
+const (
+	// ProviderTypeEpay 易支付兼容协议(MD5 签名，GET 回调)
+	ProviderTypeEpay ProviderType = "epay"
+)
+
+// Merchant 已解密的商户凭据
+type Merchant struct {
+	ClientID     string
+	ClientSecret string
+}
+
+// CreateOrderRequest 下单参数
+type CreateOrderRequest struct {
+	OutTradeNo string
+	Name       string
+	Money      string
+	NotifyURL  string
+	ReturnURL  string
+}
+
+// NotifyRequest 支付渠道回调的原始请求
+type NotifyRequest struct {
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// NotifyResult 验签通过后的回调内容,Money 为按订单币种精度格式化的金额字符串
+type NotifyResult struct {
+	OutTradeNo string
+	TradeNo    string
+	ClientID   string
+	Money      string
+	Paid       bool
+}
+
+// OrderQueryResult 主动查询的订单状态
+type OrderQueryResult struct {
+	OutTradeNo string
+	TradeNo    string
+	Money      string
+	Paid       bool
+}
+
+// RefundRequest 退款参数，当前仅支持全额退款
+type RefundRequest struct {
+	OutTradeNo string
+	TradeNo    string
+	Money      string
+}
+
+// PaymentProvider 支付渠道协议。HandleNotify 的订单状态机只依赖该接口，新增渠道无需改动状态机。
+type PaymentProvider interface {
+	// Type 渠道类型
+	Type() ProviderType
+	// Currencies 渠道支持的结算币种，首个为商户未指定币种时的默认值
+	Currencies() []Currency
+	// CreateOrder 创建订单并返回前端跳转地址;复用待支付订单时可能以相同 OutTradeNo 重复调用，实现需幂等
+	CreateOrder(ctx context.Context, merchant Merchant, req CreateOrderRequest) (payURL string, err error)
+	// NotifyOutTradeNo 在验签前从回调中取出本地订单号，仅用于定位订单与商户凭据
+	NotifyOutTradeNo(req *NotifyRequest) (string, error)
+	// VerifyNotify 校验回调签名并解析回调内容
+	VerifyNotify(ctx context.Context, merchant Merchant, req *NotifyRequest) (*NotifyResult, error)
+	// NotifyAck 回调处理结果对应的 HTTP 状态码与响应体
+	NotifyAck(ok bool) (status int, body string)
+	// QueryOrder 主动查询订单状态;用户未打开支付页导致渠道尚无该订单时返回 ErrGatewayOrderNotFoundSentinel
+	QueryOrder(ctx context.Context, merchant Merchant, outTradeNo string) (*OrderQueryResult, error)
+	// Refund 发起全额退款，成功返回 nil
+	Refund(ctx context.Context, merchant Merchant, req RefundRequest) error
+	// VerifyMerchant 以商户凭据向渠道发起一次鉴权调用，凭据无效时返回错误
+	VerifyMerchant(ctx context.Context, merchant Merchant) error
+}
+
+// NotifyReplayGuard 可选的渠道接口，返回回调中用于重放保护的唯一标识与签发时间;
+// 渠道未提供时间戳时 timestamp 为零值。未实现该接口的渠道不做重放校验。
+type NotifyReplayGuard interface {
+	NotifyNonce(req *NotifyRequest) (nonce string, timestamp time.Time)
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[ProviderType]PaymentProvider{
+		ProviderTypeEpay: epayProvider{},
+	}
+)
+
+// RegisterProvider 注册支付渠道，同类型重复注册时覆盖
+func RegisterProvider(provider PaymentProvider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[provider.Type()] = provider
+}
+
+// GetProvider 返回指定类型的支付渠道，空类型视为易支付以兼容存量数据
+func GetProvider(providerType ProviderType) (PaymentProvider, error) {
+	if providerType == "" {
+		providerType = ProviderTypeEpay
+	}
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	provider, ok := providers[providerType]
+	if !ok {
+		return nil, fmt.Errorf(ErrProviderUnsupported, providerType)
+	}
+	return provider, nil
+}
+
+// supportsCurrency 渠道是否支持以指定币种结算
+func supportsCurrency(provider PaymentProvider, currency Currency) bool {
+	return slices.Contains(provider.Currencies(), currency)
+}
+
+// merchantOf 解密商户配置并返回其支付渠道
+func merchantOf(cfg *UserPaymentConfig) (PaymentProvider, Merchant, error) {
+	provider, err := GetProvider(cfg.Provider)
+	if err != nil {
+		return nil, Merchant{}, err
+	}
+	secret, err := decryptUserClientSecret(cfg)
+	if err != nil {
+		return nil, Merchant{}, err
+	}
+	return provider, Merchant{ClientID: cfg.ClientID, ClientSecret: secret}, nil
+}
+
+// notifyBodyLimit 回调请求体读取上限
+const notifyBodyLimit = 64 << 10
+
+// ErrGatewayOrderNotFoundSentinel 渠道中不存在该订单，对账时视为未付款
+var ErrGatewayOrderNotFoundSentinel = errors.New(ErrGatewayOrderNotFound)
+
+// errNotifyMissingOutTradeNo 回调缺少本地订单号
+var errNotifyMissingOutTradeNo = errors.New("missing out_trade_no")
@@ -0,0 +1,277 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/linux-do/cdk/internal/apps/project"
+	"github.com/linux-do/cdk/internal/config"
+	"github.com/shopspring/decimal"
+)
+
+// fakeProvider 测试用支付渠道：以整数积分结算，回调请求体即订单号，按预设结果返回，并记录退款调用(refundErr 非空时退款失败)
+type fakeProvider struct {
+	notify    *NotifyResult
+	orders    map[string]*OrderQueryResult
+	queryErrs map[string]error
+	refunds   []RefundRequest
+	refundErr error
+}
+
+const providerTypeFake ProviderType = "fake"
+
+func (f *fakeProvider) Type() ProviderType { return providerTypeFake }
+
+func (f *fakeProvider) Currencies() []Currency { return []Currency{project.CurrencyCredit} }
+
+func (f *fakeProvider) CreateOrder(_ context.Context, merchant Merchant, req CreateOrderRequest) (string, error) {
+	return "https://fake.example.com/pay/" + merchant.ClientID + "/" + req.OutTradeNo, nil
+}
+
+func (f *fakeProvider) NotifyOutTradeNo(req *NotifyRequest) (string, error) {
+	if len(req.Body) == 0 {
+		return "", errNotifyMissingOutTradeNo
+	}
+	return string(req.Body), nil
+}
+
+func (f *fakeProvider) VerifyNotify(_ context.Context, _ Merchant, _ *NotifyRequest) (*NotifyResult, error) {
+	if f.notify == nil {
+		return nil, errors.New("sign mismatch")
+	}
+	return f.notify, nil
+}
+
+func (f *fakeProvider) NotifyAck(ok bool) (int, string) {
+	if ok {
+		return http.StatusOK, "ok"
+	}
+	return http.StatusInternalServerError, "retry"
+}
+
+func (f *fakeProvider) QueryOrder(_ context.Context, _ Merchant, outTradeNo string) (*OrderQueryResult, error) {
+	if err, ok := f.queryErrs[outTradeNo]; ok {
+		return nil, err
+	}
+	result, ok := f.orders[outTradeNo]
+	if !ok {
+		return nil, ErrGatewayOrderNotFoundSentinel
+	}
+	return result, nil
+}
+
+func (f *fakeProvider) Refund(_ context.Context, _ Merchant, req RefundRequest) error {
+	f.refunds = append(f.refunds, req)
+	return f.refundErr
+}
+
+func (f *fakeProvider) VerifyMerchant(_ context.Context, merchant Merchant) error {
+	if merchant.ClientSecret == "" {
+		return errors.New("invalid merchant")
+	}
+	return nil
+}
+
+func TestGetProviderDefaultsToEpay(t *testing.T) {
+	provider, err := GetProvider("")
+	if err != nil {
+		t.Fatalf("want epay provider, got %v", err)
+	}
+	if provider.Type() != ProviderTypeEpay {
+		t.Fatalf("want epay, got %s", provider.Type())
+	}
+	if _, err := GetProvider("unknown"); err == nil {
+		t.Fatalf("want unknown provider rejected")
+	}
+}
+
+func TestRegisterFakeProvider(t *testing.T) {
+	fake := &fakeProvider{}
+	RegisterProvider(fake)
+	provider, err := GetProvider(providerTypeFake)
+	if err != nil {
+		t.Fatalf("want fake provider registered, got %v", err)
+	}
+	payURL, err := provider.CreateOrder(context.Background(), Merchant{ClientID: "m1"}, CreateOrderRequest{OutTradeNo: "CDK1"})
+	if err != nil || payURL != "https://fake.example.com/pay/m1/CDK1" {
+		t.Fatalf("unexpected pay url %q, err=%v", payURL, err)
+	}
+	order := &PaymentOrder{OutTradeNo: "CDK1", TradeNo: "T1", Amount: decimal.RequireFromString("1.5")}
+	if err := provider.Refund(context.Background(), Merchant{}, order.refundRequest()); err != nil {
+		t.Fatalf("refund failed: %v", err)
+	}
+	if len(fake.refunds) != 1 || fake.refunds[0].Money != "1.50" || fake.refunds[0].TradeNo != "T1" {
+		t.Fatalf("unexpected refunds %+v", fake.refunds)
+	}
+
+	// 整数积分渠道按订单币种精度格式化金额
+	if !supportsCurrency(provider, project.CurrencyCredit) || supportsCurrency(provider, project.CurrencyLDC) {
+		t.Fatalf("unexpected currencies %v", provider.Currencies())
+	}
+	credit := &PaymentOrder{OutTradeNo: "CDK2", TradeNo: "T2", Amount: decimal.NewFromInt(3), Currency: project.CurrencyCredit}
+	if err := provider.Refund(context.Background(), Merchant{}, credit.refundRequest()); err != nil {
+		t.Fatalf("refund failed: %v", err)
+	}
+	if fake.refunds[1].Money != "3" {
+		t.Fatalf("want integer refund money, got %q", fake.refunds[1].Money)
+	}
+}
+
+func TestCheckNotifyResult(t *testing.T) {
+	cfg := &UserPaymentConfig{ClientID: "m1"}
+	order := &PaymentOrder{OutTradeNo: "CDK1", Amount: decimal.RequireFromString("10")}
+	ok := NotifyResult{OutTradeNo: "CDK1", ClientID: "m1", Money: "10.00", Paid: true}
+	if reason := checkNotifyResult(&ok, cfg, order); reason != "" {
+		t.Fatalf("want accepted, got %s", reason)
+	}
+
+	cases := map[string]func(r *NotifyResult){
+		"unpaid":         func(r *NotifyResult) { r.Paid = false },
+		"other order":    func(r *NotifyResult) { r.OutTradeNo = "CDK2" },
+		"other merchant": func(r *NotifyResult) { r.ClientID = "m2" },
+		"wrong money":    func(r *NotifyResult) { r.Money = "1.00" },
+	}
+	for name, mutate := range cases {
+		result := ok
+		mutate(&result)
+		if reason := checkNotifyResult(&result, cfg, order); reason == "" {
+			t.Fatalf("%s: want rejected", name)
+		}
+	}
+
+	credit := &PaymentOrder{OutTradeNo: "CDK1", Amount: decimal.NewFromInt(10), Currency: project.CurrencyCredit}
+	if reason := checkNotifyResult(&ok, cfg, credit); reason == "" {
+		t.Fatalf("want fiat money rejected for credit order")
+	}
+	ok.Money = "10"
+	if reason := checkNotifyResult(&ok, cfg, credit); reason != "" {
+		t.Fatalf("want credit money accepted, got %s", reason)
+	}
+}
+
+func TestEpayVerifyNotify(t *testing.T) {
+	params := map[string]string{
+		"pid":          "001",
+		"trade_no":     "T20260420",
+		"out_trade_no": "CDK20260420abcd1234",
+		"money":        "10.00",
+		"trade_status": "TRADE_SUCCESS",
+	}
+	params["sign"] = BuildSign(params, "SECRET")
+	query := url.Values{}
+	for k, v := range params {
+		query.Set(k, v)
+	}
+
+	provider := epayProvider{}
+	req := &NotifyRequest{Query: query}
+	if outTradeNo, err := provider.NotifyOutTradeNo(req); err != nil || outTradeNo != "CDK20260420abcd1234" {
+		t.Fatalf("unexpected out_trade_no %q, err=%v", outTradeNo, err)
+	}
+	result, err := provider.VerifyNotify(context.Background(), Merchant{ClientID: "001", ClientSecret: "SECRET"}, req)
+	if err != nil {
+		t.Fatalf("want verified, got %v", err)
+	}
+	if !result.Paid || result.TradeNo != "T20260420" || result.ClientID != "001" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if _, err := provider.VerifyNotify(context.Background(), Merchant{ClientSecret: "OTHER"}, req); err == nil {
+		t.Fatalf("want sign mismatch")
+	}
+}
+
+func TestEpayVerifyMerchant(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api.php" || q.Get("act") != "query" {
+			http.NotFound(w, r)
+			return
+		}
+		if q.Get("pid") == "001" && q.Get("key") == "SECRET" {
+			_, _ = w.Write([]byte(`{"code":1,"pid":1,"active":1}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":-3,"msg":"KEY校验失败"}`))
+	}))
+	defer server.Close()
+
+	original := config.Config.Payment.ApiUrl
+	config.Config.Payment.ApiUrl = server.URL
+	defer func() { config.Config.Payment.ApiUrl = original }()
+
+	provider := epayProvider{}
+	if err := provider.VerifyMerchant(context.Background(), Merchant{ClientID: "001", ClientSecret: "SECRET"}); err != nil {
+		t.Fatalf("want verified, got %v", err)
+	}
+	if err := provider.VerifyMerchant(context.Background(), Merchant{ClientID: "001", ClientSecret: "WRONG"}); err == nil {
+		t.Fatalf("want invalid credentials rejected")
+	}
+}
+
+func TestEpayQueryOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api.php" || q.Get("act") != "order" {
+			http.NotFound(w, r)
+			return
+		}
+		switch {
+		case q.Get("key") != "SECRET":
+			_, _ = w.Write([]byte(`{"code":-3,"msg":"KEY校验失败"}`))
+		case q.Get("out_trade_no") == "CDK1":
+			_, _ = w.Write([]byte(`{"code":1,"trade_no":"T1","out_trade_no":"CDK1","money":"10.00","status":1}`))
+		default:
+			_, _ = w.Write([]byte(`{"code":-1,"msg":"订单号不存在"}`))
+		}
+	}))
+	defer server.Close()
+
+	original := config.Config.Payment.ApiUrl
+	config.Config.Payment.ApiUrl = server.URL
+	defer func() { config.Config.Payment.ApiUrl = original }()
+
+	provider := epayProvider{}
+	merchant := Merchant{ClientID: "001", ClientSecret: "SECRET"}
+	result, err := provider.QueryOrder(context.Background(), merchant, "CDK1")
+	if err != nil || !result.Paid || result.TradeNo != "T1" || result.Money != "10.00" {
+		t.Fatalf("unexpected result %+v, err=%v", result, err)
+	}
+
+	// 用户未打开支付页的订单在网关不存在
+	if _, err := provider.QueryOrder(context.Background(), merchant, "CDK2"); !errors.Is(err, ErrGatewayOrderNotFoundSentinel) {
+		t.Fatalf("want gateway order not found, got %v", err)
+	}
+	// 凭据错误不能被当作未付款
+	_, err = provider.QueryOrder(context.Background(), Merchant{ClientID: "001", ClientSecret: "WRONG"}, "CDK2")
+	if err == nil || errors.Is(err, ErrGatewayOrderNotFoundSentinel) {
+		t.Fatalf("want credential error, got %v", err)
+	}
+}
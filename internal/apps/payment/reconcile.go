@@ -0,0 +1,247 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/logger"
+	"gorm.io/gorm/clause"
+)
+
+// DiscrepancyKind 对账差异类型
+type DiscrepancyKind string
+
+const (
+	// DiscrepancyPaidButFailed 渠道已付款，本地订单已因超时关闭
+	DiscrepancyPaidButFailed DiscrepancyKind = "paid_but_failed"
+	// DiscrepancyMismatch 渠道返回的订单号/商户号/金额与本地订单不一致
+	DiscrepancyMismatch DiscrepancyKind = "mismatch"
+	// DiscrepancyQueryFailed 订单已过期但始终未能向渠道确认付款状态
+	DiscrepancyQueryFailed DiscrepancyKind = "query_failed"
+	// DiscrepancyRefundFailed 退款重试耗尽，订单停留在 REFUND_FAILED 等待管理员处理
+	DiscrepancyRefundFailed DiscrepancyKind = "refund_failed"
+)
+
+const (
+	// reconcileBatchLimit 单次对账每类订单的处理上限
+	reconcileBatchLimit = 200
+	// reconcilePendingWindow 距过期不足该时长的 PENDING 订单进入对账
+	reconcilePendingWindow = 2 * time.Minute
+	// refundRetryBaseDelay 对账重试退款失败后的首次退避间隔，此后每次翻倍
+	refundRetryBaseDelay = time.Minute
+	// maxRefundAttempts 对账重试退款的次数上限，耗尽后订单转为 REFUND_FAILED
+	maxRefundAttempts = 10
+)
+
+// PaymentDiscrepancy 对账差异记录,(out_trade_no, kind) 唯一，重复发现时刷新详情并重新打开
+type PaymentDiscrepancy struct {
+	ID          uint64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	OutTradeNo  string          `gorm:"size:64;not null;uniqueIndex:idx_trade_kind,priority:1" json:"out_trade_no"`
+	Kind        DiscrepancyKind `gorm:"size:32;not null;uniqueIndex:idx_trade_kind,priority:2" json:"kind"`
+	ProjectID   string          `gorm:"size:64;index" json:"project_id"`
+	PayerID     uint64          `gorm:"index" json:"payer_id"`
+	PayeeID     uint64          `gorm:"index" json:"payee_id"`
+	LocalStatus OrderStatus     `json:"local_status"`
+	TradeNo     string          `gorm:"size:64" json:"trade_no"`
+	Detail      string          `gorm:"size:255" json:"detail"`
+	ResolvedAt  *time.Time      `gorm:"index" json:"resolved_at"`
+	ResolvedBy  *uint64         `json:"resolved_by"`
+	ResolveNote string          `gorm:"size:255" json:"resolve_note"`
+	CreatedAt   time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 自定义表名
+func (PaymentDiscrepancy) TableName() string { return "payment_discrepancies" }
+
+// recordDiscrepancy 登记对账差异，失败仅记录日志，不影响主流程
+func recordDiscrepancy(ctx context.Context, order *PaymentOrder, kind DiscrepancyKind, tradeNo, detail string) {
+	discrepancy := PaymentDiscrepancy{
+		OutTradeNo:  order.OutTradeNo,
+		Kind:        kind,
+		ProjectID:   order.ProjectID,
+		PayerID:     order.PayerID,
+		PayeeID:     order.PayeeID,
+		LocalStatus: order.Status,
+		TradeNo:     tradeNo,
+		Detail:      truncateRuneLen(detail, 255),
+	}
+	if err := db.DB(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "out_trade_no"}, {Name: "kind"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"local_status": discrepancy.LocalStatus,
+			"trade_no":     discrepancy.TradeNo,
+			"detail":       discrepancy.Detail,
+			"resolved_at":  nil,
+			"resolved_by":  nil,
+			"updated_at":   time.Now(),
+		}),
+	}).Create(&discrepancy).Error; err != nil {
+		logger.ErrorF(ctx, "payment reconcile: failed to record %s discrepancy for order %s: %v", kind, order.OutTradeNo, err)
+		return
+	}
+	logger.WarnF(ctx, "payment reconcile: order %s %s: %s", order.OutTradeNo, kind, detail)
+}
+
+// HandleReconcileOrders 主动向支付渠道查询订单状态，弥补回调丢失:
+//   - 即将过期(或已过期尚未清理)的 PENDING 订单：渠道已付款则按回调同样的 CAS 流程发放
+//   - 到达重试时间的 REFUNDING 订单：重试退款，成功后 CAS 推进到 REFUNDED 并归还 item,失败按指数退避等待下次重试
+func HandleReconcileOrders(ctx context.Context, _ *asynq.Task) error {
+	var pending []PaymentOrder
+	if err := db.DB(ctx).
+		Where("status = ? AND expire_at < ?", OrderStatusPending, time.Now().Add(reconcilePendingWindow)).
+		Order("expire_at ASC").
+		Limit(reconcileBatchLimit).
+		Find(&pending).Error; err != nil {
+		logger.ErrorF(ctx, "payment reconcile: failed to query pending orders: %v", err)
+		return err
+	}
+	for i := range pending {
+		reconcilePendingOrder(ctx, &pending[i])
+	}
+
+	var refunding []PaymentOrder
+	if err := db.DB(ctx).
+		Where("status = ? AND (next_refund_at IS NULL OR next_refund_at <= ?)", OrderStatusRefunding, time.Now()).
+		Order("id ASC").
+		Limit(reconcileBatchLimit).
+		Find(&refunding).Error; err != nil {
+		logger.ErrorF(ctx, "payment reconcile: failed to query refunding orders: %v", err)
+		return err
+	}
+	for i := range refunding {
+		reconcileRefundingOrder(ctx, &refunding[i])
+	}
+
+	logger.InfoF(ctx, "payment reconcile: checked %d pending and %d refunding orders", len(pending), len(refunding))
+	return nil
+}
+
+// orderMerchant 加载订单收款方的商户凭据，收款方已更换渠道时无法使用当前凭据查询
+func orderMerchant(ctx context.Context, order *PaymentOrder) (PaymentProvider, Merchant, error) {
+	cfg, err := GetUserPaymentConfig(ctx, order.PayeeID)
+	if err != nil {
+		return nil, Merchant{}, err
+	}
+	if cfg == nil || !order.matchesMerchant(cfg) {
+		return nil, Merchant{}, errors.New(ErrMerchantChanged)
+	}
+	return merchantOf(cfg)
+}
+
+// reconcilePendingOrder 查询单笔 PENDING 订单，渠道已付款则推进状态机
+func reconcilePendingOrder(ctx context.Context, order *PaymentOrder) {
+	provider, merchant, err := orderMerchant(ctx, order)
+	if err == nil {
+		var result *OrderQueryResult
+		result, err = provider.QueryOrder(ctx, merchant, order.OutTradeNo)
+		if errors.Is(err, ErrGatewayOrderNotFoundSentinel) {
+			// 用户未打开支付页，渠道尚无订单，按未付款处理并交由清理任务关闭
+			return
+		}
+		if err == nil {
+			if !result.Paid {
+				return
+			}
+			if result.OutTradeNo != order.OutTradeNo || result.Money != order.money() {
+				recordDiscrepancy(ctx, order, DiscrepancyMismatch, result.TradeNo, "渠道订单号或金额与本地订单不一致")
+				return
+			}
+			ok, reason := settlePaidOrder(ctx, provider, merchant, order, result.TradeNo)
+			logger.InfoF(ctx, "payment reconcile: settled pending order %s ok=%v reason=%s", order.OutTradeNo, ok, reason)
+			return
+		}
+	}
+
+	// 已过期仍无法确认(网络、验签或凭据错误)时登记差异，避免清理任务关闭后静默丢失真实付款
+	logger.ErrorF(ctx, "payment reconcile: failed to query order %s: %v", order.OutTradeNo, err)
+	if order.ExpireAt.Before(time.Now()) {
+		recordDiscrepancy(ctx, order, DiscrepancyQueryFailed, "", err.Error())
+	}
+}
+
+// reconcileRefundingOrder 重试退款并通过 CAS 将订单推进到 REFUNDED,失败时按指数退避安排下次重试
+func reconcileRefundingOrder(ctx context.Context, order *PaymentOrder) {
+	provider, merchant, err := orderMerchant(ctx, order)
+	if err == nil {
+		err = provider.Refund(ctx, merchant, order.refundRequest())
+	}
+	if err != nil {
+		deferRefundRetry(ctx, order, err)
+		return
+	}
+
+	now := time.Now()
+	if _, err := markOrderRefundedAndReturnItem(ctx, order, map[string]any{"status": OrderStatusRefunded, "refunded_at": &now}, OrderStatusRefunding); err != nil {
+		logger.ErrorF(ctx, "payment reconcile: failed to mark order %s refunded: %v", order.OutTradeNo, err)
+		return
+	}
+	logger.InfoF(ctx, "payment reconcile: order %s refunded", order.OutTradeNo)
+}
+
+// refundRetryDelay 第 attempts 次失败后的退避间隔
+func refundRetryDelay(attempts int) time.Duration {
+	return refundRetryBaseDelay << (attempts - 1)
+}
+
+// deferRefundRetry 记录一次退款失败并安排下次重试，达到次数上限后将订单转为 REFUND_FAILED 并登记差异，不再自动重试
+func deferRefundRetry(ctx context.Context, order *PaymentOrder, cause error) {
+	attempts := order.RefundAttempts + 1
+	updates := map[string]any{"refund_attempts": attempts}
+	exhausted := attempts >= maxRefundAttempts
+	if exhausted {
+		updates["status"] = OrderStatusRefundFailed
+		updates["next_refund_at"] = nil
+	} else {
+		next := time.Now().Add(refundRetryDelay(attempts))
+		updates["next_refund_at"] = &next
+	}
+
+	// CAS: 并发的回调或管理员操作已推进订单时放弃本次记录
+	result := db.DB(ctx).Model(&PaymentOrder{}).
+		Where("out_trade_no = ? AND status = ? AND refund_attempts = ?", order.OutTradeNo, OrderStatusRefunding, order.RefundAttempts).
+		Updates(updates)
+	if result.Error != nil {
+		logger.ErrorF(ctx, "payment reconcile: failed to record refund attempt for order %s: %v", order.OutTradeNo, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+	order.RefundAttempts = attempts
+
+	if exhausted {
+		order.Status = OrderStatusRefundFailed
+		recordDiscrepancy(ctx, order, DiscrepancyRefundFailed, order.TradeNo, fmt.Sprintf("退款重试 %d 次仍失败，需管理员处理: %v", attempts, cause))
+		return
+	}
+	logger.WarnF(ctx, "payment reconcile: order %s refund attempt %d failed, retry in %s: %v", order.OutTradeNo, attempts, refundRetryDelay(attempts), cause)
+}
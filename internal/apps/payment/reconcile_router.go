@@ -0,0 +1,136 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package payment
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/db"
+)
+
+type ListDiscrepanciesRequest struct {
+	Current  int             `json:"current" form:"current" binding:"min=1"`
+	Size     int             `json:"size" form:"size" binding:"min=1,max=100"`
+	Kind     DiscrepancyKind `json:"kind" form:"kind" binding:"omitempty,oneof=paid_but_failed mismatch query_failed refund_failed"`
+	Resolved *bool           `json:"resolved" form:"resolved"`
+}
+
+type ListDiscrepanciesResponseData struct {
+	Total   int64                `json:"total"`
+	Results []PaymentDiscrepancy `json:"results"`
+}
+
+type ListDiscrepanciesResponse struct {
+	ErrorMsg string                        `json:"error_msg"`
+	Data     ListDiscrepanciesResponseData `json:"data"`
+}
+
+// ListDiscrepancies 获取支付对账差异
+// @Tags admin
+// @Produce json
+// @Param request query ListDiscrepanciesRequest true "request query"
+// @Success 200 {object} ListDiscrepanciesResponse
+// @Router /api/v1/admin/payment/discrepancies [get]
+func ListDiscrepancies(c *gin.Context) {
+	req := &ListDiscrepanciesRequest{}
+	if err := c.ShouldBindQuery(req); err != nil {
+		c.JSON(http.StatusBadRequest, ListDiscrepanciesResponse{ErrorMsg: err.Error()})
+		return
+	}
+	offset := (req.Current - 1) * req.Size
+
+	query := db.DB(c.Request.Context()).Model(&PaymentDiscrepancy{})
+	if req.Kind != "" {
+		query = query.Where("kind = ?", req.Kind)
+	}
+	if req.Resolved != nil {
+		if *req.Resolved {
+			query = query.Where("resolved_at IS NOT NULL")
+		} else {
+			query = query.Where("resolved_at IS NULL")
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ListDiscrepanciesResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	var discrepancies []PaymentDiscrepancy
+	if err := query.Order("updated_at DESC").Offset(offset).Limit(req.Size).Find(&discrepancies).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ListDiscrepanciesResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListDiscrepanciesResponse{
+		Data: ListDiscrepanciesResponseData{Total: total, Results: discrepancies},
+	})
+}
+
+type ResolveDiscrepancyRequest struct {
+	Note string `json:"note" binding:"max=255"`
+}
+
+// ResolveDiscrepancy 标记对账差异已处理
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "差异ID"
+// @Param request body ResolveDiscrepancyRequest true "处理说明"
+// @Success 200 {object} Response
+// @Router /api/v1/admin/payment/discrepancies/{id}/resolve [put]
+func ResolveDiscrepancy(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{ErrorMsg: err.Error()})
+		return
+	}
+	var req ResolveDiscrepancyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{ErrorMsg: err.Error()})
+		return
+	}
+
+	now := time.Now()
+	adminID := oauth.GetUserIDFromContext(c)
+	result := db.DB(c.Request.Context()).Model(&PaymentDiscrepancy{}).
+		Where("id = ? AND resolved_at IS NULL", id).
+		Updates(map[string]interface{}{"resolved_at": &now, "resolved_by": adminID, "resolve_note": req.Note})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, Response{ErrorMsg: result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, Response{ErrorMsg: ErrDiscrepancyNotFound})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{})
+}
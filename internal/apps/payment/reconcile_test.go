@@ -0,0 +1,396 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/linux-do/cdk/internal/apps/notification"
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/apps/project"
+	"github.com/linux-do/cdk/internal/apps/webhook"
+	"github.com/linux-do/cdk/internal/config"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/db/dbtest"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	testPayeeID uint64 = 1
+	testPayerID uint64 = 2
+)
+
+// setupPaymentStore 准备支付与项目相关表、Redis 及测试渠道，收款方以测试渠道配置积分商户
+func setupPaymentStore(t *testing.T) *fakeProvider {
+	t.Helper()
+	dbtest.Setup(t,
+		&oauth.User{}, &project.Project{}, &project.ProjectItem{}, &project.ProjectClaim{}, &project.ProjectWaitlist{},
+		&UserPaymentConfig{}, &PaymentOrder{}, &PaymentDiscrepancy{}, &Coupon{},
+		&webhook.Webhook{}, &webhook.WebhookDelivery{}, &notification.Notification{},
+	)
+	payment := config.Config.Payment
+	t.Cleanup(func() { config.Config.Payment = payment })
+	config.Config.Payment.ConfigEncryptionKey = "0123456789abcdef0123456789abcdef"
+	config.Config.Payment.ConfigEncryptionKeyVersion = ""
+
+	fake := &fakeProvider{orders: map[string]*OrderQueryResult{}, queryErrs: map[string]error{}}
+	RegisterProvider(fake)
+
+	ctx := context.Background()
+	users := []oauth.User{
+		{ID: testPayeeID, Username: "payee", Score: oauth.BaseUserScore},
+		{ID: testPayerID, Username: "payer", Score: oauth.BaseUserScore},
+	}
+	if err := db.DB(ctx).Create(&users).Error; err != nil {
+		t.Fatalf("create users: %v", err)
+	}
+	secret, err := encryptClientSecret("SECRET")
+	if err != nil {
+		t.Fatalf("encrypt secret: %v", err)
+	}
+	if err := db.DB(ctx).Create(&UserPaymentConfig{
+		UserID:          testPayeeID,
+		Provider:        providerTypeFake,
+		Currency:        project.CurrencyCredit,
+		ClientID:        "m1",
+		ClientSecretEnc: secret,
+	}).Error; err != nil {
+		t.Fatalf("create payment config: %v", err)
+	}
+	return fake
+}
+
+// createReservedOrderFixture 创建一个付费项目及一个已被订单预占的 item,订单金额为 10 积分
+func createReservedOrderFixture(t *testing.T, outTradeNo string, status OrderStatus, expireAt time.Time) *PaymentOrder {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now()
+	p := project.Project{
+		ID:               "project-" + outTradeNo,
+		Name:             outTradeNo,
+		DistributionType: project.DistributionTypeOneForEach,
+		TotalItems:       1,
+		StartTime:        now.Add(-time.Hour),
+		EndTime:          now.Add(time.Hour),
+		CreatorID:        testPayeeID,
+		Price:            decimal.NewFromInt(10),
+		Currency:         project.CurrencyCredit,
+		MaxPerUser:       1,
+	}
+	if err := db.DB(ctx).Create(&p).Error; err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	item := project.ProjectItem{ProjectID: p.ID, Content: "KEY-" + outTradeNo}
+	if err := db.DB(ctx).Create(&item).Error; err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+	order := &PaymentOrder{
+		OutTradeNo:    outTradeNo,
+		ProjectID:     p.ID,
+		ItemID:        item.ID,
+		PayerID:       testPayerID,
+		PayeeID:       testPayeeID,
+		PayeeClientID: "m1",
+		Provider:      providerTypeFake,
+		Amount:        decimal.NewFromInt(10),
+		Currency:      project.CurrencyCredit,
+		Status:        status,
+		ExpireAt:      expireAt,
+	}
+	if status != OrderStatusPending {
+		order.TradeNo = "T-" + outTradeNo
+	}
+	if err := db.DB(ctx).Create(order).Error; err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	return order
+}
+
+// reloadOrder 读取订单最新状态
+func reloadOrder(t *testing.T, outTradeNo string) *PaymentOrder {
+	t.Helper()
+	var order PaymentOrder
+	if err := db.DB(context.Background()).Where("out_trade_no = ?", outTradeNo).First(&order).Error; err != nil {
+		t.Fatalf("reload order: %v", err)
+	}
+	return &order
+}
+
+// discrepancies 返回订单登记的差异记录
+func discrepancies(t *testing.T, outTradeNo string) []PaymentDiscrepancy {
+	t.Helper()
+	var records []PaymentDiscrepancy
+	if err := db.DB(context.Background()).Where("out_trade_no = ?", outTradeNo).Find(&records).Error; err != nil {
+		t.Fatalf("load discrepancies: %v", err)
+	}
+	return records
+}
+
+// itemInStock 判断 item 是否已回到项目的公开库存
+func itemInStock(t *testing.T, order *PaymentOrder) bool {
+	t.Helper()
+	values, err := db.Redis.LRange(context.Background(), project.ProjectItemsKey(order.ProjectID), 0, -1).Result()
+	if err != nil {
+		t.Fatalf("load stock: %v", err)
+	}
+	for _, value := range values {
+		if value == fmt.Sprint(order.ItemID) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSettlePaidOrder(t *testing.T) {
+	fake := setupPaymentStore(t)
+	ctx := context.Background()
+	merchant := Merchant{ClientID: "m1", ClientSecret: "SECRET"}
+
+	order := createReservedOrderFixture(t, "CDK-SETTLE", OrderStatusPending, time.Now().Add(time.Minute))
+	if ok, reason := settlePaidOrder(ctx, fake, merchant, order, "T-SETTLE"); !ok || reason != "ok" {
+		t.Fatalf("want settled, got ok=%v reason=%s", ok, reason)
+	}
+	settled := reloadOrder(t, order.OutTradeNo)
+	if settled.Status != OrderStatusCompleted || settled.TradeNo != "T-SETTLE" || settled.PaidAt == nil {
+		t.Fatalf("unexpected order after settle: %+v", settled)
+	}
+	var item project.ProjectItem
+	db.DB(ctx).First(&item, order.ItemID)
+	if item.ReceiverID == nil || *item.ReceiverID != testPayerID {
+		t.Fatalf("want item delivered to payer, got %+v", item.ReceiverID)
+	}
+
+	// 重复回调幂等
+	if ok, reason := settlePaidOrder(ctx, fake, merchant, settled, "T-SETTLE"); !ok || reason != "idempotent" {
+		t.Fatalf("want idempotent, got ok=%v reason=%s", ok, reason)
+	}
+
+	// 本地已关闭的订单登记差异
+	closed := createReservedOrderFixture(t, "CDK-CLOSED", OrderStatusFailed, time.Now().Add(-time.Minute))
+	if ok, _ := settlePaidOrder(ctx, fake, merchant, closed, "T-CLOSED"); !ok {
+		t.Fatal("want paid-but-failed acknowledged")
+	}
+	if records := discrepancies(t, closed.OutTradeNo); len(records) != 1 || records[0].Kind != DiscrepancyPaidButFailed {
+		t.Fatalf("want paid_but_failed discrepancy, got %+v", records)
+	}
+	if len(fake.refunds) != 0 {
+		t.Fatalf("unexpected refunds %+v", fake.refunds)
+	}
+}
+
+func TestSettlePaidOrderRefundsWhenFulfillFails(t *testing.T) {
+	fake := setupPaymentStore(t)
+	ctx := context.Background()
+
+	order := createReservedOrderFixture(t, "CDK-FULFILL", OrderStatusPending, time.Now().Add(time.Minute))
+	// 付款方已达到领取上限，发放失败
+	if err := db.DB(ctx).Create(&project.ProjectClaim{ProjectID: order.ProjectID, UserID: testPayerID, Seq: 1, ItemID: 999}).Error; err != nil {
+		t.Fatalf("create claim: %v", err)
+	}
+
+	if ok, _ := settlePaidOrder(ctx, fake, Merchant{ClientID: "m1", ClientSecret: "SECRET"}, order, "T-FULFILL"); ok {
+		t.Fatal("want gateway retry after failed fulfill")
+	}
+	refunded := reloadOrder(t, order.OutTradeNo)
+	if refunded.Status != OrderStatusRefunded || refunded.RefundedAt == nil || refunded.FailReason == "" {
+		t.Fatalf("unexpected order after failed fulfill: %+v", refunded)
+	}
+	if len(fake.refunds) != 1 || fake.refunds[0].TradeNo != "T-FULFILL" || fake.refunds[0].Money != "10" {
+		t.Fatalf("unexpected refunds %+v", fake.refunds)
+	}
+	if !itemInStock(t, order) {
+		t.Fatal("want reserved item returned to stock")
+	}
+}
+
+func TestRecordDiscrepancyReopensExisting(t *testing.T) {
+	setupPaymentStore(t)
+	ctx := context.Background()
+
+	order := &PaymentOrder{OutTradeNo: "CDK-DISC", ProjectID: "p1", PayerID: testPayerID, PayeeID: testPayeeID, Status: OrderStatusPending}
+	recordDiscrepancy(ctx, order, DiscrepancyQueryFailed, "", "timeout")
+
+	now := time.Now()
+	resolver := uint64(9)
+	db.DB(ctx).Model(&PaymentDiscrepancy{}).Where("out_trade_no = ?", order.OutTradeNo).
+		Updates(map[string]interface{}{"resolved_at": &now, "resolved_by": resolver})
+
+	order.Status = OrderStatusFailed
+	recordDiscrepancy(ctx, order, DiscrepancyQueryFailed, "T1", "still failing")
+	recordDiscrepancy(ctx, order, DiscrepancyMismatch, "T1", "money mismatch")
+
+	records := discrepancies(t, order.OutTradeNo)
+	if len(records) != 2 {
+		t.Fatalf("want one record per kind, got %+v", records)
+	}
+	for _, record := range records {
+		if record.Kind != DiscrepancyQueryFailed {
+			continue
+		}
+		if record.ResolvedAt != nil || record.ResolvedBy != nil || record.Detail != "still failing" ||
+			record.TradeNo != "T1" || record.LocalStatus != OrderStatusFailed {
+			t.Fatalf("want reopened and refreshed discrepancy, got %+v", record)
+		}
+	}
+}
+
+func TestHandleReconcileOrders(t *testing.T) {
+	fake := setupPaymentStore(t)
+	ctx := context.Background()
+	expired := time.Now().Add(-time.Minute)
+
+	paid := createReservedOrderFixture(t, "CDK-PAID", OrderStatusPending, time.Now().Add(time.Minute))
+	fake.orders[paid.OutTradeNo] = &OrderQueryResult{OutTradeNo: paid.OutTradeNo, TradeNo: "T-PAID", Money: "10", Paid: true}
+	// 用户放弃支付，渠道中不存在订单
+	abandoned := createReservedOrderFixture(t, "CDK-ABANDONED", OrderStatusPending, expired)
+	unpaid := createReservedOrderFixture(t, "CDK-UNPAID", OrderStatusPending, expired)
+	fake.orders[unpaid.OutTradeNo] = &OrderQueryResult{OutTradeNo: unpaid.OutTradeNo, Money: "10"}
+	mismatch := createReservedOrderFixture(t, "CDK-MISMATCH", OrderStatusPending, expired)
+	fake.orders[mismatch.OutTradeNo] = &OrderQueryResult{OutTradeNo: mismatch.OutTradeNo, TradeNo: "T-MISMATCH", Money: "1", Paid: true}
+	unreachable := createReservedOrderFixture(t, "CDK-UNREACHABLE", OrderStatusPending, expired)
+	fake.queryErrs[unreachable.OutTradeNo] = errors.New("connection reset")
+	refunding := createReservedOrderFixture(t, "CDK-REFUNDING", OrderStatusRefunding, expired)
+
+	if err := HandleReconcileOrders(ctx, nil); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	if got := reloadOrder(t, paid.OutTradeNo); got.Status != OrderStatusCompleted || got.TradeNo != "T-PAID" {
+		t.Fatalf("want paid order settled, got %+v", got)
+	}
+	for _, order := range []*PaymentOrder{abandoned, unpaid} {
+		if got := reloadOrder(t, order.OutTradeNo); got.Status != OrderStatusPending {
+			t.Fatalf("%s: want left pending for cleanup, got %d", order.OutTradeNo, got.Status)
+		}
+		if records := discrepancies(t, order.OutTradeNo); len(records) != 0 {
+			t.Fatalf("%s: unpaid order must not record discrepancy, got %+v", order.OutTradeNo, records)
+		}
+	}
+	if records := discrepancies(t, mismatch.OutTradeNo); len(records) != 1 || records[0].Kind != DiscrepancyMismatch {
+		t.Fatalf("want mismatch discrepancy, got %+v", records)
+	}
+	if records := discrepancies(t, unreachable.OutTradeNo); len(records) != 1 || records[0].Kind != DiscrepancyQueryFailed {
+		t.Fatalf("want query_failed discrepancy for transport error, got %+v", records)
+	}
+	if got := reloadOrder(t, refunding.OutTradeNo); got.Status != OrderStatusRefunded || got.RefundedAt == nil {
+		t.Fatalf("want refunding order refunded, got %+v", got)
+	}
+	if len(fake.refunds) != 1 || fake.refunds[0].OutTradeNo != refunding.OutTradeNo {
+		t.Fatalf("unexpected refunds %+v", fake.refunds)
+	}
+	if !itemInStock(t, refunding) {
+		t.Fatal("want refunded item returned to stock")
+	}
+}
+
+func TestReconcileRefundBackoff(t *testing.T) {
+	fake := setupPaymentStore(t)
+	ctx := context.Background()
+	fake.refundErr = errors.New("gateway unavailable")
+	order := createReservedOrderFixture(t, "CDK-REFUND-BACKOFF", OrderStatusRefunding, time.Now().Add(-time.Minute))
+
+	// 失败后记录次数并推迟下次重试，未到时间的订单不再重复退款
+	if err := HandleReconcileOrders(ctx, nil); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	got := reloadOrder(t, order.OutTradeNo)
+	if got.Status != OrderStatusRefunding || got.RefundAttempts != 1 || got.NextRefundAt == nil ||
+		got.NextRefundAt.Before(time.Now().Add(refundRetryBaseDelay/2)) {
+		t.Fatalf("want first attempt recorded with backoff, got %+v", got)
+	}
+	if err := HandleReconcileOrders(ctx, nil); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if len(fake.refunds) != 1 {
+		t.Fatalf("want no retry before backoff elapses, got %d refunds", len(fake.refunds))
+	}
+	if records := discrepancies(t, order.OutTradeNo); len(records) != 0 {
+		t.Fatalf("want no discrepancy before retries are exhausted, got %+v", records)
+	}
+
+	// 退避间隔逐次翻倍
+	got.NextRefundAt = nil
+	for attempts := got.RefundAttempts; attempts < maxRefundAttempts-1; attempts++ {
+		before := time.Now()
+		reconcileRefundingOrder(ctx, got)
+		got = reloadOrder(t, order.OutTradeNo)
+		if got.RefundAttempts != attempts+1 || got.NextRefundAt.Before(before.Add(refundRetryDelay(attempts+1))) {
+			t.Fatalf("attempt %d: want backoff %s, got %+v", attempts+1, refundRetryDelay(attempts+1), got)
+		}
+	}
+	if refundRetryDelay(2) != 2*refundRetryBaseDelay || refundRetryDelay(3) != 4*refundRetryBaseDelay {
+		t.Fatal("want exponential backoff")
+	}
+
+	// 重试耗尽后进入 REFUND_FAILED 并登记差异，不再被对账任务选中
+	reconcileRefundingOrder(ctx, got)
+	got = reloadOrder(t, order.OutTradeNo)
+	if got.Status != OrderStatusRefundFailed || got.RefundAttempts != maxRefundAttempts || got.NextRefundAt != nil {
+		t.Fatalf("want terminal refund failure, got %+v", got)
+	}
+	if records := discrepancies(t, order.OutTradeNo); len(records) != 1 || records[0].Kind != DiscrepancyRefundFailed {
+		t.Fatalf("want refund_failed discrepancy, got %+v", records)
+	}
+	refunds := len(fake.refunds)
+	if err := HandleReconcileOrders(ctx, nil); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if len(fake.refunds) != refunds {
+		t.Fatal("want no automatic retry after refund failed")
+	}
+
+	// 管理员重新退款：仍失败时重新进入退避重试，成功后完成退款
+	if _, err := RetryRefund(ctx, "CDK-REFUND-MISSING"); !errors.Is(err, ErrOrderNotFoundSentinel) {
+		t.Fatalf("want missing order rejected, got %v", err)
+	}
+	retried, err := RetryRefund(ctx, order.OutTradeNo)
+	if err != nil {
+		t.Fatalf("retry refund: %v", err)
+	}
+	if retried.Status != OrderStatusRefunding || retried.RefundAttempts != 1 || retried.NextRefundAt == nil {
+		t.Fatalf("want failed retry back in backoff, got %+v", retried)
+	}
+	if _, err := RetryRefund(ctx, order.OutTradeNo); err == nil || err.Error() != ErrRefundRetryNotAllowed {
+		t.Fatalf("want retry of refunding order rejected, got %v", err)
+	}
+	fake.refundErr = nil
+	if err := db.DB(ctx).Model(&PaymentOrder{}).Where("out_trade_no = ?", order.OutTradeNo).
+		Update("next_refund_at", time.Now().Add(-time.Second)).Error; err != nil {
+		t.Fatalf("expire backoff: %v", err)
+	}
+	if err := HandleReconcileOrders(ctx, nil); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if got := reloadOrder(t, order.OutTradeNo); got.Status != OrderStatusRefunded || got.RefundedAt == nil {
+		t.Fatalf("want order refunded once backoff elapses, got %+v", got)
+	}
+	if !itemInStock(t, order) {
+		t.Fatal("want refunded item returned to stock")
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package payment
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/logger"
+	"gorm.io/gorm"
+)
+
+// errRefundPending 渠道退款暂未成功，订单停留在 REFUNDING 由对账任务重试
+var errRefundPending = errors.New(ErrRefundPending)
+
+// RefundOrder 由收款方或管理员对已完成订单发起全额退款。
+// 流程:CAS COMPLETED → REFUNDING(记录发起人、原因与是否收回 item) → 调用渠道退款 → CAS REFUNDING → REFUNDED。
+// 渠道退款失败时订单保持 REFUNDING,由对账任务继续重试，返回 errRefundPending。
+func RefundOrder(ctx context.Context, outTradeNo string, initiator *oauth.User, reason string, revokeItem bool) (*PaymentOrder, error) {
+	var order PaymentOrder
+	if err := db.DB(ctx).Where("out_trade_no = ?", outTradeNo).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFoundSentinel
+		}
+		return nil, err
+	}
+	// 非收款方且非管理员时与订单不存在返回相同错误，避免探测订单号
+	if order.PayeeID != initiator.ID && !initiator.IsAdmin {
+		return nil, ErrOrderNotFoundSentinel
+	}
+	provider, merchant, err := orderMerchant(ctx, &order)
+	if err != nil {
+		return nil, err
+	}
+
+	// CAS: COMPLETED -> REFUNDING
+	result := db.DB(ctx).Model(&PaymentOrder{}).
+		Where("out_trade_no = ? AND status = ?", outTradeNo, OrderStatusCompleted).
+		Updates(map[string]any{
+			"status":        OrderStatusRefunding,
+			"refund_by":     initiator.ID,
+			"refund_reason": reason,
+			"revoke_item":   revokeItem,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(ErrRefundNotAllowed)
+	}
+	order.Status = OrderStatusRefunding
+	order.RefundBy = &initiator.ID
+	order.RefundReason = reason
+	order.RevokeItem = revokeItem
+	logger.InfoF(ctx, "payment refund: order %s refund initiated by %d, revoke item: %v", outTradeNo, initiator.ID, revokeItem)
+
+	if err := provider.Refund(ctx, merchant, order.refundRequest()); err != nil {
+		logger.ErrorF(ctx, "payment refund: order %s refund request failed: %v", outTradeNo, err)
+		return &order, errRefundPending
+	}
+
+	// CAS: REFUNDING -> REFUNDED
+	now := time.Now()
+	if _, err := markOrderRefundedAndReturnItem(ctx, &order, map[string]any{"status": OrderStatusRefunded, "refunded_at": &now}, OrderStatusRefunding); err != nil {
+		return &order, err
+	}
+	order.Status = OrderStatusRefunded
+	order.RefundedAt = &now
+	return &order, nil
+}
+
+// RetryRefund 管理员确认后重新退款自动重试耗尽的订单。
+// 流程:CAS REFUND_FAILED → REFUNDING(清零重试次数) → 立即按对账流程重试一次，仍失败时重新进入退避重试。
+func RetryRefund(ctx context.Context, outTradeNo string) (*PaymentOrder, error) {
+	var order PaymentOrder
+	if err := db.DB(ctx).Where("out_trade_no = ?", outTradeNo).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFoundSentinel
+		}
+		return nil, err
+	}
+
+	// CAS: REFUND_FAILED -> REFUNDING
+	result := db.DB(ctx).Model(&PaymentOrder{}).
+		Where("out_trade_no = ? AND status = ?", outTradeNo, OrderStatusRefundFailed).
+		Updates(map[string]any{"status": OrderStatusRefunding, "refund_attempts": 0, "next_refund_at": nil})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(ErrRefundRetryNotAllowed)
+	}
+	order.Status = OrderStatusRefunding
+	order.RefundAttempts = 0
+	order.NextRefundAt = nil
+	logger.InfoF(ctx, "payment refund: order %s refund retry requested by admin", outTradeNo)
+
+	reconcileRefundingOrder(ctx, &order)
+	if err := db.DB(ctx).Where("out_trade_no = ?", outTradeNo).First(&order).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
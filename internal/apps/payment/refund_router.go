@@ -0,0 +1,101 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package payment
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/cdk/internal/apps/oauth"
+)
+
+type RefundOrderRequest struct {
+	Reason     string `json:"reason" binding:"required,min=1,max=255"`
+	RevokeItem bool   `json:"revoke_item"`
+}
+
+// RefundOrderHTTP 收款方或管理员对已完成订单发起退款
+// @Tags payment
+// @Accept json
+// @Produce json
+// @Param out_trade_no path string true "订单号"
+// @Param request body RefundOrderRequest true "退款原因及是否收回并作废已发放的 item"
+// @Success 200 {object} Response{data=PaymentOrder}
+// @Success 202 {object} Response{data=PaymentOrder}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Router /api/v1/payment/orders/{out_trade_no}/refund [post]
+func RefundOrderHTTP(c *gin.Context) {
+	var req RefundOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{ErrorMsg: err.Error()})
+		return
+	}
+
+	user, _ := oauth.GetUserFromContext(c)
+	order, err := RefundOrder(c.Request.Context(), c.Param("out_trade_no"), user, req.Reason, req.RevokeItem)
+	if err != nil {
+		switch {
+		case errors.Is(err, errRefundPending):
+			// 渠道暂未确认，订单已进入 REFUNDING,由对账任务继续重试
+			c.JSON(http.StatusAccepted, Response{ErrorMsg: err.Error(), Data: order})
+		case errors.Is(err, ErrOrderNotFoundSentinel):
+			c.JSON(http.StatusNotFound, Response{ErrorMsg: err.Error()})
+		case err.Error() == ErrRefundNotAllowed:
+			c.JSON(http.StatusBadRequest, Response{ErrorMsg: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, Response{ErrorMsg: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Data: order})
+}
+
+// RetryRefundHTTP 管理员对自动退款重试耗尽的订单重新发起退款
+// @Tags admin
+// @Produce json
+// @Param out_trade_no path string true "订单号"
+// @Success 200 {object} Response{data=PaymentOrder}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Router /api/v1/admin/payment/orders/{out_trade_no}/retry-refund [post]
+func RetryRefundHTTP(c *gin.Context) {
+	order, err := RetryRefund(c.Request.Context(), c.Param("out_trade_no"))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrOrderNotFoundSentinel):
+			c.JSON(http.StatusNotFound, Response{ErrorMsg: err.Error()})
+		case err.Error() == ErrRefundRetryNotAllowed:
+			c.JSON(http.StatusBadRequest, Response{ErrorMsg: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, Response{ErrorMsg: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Data: order})
+}
@@ -0,0 +1,120 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/apps/project"
+	"github.com/linux-do/cdk/internal/db"
+)
+
+// createCompletedOrderFixture 创建已完成的订单,item 已发放给付款方
+func createCompletedOrderFixture(t *testing.T, outTradeNo string) *PaymentOrder {
+	t.Helper()
+	ctx := context.Background()
+	order := createReservedOrderFixture(t, outTradeNo, OrderStatusCompleted, time.Now().Add(-time.Minute))
+	now := time.Now()
+	if err := db.DB(ctx).Model(&project.ProjectItem{}).Where("id = ?", order.ItemID).
+		Updates(map[string]interface{}{"receiver_id": order.PayerID, "received_at": &now}).Error; err != nil {
+		t.Fatalf("deliver item: %v", err)
+	}
+	if err := db.DB(ctx).Create(&project.ProjectClaim{ProjectID: order.ProjectID, UserID: order.PayerID, Seq: 1, ItemID: order.ItemID}).Error; err != nil {
+		t.Fatalf("create claim: %v", err)
+	}
+	return order
+}
+
+func TestRefundOrder(t *testing.T) {
+	fake := setupPaymentStore(t)
+	ctx := context.Background()
+	payee := &oauth.User{ID: testPayeeID}
+
+	cases := []struct {
+		name       string
+		outTradeNo string
+		revokeItem bool
+	}{
+		{"keep item", "CDK-REFUND-KEEP", false},
+		{"revoke item", "CDK-REFUND-REVOKE", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			order := createCompletedOrderFixture(t, tc.outTradeNo)
+
+			refunded, err := RefundOrder(ctx, order.OutTradeNo, payee, "买家反馈无法使用", tc.revokeItem)
+			if err != nil {
+				t.Fatalf("refund: %v", err)
+			}
+			if refunded.Status != OrderStatusRefunded {
+				t.Fatalf("want refunded, got %d", refunded.Status)
+			}
+			stored := reloadOrder(t, order.OutTradeNo)
+			if stored.Status != OrderStatusRefunded || stored.RefundBy == nil || *stored.RefundBy != testPayeeID || stored.RevokeItem != tc.revokeItem {
+				t.Fatalf("unexpected stored order %+v", stored)
+			}
+			if last := fake.refunds[len(fake.refunds)-1]; last.OutTradeNo != order.OutTradeNo {
+				t.Fatalf("want gateway refund for %s, got %+v", order.OutTradeNo, last)
+			}
+
+			var item project.ProjectItem
+			db.DB(ctx).First(&item, order.ItemID)
+			var claims int64
+			db.DB(ctx).Model(&project.ProjectClaim{}).Where("item_id = ?", order.ItemID).Count(&claims)
+			var p project.Project
+			db.DB(ctx).First(&p, "id = ?", order.ProjectID)
+
+			// 无论是否收回，已发放的 item 都不能回到公开库存
+			if itemInStock(t, order) {
+				t.Fatal("refunded item must not be returned to stock")
+			}
+			if !tc.revokeItem {
+				if item.ReceiverID == nil || item.RevokedAt != nil || claims != 1 || p.TotalItems != 1 {
+					t.Fatalf("want item kept by payer, got item=%+v claims=%d total=%d", item, claims, p.TotalItems)
+				}
+				return
+			}
+			if item.ReceiverID != nil || item.RevokedAt == nil || claims != 0 || p.TotalItems != 0 {
+				t.Fatalf("want item revoked and removed from totals, got item=%+v claims=%d total=%d", item, claims, p.TotalItems)
+			}
+		})
+	}
+
+	// 非收款方且非管理员与订单不存在的返回一致
+	order := createCompletedOrderFixture(t, "CDK-REFUND-DENIED")
+	if _, err := RefundOrder(ctx, order.OutTradeNo, &oauth.User{ID: testPayerID}, "x", true); !errors.Is(err, ErrOrderNotFoundSentinel) {
+		t.Fatalf("want %v for non-owner, got %v", ErrOrderNotFoundSentinel, err)
+	}
+	if _, err := RefundOrder(ctx, "CDK-REFUND-MISSING", &oauth.User{ID: testPayerID}, "x", true); !errors.Is(err, ErrOrderNotFoundSentinel) {
+		t.Fatalf("want %v for missing order, got %v", ErrOrderNotFoundSentinel, err)
+	}
+	if reloadOrder(t, order.OutTradeNo).Status != OrderStatusCompleted {
+		t.Fatal("want order untouched by non-owner refund")
+	}
+}
@@ -26,13 +26,16 @@ package payment
 
 import (
 	"errors"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/linux-do/cdk/internal/apps/oauth"
 	"github.com/linux-do/cdk/internal/apps/project"
 	"github.com/linux-do/cdk/internal/config"
 	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/logger"
 	"gorm.io/gorm"
 )
 
@@ -44,12 +47,15 @@ type Response struct {
 
 // GetPaymentConfigResponseData 当前用户支付配置的安全视图
 type GetPaymentConfigResponseData struct {
-	HasConfig         bool   `json:"has_config"`
-	ClientID          string `json:"client_id"`
-	SecretLast4       string `json:"secret_last4"`
-	CallbackNotifyURL string `json:"callback_notify_url"`
-	CallbackReturnURL string `json:"callback_return_url"`
-	PaymentEnabled    bool   `json:"payment_enabled"`
+	HasConfig         bool         `json:"has_config"`
+	Provider          ProviderType `json:"provider"`
+	Currency          Currency     `json:"currency"`
+	ClientID          string       `json:"client_id"`
+	SecretLast4       string       `json:"secret_last4"`
+	CallbackNotifyURL string       `json:"callback_notify_url"`
+	CallbackReturnURL string       `json:"callback_return_url"`
+	PaymentEnabled    bool         `json:"payment_enabled"`
+	VerifiedAt        *time.Time   `json:"verified_at"`
 }
 
 // GetPaymentConfig GET /api/v1/users/payment-config
@@ -61,24 +67,34 @@ func GetPaymentConfig(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, Response{ErrorMsg: err.Error()})
 		return
 	}
-	notifyURL, returnURL := CallbackURLs()
+	providerType := ProviderTypeEpay
+	if cfg != nil {
+		providerType = cfg.providerType()
+	}
+	notifyURL, returnURL := CallbackURLs(providerType)
 	resp := GetPaymentConfigResponseData{
+		Provider:          providerType,
 		CallbackNotifyURL: notifyURL,
 		CallbackReturnURL: returnURL,
 		PaymentEnabled:    config.Config.Payment.Enabled,
 	}
 	if cfg != nil {
 		resp.HasConfig = true
+		resp.Currency = cfg.currency()
 		resp.ClientID = cfg.ClientID
 		resp.SecretLast4 = cfg.SecretLast4
+		resp.VerifiedAt = cfg.VerifiedAt
 	}
 	c.JSON(http.StatusOK, Response{Data: resp})
 }
 
-// UpsertPaymentConfigRequest PUT 请求体
+// UpsertPaymentConfigRequest PUT 请求体,Currency 为结算币种，留空为渠道默认币种
 type UpsertPaymentConfigRequest struct {
-	ClientID     string `json:"client_id" binding:"required,min=1,max=64"`
-	ClientSecret string `json:"client_secret" binding:"required,min=1,max=256"`
+	// Provider 支付渠道，留空为易支付
+	Provider     ProviderType `json:"provider" binding:"max=16"`
+	ClientID     string       `json:"client_id" binding:"required,min=1,max=64"`
+	ClientSecret string       `json:"client_secret" binding:"required,min=1,max=256"`
+	Currency     Currency     `json:"currency" binding:"omitempty,oneof=LDC CNY CREDIT"`
 }
 
 // UpsertPaymentConfig PUT /api/v1/users/payment-config
@@ -93,13 +109,40 @@ func UpsertPaymentConfig(c *gin.Context) {
 		return
 	}
 	userID := oauth.GetUserIDFromContext(c)
-	if err := SaveUserPaymentConfig(c.Request.Context(), userID, req.ClientID, req.ClientSecret); err != nil {
+	if err := SaveUserPaymentConfig(c.Request.Context(), userID, req.Provider, req.Currency, req.ClientID, req.ClientSecret); err != nil {
+		if err.Error() == ErrMerchantVerifyFailed || err.Error() == ErrInvalidClientCredentials ||
+			err.Error() == ErrCurrencyUnsupported || err.Error() == ErrCurrencyChangeHasActive {
+			c.JSON(http.StatusBadRequest, Response{ErrorMsg: err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, Response{ErrorMsg: err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, Response{})
 }
 
+// VerifyPaymentConfig POST /api/v1/users/payment-config/verify
+// @Summary 使用已保存的支付凭据重新向渠道校验,成功后刷新 verified_at
+func VerifyPaymentConfig(c *gin.Context) {
+	if !config.Config.Payment.Enabled {
+		c.JSON(http.StatusForbidden, Response{ErrorMsg: ErrPaymentDisabled})
+		return
+	}
+	cfg, err := VerifyUserPaymentConfig(c.Request.Context(), oauth.GetUserIDFromContext(c))
+	if err != nil {
+		switch err.Error() {
+		case ErrPaymentConfigNotFound:
+			c.JSON(http.StatusNotFound, Response{ErrorMsg: err.Error()})
+		case ErrMerchantVerifyFailed:
+			c.JSON(http.StatusBadRequest, Response{ErrorMsg: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, Response{ErrorMsg: err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, Response{Data: cfg.VerifiedAt})
+}
+
 // DeletePaymentConfig DELETE /api/v1/users/payment-config
 func DeletePaymentConfig(c *gin.Context) {
 	userID := oauth.GetUserIDFromContext(c)
@@ -117,6 +160,7 @@ type ReceiveResponse struct {
 	PayURL         string `json:"pay_url,omitempty"`
 	OutTradeNo     string `json:"out_trade_no,omitempty"`
 	Amount         string `json:"amount,omitempty"`
+	Currency       string `json:"currency,omitempty"`
 	ExpireAt       string `json:"expire_at,omitempty"`
 }
 
@@ -125,6 +169,7 @@ type PendingPaymentResponseData struct {
 	HasPending bool   `json:"has_pending"`
 	PayURL     string `json:"pay_url,omitempty"`
 	Amount     string `json:"amount,omitempty"`
+	Currency   string `json:"currency,omitempty"`
 }
 
 // GetPendingPayment
@@ -165,13 +210,15 @@ func GetPendingPayment(c *gin.Context) {
 		HasPending: true,
 		PayURL:     init.PayURL,
 		Amount:     init.Amount,
+		Currency:   string(init.Currency),
 	}})
 }
 
 // DispatchReceive POST /api/v1/projects/:id/receive
 // 运行在 project.ReceiveProjectMiddleware() 之后,已通过资格校验并在 context 注入 project。
 // 付费项目:返回 {require_payment:true, pay_url, ...};前端直接跳转 pay_url。
-// 免费项目:执行原领取事务,返回 {itemContent}。
+// 优惠码抵扣全额时与免费项目一致直接返回 {itemContent}。
+// 免费项目:执行原领取事务,返回 {itemContent};邀请码项目在同一事务内核销邀请码。
 func DispatchReceive(c *gin.Context) {
 	ctx := c.Request.Context()
 	currentUser, _ := oauth.GetUserFromContext(c)
@@ -187,20 +234,40 @@ func DispatchReceive(c *gin.Context) {
 
 	// 付费分叉
 	if p.IsPaid() {
-		init, err := InitiatePayment(ctx, p, currentUser, c.ClientIP())
+		req, err := project.GetReceiveRequestFromContext(c)
 		if err != nil {
-			if err.Error() == ErrPendingOrderExists {
+			c.JSON(http.StatusBadRequest, Response{ErrorMsg: err.Error()})
+			return
+		}
+		init, err := InitiatePayment(ctx, p, currentUser, c.ClientIP(), req.CouponCode)
+		if err != nil {
+			if err.Error() == ErrPendingOrderExists || err.Error() == project.ReceiveLimitReached || err.Error() == ErrCouponInvalid {
 				c.JSON(http.StatusBadRequest, Response{ErrorMsg: err.Error()})
 				return
 			}
 			c.JSON(http.StatusInternalServerError, Response{ErrorMsg: err.Error()})
 			return
 		}
+		if init.ItemID > 0 {
+			var item project.ProjectItem
+			if err := item.Exact(db.DB(ctx), init.ItemID); err != nil {
+				c.JSON(http.StatusInternalServerError, project.ProjectResponse{ErrorMsg: err.Error()})
+				return
+			}
+			content, err := item.PlainContent()
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, project.ProjectResponse{ErrorMsg: err.Error()})
+				return
+			}
+			c.JSON(http.StatusOK, project.ProjectResponse{Data: ReceiveResponse{ItemContent: content}})
+			return
+		}
 		c.JSON(http.StatusOK, project.ProjectResponse{Data: ReceiveResponse{
 			RequirePayment: true,
 			PayURL:         init.PayURL,
 			OutTradeNo:     init.OutTradeNo,
 			Amount:         init.Amount,
+			Currency:       string(init.Currency),
 			ExpireAt:       init.ExpireAt.Format("2006-01-02 15:04:05"),
 		}})
 		return
@@ -219,27 +286,67 @@ func DispatchReceive(c *gin.Context) {
 		c.JSON(http.StatusNotFound, project.ProjectResponse{ErrorMsg: err.Error()})
 		return
 	}
+	invite, _ := project.GetInviteFromContext(c)
 	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		// 邀请码与 item 在同一事务内核销
+		if invite != nil {
+			if err := p.ConsumeInvite(ctx, tx, invite, currentUser.ID); err != nil {
+				return err
+			}
+		}
 		return p.FulfillForReceiver(ctx, tx, &item, currentUser.ID, c.ClientIP())
 	}); err != nil {
-		if p.DistributionType == project.DistributionTypeOneForEach {
+		if !p.IsWinnerBased() {
 			db.Redis.RPush(ctx, p.ItemsKey(), itemID)
 		}
 		c.JSON(http.StatusInternalServerError, project.ProjectResponse{ErrorMsg: err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, project.ProjectResponse{Data: ReceiveResponse{ItemContent: item.Content}})
+	// 事务提交后再删除邀请码索引，回滚时邀请码仍可再次使用
+	if invite != nil {
+		if err := p.DropInviteIndex(ctx, invite.Token); err != nil {
+			logger.ErrorF(ctx, "[DispatchReceive] 清理邀请码[%d]索引失败: %v", invite.ID, err)
+		}
+	}
+	content, err := item.PlainContent()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, project.ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, project.ProjectResponse{Data: ReceiveResponse{ItemContent: content}})
 }
 
 // HandleNotifyHTTP GET /api/v1/payment/notify
 // 易支付兼容回调,返回纯文本 "success" / "fail"。
 func HandleNotifyHTTP(c *gin.Context) {
-	ok, _ := HandleNotify(c.Request.Context(), extractQueryMap(c.Request.URL.Query()))
-	if ok {
-		c.String(http.StatusOK, "success")
-	} else {
-		c.String(http.StatusOK, "fail")
+	handleProviderNotify(c, ProviderTypeEpay)
+}
+
+// HandleProviderNotifyHTTP GET/POST /api/v1/payment/notify/:provider
+// 按渠道类型分发的回调入口，响应格式由渠道决定。
+func HandleProviderNotifyHTTP(c *gin.Context) {
+	handleProviderNotify(c, ProviderType(c.Param("provider")))
+}
+
+// handleProviderNotify 读取原始回调请求，经来源与重放校验后交由 HandleNotify 处理并记录审计日志
+func handleProviderNotify(c *gin.Context, providerType ProviderType) {
+	provider, err := GetProvider(providerType)
+	if err != nil {
+		c.String(http.StatusNotFound, err.Error())
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, notifyBodyLimit))
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
 	}
+	ok := processNotify(c.Request.Context(), provider, &NotifyRequest{
+		Query:  c.Request.URL.Query(),
+		Header: c.Request.Header,
+		Body:   body,
+	}, c.Request.URL.RawQuery, c.ClientIP())
+	status, ack := provider.NotifyAck(ok)
+	c.String(status, ack)
 }
 
 // 保留 GORM ErrRecordNotFound 的判断以防将来需要细分
@@ -34,6 +34,7 @@ import (
 
 	"github.com/linux-do/cdk/internal/apps/oauth"
 	"github.com/linux-do/cdk/internal/apps/project"
+	"github.com/linux-do/cdk/internal/apps/webhook"
 	"github.com/linux-do/cdk/internal/config"
 	"github.com/linux-do/cdk/internal/db"
 	"github.com/linux-do/cdk/internal/logger"
@@ -56,14 +57,30 @@ func GetUserPaymentConfig(ctx context.Context, userID uint64) (*UserPaymentConfi
 }
 
 // SaveUserPaymentConfig 保存/更新用户的支付凭据,clientSecret 明文进入后会被加密。
-func SaveUserPaymentConfig(ctx context.Context, userID uint64, clientID, clientSecret string) error {
+// 保存前先以凭据向渠道发起一次鉴权调用，校验失败时不落库。
+// currency 留空时使用渠道的默认币种;存在未结束的付费项目时不允许变更币种，避免存量项目无法下单。
+func SaveUserPaymentConfig(ctx context.Context, userID uint64, providerType ProviderType, currency Currency, clientID, clientSecret string) error {
 	if clientID == "" || clientSecret == "" {
 		return errors.New(ErrInvalidClientCredentials)
 	}
+	provider, err := GetProvider(providerType)
+	if err != nil {
+		return err
+	}
+	if currency == "" {
+		currency = provider.Currencies()[0]
+	}
+	if !supportsCurrency(provider, currency) {
+		return errors.New(ErrCurrencyUnsupported)
+	}
 	if err := validateEncryptionKeyConfigured(); err != nil {
 		return err
 	}
-	enc, err := EncryptSecret(clientSecret, config.Config.Payment.ConfigEncryptionKey)
+	if err := verifyMerchant(ctx, provider, Merchant{ClientID: clientID, ClientSecret: clientSecret}, userID); err != nil {
+		return err
+	}
+	verifiedAt := time.Now()
+	enc, err := encryptClientSecret(clientSecret)
 	if err != nil {
 		return err
 	}
@@ -75,28 +92,71 @@ func SaveUserPaymentConfig(ctx context.Context, userID uint64, clientID, clientS
 	queryErr := db.DB(ctx).Where("user_id = ?", userID).First(cfg).Error
 	if errors.Is(queryErr, gorm.ErrRecordNotFound) {
 		cfg.UserID = userID
+		cfg.Provider = provider.Type()
+		cfg.Currency = currency
 		cfg.ClientID = clientID
 		cfg.ClientSecretEnc = enc
 		cfg.SecretLast4 = last4
+		cfg.VerifiedAt = &verifiedAt
 		return db.DB(ctx).Create(cfg).Error
 	} else if queryErr != nil {
 		return queryErr
 	}
+	if cfg.currency() != currency {
+		active, err := countActivePaidProjects(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if active > 0 {
+			return errors.New(ErrCurrencyChangeHasActive)
+		}
+	}
+	cfg.Provider = provider.Type()
+	cfg.Currency = currency
 	cfg.ClientID = clientID
 	cfg.ClientSecretEnc = enc
 	cfg.SecretLast4 = last4
+	cfg.VerifiedAt = &verifiedAt
 	return db.DB(ctx).Save(&cfg).Error
 }
 
+// VerifyUserPaymentConfig 以已保存的凭据重新向渠道校验，成功后刷新 VerifiedAt
+func VerifyUserPaymentConfig(ctx context.Context, userID uint64) (*UserPaymentConfig, error) {
+	cfg, err := GetUserPaymentConfig(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, errors.New(ErrPaymentConfigNotFound)
+	}
+	provider, merchant, err := merchantOf(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyMerchant(ctx, provider, merchant, userID); err != nil {
+		return nil, err
+	}
+	verifiedAt := time.Now()
+	if err := db.DB(ctx).Model(cfg).Update("verified_at", &verifiedAt).Error; err != nil {
+		return nil, err
+	}
+	cfg.VerifiedAt = &verifiedAt
+	return cfg, nil
+}
+
+// verifyMerchant 调用渠道校验凭据，渠道返回的详细原因仅记录日志
+func verifyMerchant(ctx context.Context, provider PaymentProvider, merchant Merchant, userID uint64) error {
+	if err := provider.VerifyMerchant(ctx, merchant); err != nil {
+		logger.WarnF(ctx, "Payment credentials verification failed for user %d: %v", userID, err)
+		return errors.New(ErrMerchantVerifyFailed)
+	}
+	return nil
+}
+
 // DeleteUserPaymentConfig 删除用户支付配置。
 // 若该用户存在 Price>0 且未结束的自有项目则拒绝删除,避免后续领取者无法付款。
 func DeleteUserPaymentConfig(ctx context.Context, userID uint64) error {
-	var cnt int64
-	err := db.DB(ctx).
-		Model(&project.Project{}).
-		Where("creator_id = ? AND price > 0 AND end_time > ? AND is_completed = 0 AND status = ?",
-			userID, time.Now(), project.ProjectStatusNormal).
-		Count(&cnt).Error
+	cnt, err := countActivePaidProjects(ctx, userID)
 	if err != nil {
 		return err
 	}
@@ -106,12 +166,20 @@ func DeleteUserPaymentConfig(ctx context.Context, userID uint64) error {
 	return db.DB(ctx).Where("user_id = ?", userID).Delete(&UserPaymentConfig{}).Error
 }
 
-// decryptUserClientSecret 解密指定配置的 clientSecret。
+// countActivePaidProjects 统计用户 Price>0 且未结束的自有项目数量
+func countActivePaidProjects(ctx context.Context, userID uint64) (int64, error) {
+	var cnt int64
+	err := db.DB(ctx).
+		Model(&project.Project{}).
+		Where("creator_id = ? AND price > 0 AND end_time > ? AND is_completed = 0 AND status = ?",
+			userID, time.Now(), project.ProjectStatusNormal).
+		Count(&cnt).Error
+	return cnt, err
+}
+
+// decryptUserClientSecret 解密指定配置的 clientSecret,按密文携带的密钥版本选择密钥。
 func decryptUserClientSecret(cfg *UserPaymentConfig) (string, error) {
-	if err := validateEncryptionKeyConfigured(); err != nil {
-		return "", err
-	}
-	return DecryptSecret(cfg.ClientSecretEnc, config.Config.Payment.ConfigEncryptionKey)
+	return decryptClientSecret(cfg.ClientSecretEnc)
 }
 
 // genOutTradeNo 生成本地订单号:CDK + yyyyMMddHHmmss + 8 位随机十六进制。
@@ -121,36 +189,36 @@ func genOutTradeNo() string {
 	return fmt.Sprintf("CDK%s%s", time.Now().Format("20060102150405"), hex.EncodeToString(b[:]))
 }
 
-// moneyString 将 decimal 金额格式化为"两位小数"字符串,与 epay 的金额要求保持一致。
-func moneyString(d decimal.Decimal) string {
-	return d.StringFixed(2)
-}
-
-// PaymentInitiation 返回给前端的发起支付信息
+// PaymentInitiation 返回给前端的发起支付信息,Amount 按 Currency 的精度格式化;
+// ItemID 非 0 表示优惠码抵扣全额、已直接发放该 item
 type PaymentInitiation struct {
 	OutTradeNo string    `json:"out_trade_no"`
 	PayURL     string    `json:"pay_url"`
 	Amount     string    `json:"amount"`
+	Currency   Currency  `json:"currency"`
 	ExpireAt   time.Time `json:"expire_at"`
+	ItemID     uint64    `json:"-"`
 }
 
-// buildPaymentInitiation 使用本地订单信息构造易支付跳转地址。
-func buildPaymentInitiation(p *project.Project, cfg *UserPaymentConfig, secret string, order *PaymentOrder) PaymentInitiation {
-	name := truncateRuneLen("CDK-"+p.Name, 60)
-	return PaymentInitiation{
+// buildPaymentInitiation 使用本地订单信息向支付渠道下单并构造跳转地址。
+func buildPaymentInitiation(ctx context.Context, p *project.Project, provider PaymentProvider, merchant Merchant, order *PaymentOrder) (PaymentInitiation, error) {
+	payURL, err := provider.CreateOrder(ctx, merchant, CreateOrderRequest{
 		OutTradeNo: order.OutTradeNo,
-		PayURL: submitURL(
-			cfg.ClientID,
-			secret,
-			name,
-			moneyString(order.Amount),
-			order.OutTradeNo,
-			callbackNotifyURL(),
-			callbackReturnURL(p.ID),
-		),
-		Amount:   moneyString(order.Amount),
-		ExpireAt: order.ExpireAt,
+		Name:       truncateRuneLen("CDK-"+p.Name, 60),
+		Money:      order.money(),
+		NotifyURL:  callbackNotifyURL(provider.Type()),
+		ReturnURL:  callbackReturnURL(p.ID),
+	})
+	if err != nil {
+		return PaymentInitiation{}, err
 	}
+	return PaymentInitiation{
+		OutTradeNo: order.OutTradeNo,
+		PayURL:     payURL,
+		Amount:     order.money(),
+		Currency:   order.Currency.OrDefault(),
+		ExpireAt:   order.ExpireAt,
+	}, nil
 }
 
 // GetPendingPaymentInitiation 查询当前用户在指定项目下仍有效的待支付订单。
@@ -184,15 +252,18 @@ func GetPendingPaymentInitiation(ctx context.Context, p *project.Project, payer
 	if cfg == nil {
 		return nil, errors.New(ErrCreatorNotConfigured)
 	}
-	if order.PayeeClientID != cfg.ClientID {
+	if !order.matchesMerchant(cfg) {
 		return nil, errors.New(ErrPendingOrderExists)
 	}
-	secret, err := decryptUserClientSecret(cfg)
+	provider, merchant, err := merchantOf(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	init := buildPaymentInitiation(p, cfg, secret, &order)
+	init, err := buildPaymentInitiation(ctx, p, provider, merchant, &order)
+	if err != nil {
+		return nil, err
+	}
 	return &init, nil
 }
 
@@ -200,7 +271,8 @@ func GetPendingPaymentInitiation(ctx context.Context, p *project.Project, payer
 // 调用方已通过 ReceiveProjectMiddleware 的前置校验。
 // 流程:载入商户凭据 → 复用已有 PENDING 订单，或 Redis LPop 预占 item 后创建新订单 → 构造 submit URL 返回。
 // 若创建订单失败或拼接失败,需立即把 itemID RPush 回 Redis 以恢复库存。
-func InitiatePayment(ctx context.Context, p *project.Project, payer *oauth.User, clientIP string) (*PaymentInitiation, error) {
+// couponCode 仅作用于新建订单，与订单在同一事务内占用;抵扣后金额为 0 时不经过支付渠道，直接按免费路径发放。
+func InitiatePayment(ctx context.Context, p *project.Project, payer *oauth.User, clientIP, couponCode string) (*PaymentInitiation, error) {
 	if !config.Config.Payment.Enabled {
 		return nil, errors.New(ErrPaymentDisabled)
 	}
@@ -215,7 +287,14 @@ func InitiatePayment(ctx context.Context, p *project.Project, payer *oauth.User,
 	if cfg == nil {
 		return nil, errors.New(ErrCreatorNotConfigured)
 	}
-	secret, err := decryptUserClientSecret(cfg)
+	if err := checkOrderCurrency(p, cfg); err != nil {
+		return nil, err
+	}
+	provider, merchant, err := merchantOf(cfg)
+	if err != nil {
+		return nil, err
+	}
+	coupon, err := LoadCoupon(ctx, p, couponCode)
 	if err != nil {
 		return nil, err
 	}
@@ -241,7 +320,16 @@ func InitiatePayment(ctx context.Context, p *project.Project, payer *oauth.User,
 			return err
 		}
 
-		// 存在 COMPLETED/PAID 时阻止重复创建，否则复用最新的 PENDING。
+		// 已达到每人领取上限时拒绝下单
+		claimed, err := p.ClaimCount(tx, payer.ID)
+		if err != nil {
+			return err
+		}
+		if claimed >= int64(p.QuotaFor(payer)) {
+			return errors.New(project.ReceiveLimitReached)
+		}
+
+		// 存在 PAID(发放中)时阻止重复创建，否则复用最新的 PENDING。
 		var activeOrder PaymentOrder
 		queryErr := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
 			Where(
@@ -251,7 +339,6 @@ func InitiatePayment(ctx context.Context, p *project.Project, payer *oauth.User,
 				[]OrderStatus{
 					OrderStatusPending,
 					OrderStatusPaid,
-					OrderStatusCompleted,
 				},
 			).
 			Order("status DESC").
@@ -259,15 +346,17 @@ func InitiatePayment(ctx context.Context, p *project.Project, payer *oauth.User,
 			First(&activeOrder).Error
 		if queryErr == nil {
 			switch activeOrder.Status {
-			case OrderStatusPaid, OrderStatusCompleted:
+			case OrderStatusPaid:
 				return errors.New(ErrPendingOrderExists)
 			case OrderStatusPending:
-				// 原订单属于旧的商户 ClientID 时无法安全重建签名，等待其按原流程过期释放。
-				if activeOrder.PayeeClientID != cfg.ClientID {
+				// 原订单属于旧的商户 ClientID 或支付渠道时无法安全重建签名，等待其按原流程过期释放。
+				if !activeOrder.matchesMerchant(cfg) {
 					return errors.New(ErrPendingOrderExists)
 				}
 
-				init = buildPaymentInitiation(p, cfg, secret, &activeOrder)
+				if init, err = buildPaymentInitiation(ctx, p, provider, merchant, &activeOrder); err != nil {
+					return err
+				}
 				logger.InfoF(ctx, "Reusing pending payment order %s for project %s and payer %d", activeOrder.OutTradeNo, p.ID, payer.ID)
 			}
 			return nil
@@ -276,6 +365,24 @@ func InitiatePayment(ctx context.Context, p *project.Project, payer *oauth.User,
 			return queryErr
 		}
 
+		// 按预占前的剩余库存计算单价，并固化到订单金额
+		remaining, err := p.Stock(ctx)
+		if err != nil {
+			return err
+		}
+		amount, err := p.ResolvePrice(payer, time.Now(), remaining)
+		if err != nil {
+			return err
+		}
+		var couponID *uint64
+		if coupon != nil {
+			if err := consumeCoupon(tx, coupon.ID); err != nil {
+				return err
+			}
+			amount = coupon.Apply(amount, p.Currency)
+			couponID = &coupon.ID
+		}
+
 		// 预占 item(Redis LPOP 原子)
 		reservedItemID, err := p.PrepareReceive(ctx, payer.Username)
 		if err != nil {
@@ -284,25 +391,27 @@ func InitiatePayment(ctx context.Context, p *project.Project, payer *oauth.User,
 		itemID = reservedItemID
 		logger.InfoF(ctx, "Reserved item %d for project %s and payer %d", itemID, p.ID, payer.ID)
 
-		outTradeNo := genOutTradeNo()
-		order := PaymentOrder{
-			OutTradeNo:    outTradeNo,
-			ProjectID:     p.ID,
-			ItemID:        itemID,
-			PayerID:       payer.ID,
-			PayeeID:       p.CreatorID,
-			PayeeClientID: cfg.ClientID,
-			Amount:        p.Price,
-			Status:        OrderStatusPending,
-			ExpireAt:      expireAt,
-			ClientIP:      clientIP,
+		// 优惠码抵扣全额:跳过支付渠道，直接发放
+		if amount.IsZero() {
+			var item project.ProjectItem
+			if err := item.Exact(tx, itemID); err != nil {
+				return err
+			}
+			if err := p.FulfillForReceiver(ctx, tx, &item, payer.ID, clientIP); err != nil {
+				return err
+			}
+			init = PaymentInitiation{Amount: p.Currency.Format(amount), Currency: p.Currency.OrDefault(), ItemID: itemID}
+			logger.InfoF(ctx, "Coupon %d covered item %d for project %s and payer %d", coupon.ID, itemID, p.ID, payer.ID)
+			return nil
 		}
-		if err := tx.Create(&order).Error; err != nil {
+
+		order, err := createReservedOrder(tx, p, cfg, payer.ID, itemID, amount, couponID, expireAt, clientIP)
+		if err != nil {
 			return err
 		}
 
-		init = buildPaymentInitiation(p, cfg, secret, &order)
-		return nil
+		init, err = buildPaymentInitiation(ctx, p, provider, merchant, order)
+		return err
 	})
 	if err != nil {
 		// 仅在已成功预占 item 的情况下回滚库存。
@@ -315,6 +424,38 @@ func InitiatePayment(ctx context.Context, p *project.Project, payer *oauth.User,
 	return &init, nil
 }
 
+// checkOrderCurrency 项目币种需与收款方当前的结算币种一致，否则渠道无法按订单金额结算
+func checkOrderCurrency(p *project.Project, cfg *UserPaymentConfig) error {
+	if p.Currency.OrDefault() != cfg.currency() {
+		return fmt.Errorf(ErrCurrencyMismatch, p.Currency.OrDefault(), cfg.currency())
+	}
+	return nil
+}
+
+// createReservedOrder 为已预占的 item 创建 PENDING 订单，订单过期时由清理任务归还 item。
+// 由 InitiatePayment 与候补分配共用,amount 为调用方按定价规则及优惠码计算出的金额。
+func createReservedOrder(tx *gorm.DB, p *project.Project, cfg *UserPaymentConfig, payerID, itemID uint64, amount decimal.Decimal, couponID *uint64, expireAt time.Time, clientIP string) (*PaymentOrder, error) {
+	order := &PaymentOrder{
+		OutTradeNo:    genOutTradeNo(),
+		ProjectID:     p.ID,
+		ItemID:        itemID,
+		PayerID:       payerID,
+		PayeeID:       p.CreatorID,
+		PayeeClientID: cfg.ClientID,
+		Provider:      cfg.providerType(),
+		Amount:        amount,
+		Currency:      p.Currency.OrDefault(),
+		CouponID:      couponID,
+		Status:        OrderStatusPending,
+		ExpireAt:      expireAt,
+		ClientIP:      clientIP,
+	}
+	if err := tx.Create(order).Error; err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
 // truncateRuneLen 按 rune 长度截断字符串,避免多字节字符被拦腰截断
 func truncateRuneLen(s string, max int) string {
 	rs := []rune(s)
@@ -324,48 +465,87 @@ func truncateRuneLen(s string, max int) string {
 	return string(rs[:max])
 }
 
-// HandleNotifyParams 易支付回调需要的全部字段
-type HandleNotifyParams struct {
-	Query map[string]string
-}
-
 // HandleNotify 处理异步支付回调。
-// 返回 (success bool, reason string):success=true 表示应返回文本 "success";
-// 否则返回 "fail",epay 最多重试 5 次,每次间隔由对方决定。
+// 返回 (success bool, reason string):success=true 表示应向渠道确认成功(易支付返回文本 "success");
+// 否则确认失败,渠道会按各自策略重试。
 // 实现关键点:
-//  1. 拉起订单 → 验签(使用订单记录的 PayeeID 对应凭据) → 校验 trade_status/pid/money
+//  1. 拉起订单 → 验签(使用订单记录的 PayeeID 对应凭据与下单渠道) → 校验支付状态/商户号/金额
 //  2. 幂等:若订单已是 COMPLETED/REFUNDED 直接 success
 //  3. CAS 推进 PENDING → PAID,成功者执行 fulfill 事务
 //  4. fulfill 失败 → 调 refund,成功后 RPush item 回 Redis,置 REFUNDED;本次返回 fail 让对方重试,
 //     再次进入时因状态非 PENDING 直接 success
-func HandleNotify(ctx context.Context, q map[string]string) (bool, string) {
-	outTradeNo := q["out_trade_no"]
-	if outTradeNo == "" {
-		return false, "missing out_trade_no"
+func HandleNotify(ctx context.Context, provider PaymentProvider, req *NotifyRequest) (bool, string) {
+	ok, _, reason := handleNotify(ctx, provider, req)
+	return ok, reason
+}
+
+// handleNotify 同 HandleNotify,额外返回回调是否通过验签，供审计日志记录
+func handleNotify(ctx context.Context, provider PaymentProvider, req *NotifyRequest) (bool, bool, string) {
+	outTradeNo, err := provider.NotifyOutTradeNo(req)
+	if err != nil {
+		return false, false, err.Error()
 	}
 	var order PaymentOrder
 	if err := db.DB(ctx).Where("out_trade_no = ?", outTradeNo).First(&order).Error; err != nil {
-		return false, fmt.Sprintf("order not found: %v", err)
+		return false, false, fmt.Sprintf("order not found: %v", err)
+	}
+	if order.providerType() != provider.Type() {
+		return false, false, "provider mismatch"
 	}
 	cfg, err := GetUserPaymentConfig(ctx, order.PayeeID)
 	if err != nil || cfg == nil {
-		return false, "payee config missing"
+		return false, false, "payee config missing"
+	}
+	if cfg.providerType() != provider.Type() {
+		return false, false, "payee provider changed"
 	}
 	secret, err := decryptUserClientSecret(cfg)
 	if err != nil {
-		return false, "decrypt secret failed"
+		return false, false, "decrypt secret failed"
 	}
-	if !VerifySign(q, secret) {
-		return false, "sign mismatch"
+	merchant := Merchant{ClientID: cfg.ClientID, ClientSecret: secret}
+	result, err := provider.VerifyNotify(ctx, merchant, req)
+	if err != nil {
+		return false, false, err.Error()
+	}
+	if reason := checkNotifyResult(result, cfg, &order); reason != "" {
+		// 验签通过的已付款回调与本地订单不符，需人工核对
+		if result.Paid {
+			recordDiscrepancy(ctx, &order, DiscrepancyMismatch, result.TradeNo, reason)
+		}
+		return false, true, reason
+	}
+
+	ok, reason := settlePaidOrder(ctx, provider, merchant, &order, result.TradeNo)
+	return ok, true, reason
+}
+
+// checkNotifyResult 校验验签后的回调内容与本地订单一致，不一致时返回原因
+func checkNotifyResult(result *NotifyResult, cfg *UserPaymentConfig, order *PaymentOrder) string {
+	if !result.Paid {
+		return "trade_status not success"
 	}
-	if q["trade_status"] != "TRADE_SUCCESS" {
-		return false, "trade_status not success"
+	if result.OutTradeNo != order.OutTradeNo {
+		return "out_trade_no mismatch"
 	}
-	if q["pid"] != cfg.ClientID {
-		return false, "pid mismatch"
+	if result.ClientID != cfg.ClientID {
+		return "pid mismatch"
 	}
-	if q["money"] != moneyString(order.Amount) {
-		return false, "money mismatch"
+	if result.Money != order.money() {
+		return "money mismatch"
+	}
+	return ""
+}
+
+// settlePaidOrder 在渠道确认订单已付款后推进订单状态机，由支付回调与主动对账共用。
+// 返回值语义同 HandleNotify。
+func settlePaidOrder(ctx context.Context, provider PaymentProvider, merchant Merchant, order *PaymentOrder, tradeNo string) (bool, string) {
+	outTradeNo := order.OutTradeNo
+
+	// 已付款但本地已关闭(回调丢失后超时)的订单无法自动恢复，登记差异交由管理员处理
+	if order.Status == OrderStatusFailed {
+		recordDiscrepancy(ctx, order, DiscrepancyPaidButFailed, tradeNo, "渠道已付款，本地订单已关闭")
+		return true, "paid but failed"
 	}
 
 	// 幂等分支
@@ -375,10 +555,10 @@ func HandleNotify(ctx context.Context, q map[string]string) (bool, string) {
 
 	// 增加对 Refunding 状态的处理
 	if order.Status == OrderStatusRefunding {
-		refundErr := doEpayRefund(ctx, cfg.ClientID, secret, order.TradeNo, moneyString(order.Amount))
+		refundErr := provider.Refund(ctx, merchant, order.refundRequest())
 		if refundErr == nil {
 			tNow := time.Now()
-			processed, updateErr := markOrderRefundedAndReturnItem(ctx, &order, map[string]any{"status": OrderStatusRefunded, "refunded_at": &tNow}, OrderStatusRefunding)
+			processed, updateErr := markOrderRefundedAndReturnItem(ctx, order, map[string]any{"status": OrderStatusRefunded, "refunded_at": &tNow}, OrderStatusRefunding)
 			if updateErr != nil {
 				logger.ErrorF(ctx, "payment refund retry: failed to mark order %s refunded: %v", outTradeNo, updateErr)
 				return false, "update order status failed"
@@ -397,24 +577,29 @@ func HandleNotify(ctx context.Context, q map[string]string) (bool, string) {
 		Where("out_trade_no = ? AND status = ?", outTradeNo, OrderStatusPending).
 		Updates(map[string]any{
 			"status":   OrderStatusPaid,
-			"trade_no": q["trade_no"],
+			"trade_no": tradeNo,
 			"paid_at":  &now,
 		}).RowsAffected
 	if rows == 0 {
 		// 并发下另一个回调在处理,或订单已过期被扫描任务置 FAILED(itemID 已回滚),此时不再处理
-		// 仍返回 success 让对方停止重试,结果以订单最终状态为准
+		// 仍返回 success 让对方停止重试,结果以订单最终状态为准;已关闭的订单登记差异
+		var current PaymentOrder
+		if err := db.DB(ctx).Where("out_trade_no = ?", outTradeNo).First(&current).Error; err == nil && current.Status == OrderStatusFailed {
+			recordDiscrepancy(ctx, &current, DiscrepancyPaidButFailed, tradeNo, "渠道已付款，本地订单已关闭")
+		}
 		return true, "concurrent or non-pending"
 	}
 
 	// 重新读一次订单
-	if err := db.DB(ctx).Where("out_trade_no = ?", outTradeNo).First(&order).Error; err != nil {
+	if err := db.DB(ctx).Where("out_trade_no = ?", outTradeNo).First(order).Error; err != nil {
 		return false, err.Error()
 	}
+	webhook.Emit(ctx, db.DB(ctx), order.PayeeID, webhook.EventOrderPaid, order.webhookData())
 
 	// 发放
-	if err := fulfillPaidOrder(ctx, &order); err != nil {
+	if err := fulfillPaidOrder(ctx, order); err != nil {
 		// 退款 + RPush
-		refundErr := doEpayRefund(ctx, cfg.ClientID, secret, order.TradeNo, moneyString(order.Amount))
+		refundErr := provider.Refund(ctx, merchant, order.refundRequest())
 		updates := map[string]any{
 			"fail_reason": truncateRuneLen(err.Error(), 200),
 		}
@@ -422,7 +607,7 @@ func HandleNotify(ctx context.Context, q map[string]string) (bool, string) {
 			tNow := time.Now()
 			updates["status"] = OrderStatusRefunded
 			updates["refunded_at"] = &tNow
-			if _, updateErr := markOrderRefundedAndReturnItem(ctx, &order, updates, OrderStatusPaid); updateErr != nil {
+			if _, updateErr := markOrderRefundedAndReturnItem(ctx, order, updates, OrderStatusPaid); updateErr != nil {
 				logger.ErrorF(ctx, "payment refund: failed to mark order %s refunded: %v", outTradeNo, updateErr)
 				return false, "update order status failed"
 			}
@@ -434,7 +619,7 @@ func HandleNotify(ctx context.Context, q map[string]string) (bool, string) {
 				return false, "update order status failed"
 			}
 		}
-		// 让 epay 重试,再次进入时因状态非 PENDING 会回到 idempotent 分支
+		// 让渠道重试,再次进入时因状态非 PENDING 会回到 idempotent 分支
 		return false, fmt.Sprintf("fulfill failed: %v", err)
 	}
 
@@ -460,8 +645,8 @@ func fulfillPaidOrder(ctx context.Context, order *PaymentOrder) error {
 }
 
 // CallbackURLs 返回当前平台配置的回调地址,用于前端展示给用户。
-func CallbackURLs() (notifyURL, returnURL string) {
-	return callbackNotifyURL(), callbackReturnURL("")
+func CallbackURLs(providerType ProviderType) (notifyURL, returnURL string) {
+	return callbackNotifyURL(providerType), callbackReturnURL("")
 }
 
 // ErrOrderNotFoundSentinel 外部判断
@@ -29,6 +29,7 @@ import (
 	"time"
 
 	"github.com/hibiken/asynq"
+	"github.com/linux-do/cdk/internal/apps/project"
 	"github.com/linux-do/cdk/internal/db"
 	"github.com/linux-do/cdk/internal/logger"
 	"gorm.io/gorm"
@@ -76,9 +77,15 @@ func expireOrder(ctx context.Context, order *PaymentOrder) {
 			return nil
 		}
 
+		if err := project.ExpireWaitlistOrder(tx, order.OutTradeNo); err != nil {
+			return err
+		}
 		if err := returnReservedItem(ctx, tx, order.ProjectID, order.ItemID); err != nil {
 			return err
 		}
+		if err := releaseCoupon(tx, order.CouponID); err != nil {
+			return err
+		}
 
 		processed = true
 		return nil
@@ -27,28 +27,30 @@ package payment
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/linux-do/cdk/internal/apps/notification"
 	"github.com/linux-do/cdk/internal/apps/project"
+	"github.com/linux-do/cdk/internal/apps/webhook"
 	"github.com/linux-do/cdk/internal/db"
 	"gorm.io/gorm"
 )
 
-// returnReservedItem 把支付预占的 item 归还 Redis，并在同一个数据库事务中重置项目完成状态。
-// Redis RPush 或项目状态更新失败时返回 error，由调用方触发外层数据库事务回滚。
+// returnReservedItem 把支付预占的 item 归还:存在候补时暂存至归还队列等待分配，否则放回公开库存并重置项目完成状态。
+// Redis 写入或项目状态更新失败时返回 error，由调用方触发外层数据库事务回滚。
 func returnReservedItem(ctx context.Context, tx *gorm.DB, projectID string, itemID uint64) error {
 	var proj project.Project
 	if err := tx.Where("id = ?", projectID).First(&proj).Error; err != nil {
 		return fmt.Errorf("load project %s: %w", projectID, err)
 	}
-	if err := db.Redis.RPush(ctx, project.ProjectItemsKey(projectID), itemID).Err(); err != nil {
-		return fmt.Errorf("return item %d to project %s stock: %w", itemID, projectID, err)
-	}
-	if err := proj.ResetCompletedStatusIfHasStock(ctx, tx); err != nil {
-		return fmt.Errorf("reset completed status for project %s: %w", projectID, err)
+	if err := proj.ReturnItem(ctx, tx, itemID); err != nil {
+		return fmt.Errorf("return item %d to project %s: %w", itemID, projectID, err)
 	}
 	return nil
 }
 
+// markOrderRefundedAndReturnItem 通过 CAS 将订单置为已退款并处理 item:
+// 自动退款的 item 尚未发放，直接归还库存;手动退款的 item 已发放，仅在要求收回时撤销领取并作废该 item。
 func markOrderRefundedAndReturnItem(ctx context.Context, order *PaymentOrder, updates map[string]any, expectedStatus OrderStatus) (bool, error) {
 	processed := false
 	err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
@@ -61,7 +63,23 @@ func markOrderRefundedAndReturnItem(ctx context.Context, order *PaymentOrder, up
 		if result.RowsAffected == 0 {
 			return nil
 		}
+		refunded := order.webhookData()
+		refunded.RefundedAt, _ = updates["refunded_at"].(*time.Time)
+		webhook.Emit(ctx, tx, order.PayeeID, webhook.EventOrderRefunded, refunded)
+		if err := notification.Send(tx, notification.OrderRefunded(order.ProjectID, order.OutTradeNo, order.money(), order.RefundReason), order.PayerID); err != nil {
+			return err
+		}
 
+		// 手动退款的 item 已发放：要求收回时作废该 item,不归还库存，避免失效内容被再次售出
+		if order.RefundBy != nil {
+			if order.RevokeItem {
+				if err := project.RevokeReceivedItem(tx, order.ProjectID, order.ItemID, order.PayerID); err != nil {
+					return err
+				}
+			}
+			processed = true
+			return nil
+		}
 		if err := returnReservedItem(ctx, tx, order.ProjectID, order.ItemID); err != nil {
 			return err
 		}
@@ -0,0 +1,210 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/apps/project"
+	"github.com/linux-do/cdk/internal/config"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/logger"
+	"gorm.io/gorm"
+)
+
+// waitlistOfferBatchLimit 单次任务最多分配的候补数量，剩余部分重新下发任务继续处理
+const waitlistOfferBatchLimit = 50
+
+// HandleWaitlistOffer 库存归还后按 FIFO 为候补用户预占 item。
+// 付费项目复用 InitiatePayment 的预占语义直接创建待支付订单(有效期为候补保留时长),
+// 过期后由订单清理任务归还 item 并再次触发分配;免费项目保留至到期，由到期任务归还。
+func HandleWaitlistOffer(ctx context.Context, t *asynq.Task) error {
+	var payload struct {
+		ProjectID string `json:"project_id"`
+	}
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("解析任务参数失败: %w", err)
+	}
+
+	p := &project.Project{}
+	if err := p.Exact(db.DB(ctx), payload.ProjectID, true); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !p.IsWaitlistable() || p.EndTime.Before(time.Now()) {
+		return releaseReturnedItems(ctx, p, nil)
+	}
+
+	for i := 0; i < waitlistOfferBatchLimit; i++ {
+		offered, err := offerNextWaitlist(ctx, p)
+		if err != nil || !offered {
+			return releaseReturnedItems(ctx, p, err)
+		}
+	}
+	project.EnqueueWaitlistOffer(ctx, p.ID)
+	return nil
+}
+
+// releaseReturnedItems 候补分配结束或无法继续时，把暂存的归还 item 开放给公开领取，避免库存滞留;返回原始错误
+func releaseReturnedItems(ctx context.Context, p *project.Project, err error) error {
+	if releaseErr := p.ReleaseReturnedItems(ctx); releaseErr != nil {
+		logger.ErrorF(ctx, "项目[%s]归还暂存库存失败: %v", p.ID, releaseErr)
+		if err == nil {
+			return releaseErr
+		}
+	}
+	return err
+}
+
+// offerNextWaitlist 预占一个 item 分配给队首候补，库存或队列为空时返回 false
+func offerNextWaitlist(ctx context.Context, p *project.Project) (bool, error) {
+	var cfg *UserPaymentConfig
+	if p.IsPaid() {
+		if !config.Config.Payment.Enabled {
+			return false, nil
+		}
+		var err error
+		if cfg, err = GetUserPaymentConfig(ctx, p.CreatorID); err != nil {
+			return false, err
+		}
+		if cfg == nil {
+			logger.WarnF(ctx, "项目[%s]创建者未配置支付凭据，跳过候补分配", p.ID)
+			return false, nil
+		}
+		if err := checkOrderCurrency(p, cfg); err != nil {
+			logger.WarnF(ctx, "项目[%s]跳过候补分配: %v", p.ID, err)
+			return false, nil
+		}
+	}
+
+	// 预占 item,优先使用为候补暂存的归还库存
+	itemID, fromReturned, err := p.PrepareWaitlistOffer(ctx)
+	if err != nil {
+		if err.Error() == project.NoStock {
+			return false, nil
+		}
+		return false, err
+	}
+
+	holdExpireAt := time.Now().Add(project.WaitlistHoldDuration())
+	var entry *project.ProjectWaitlist
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		for {
+			if entry, err = p.NextWaitlist(tx); err != nil || entry == nil {
+				return err
+			}
+			eligible, err := isWaitlistEligible(tx, p, entry)
+			if err != nil {
+				return err
+			}
+			if eligible {
+				break
+			}
+			if err := project.CancelWaitlist(tx, entry.ID); err != nil {
+				return err
+			}
+		}
+
+		outTradeNo := ""
+		if p.IsPaid() {
+			var payer oauth.User
+			if err := payer.Exact(tx, entry.UserID); err != nil {
+				return err
+			}
+			// item 已预占，剩余库存需计入本次分配
+			remaining, err := p.Stock(ctx)
+			if err != nil {
+				return err
+			}
+			amount, err := p.ResolvePrice(&payer, time.Now(), remaining+1)
+			if err != nil {
+				return err
+			}
+			order, err := createReservedOrder(tx, p, cfg, entry.UserID, itemID, amount, nil, holdExpireAt, entry.ClientIP)
+			if err != nil {
+				return err
+			}
+			outTradeNo = order.OutTradeNo
+		}
+		return p.OfferWaitlistHold(tx, entry, itemID, outTradeNo, holdExpireAt)
+	}); err != nil || entry == nil {
+		// 分配失败或队列为空，归还预占的 item 至原队列
+		if fromReturned {
+			db.Redis.LPush(ctx, p.ReturnedItemsKey(), itemID)
+		} else {
+			db.Redis.RPush(ctx, p.ItemsKey(), itemID)
+		}
+		return false, err
+	}
+
+	if !p.IsPaid() {
+		if err := project.EnqueueWaitlistHoldExpiry(ctx, entry); err != nil {
+			logger.ErrorF(ctx, "下发候补[%d]预占到期任务失败: %v", entry.ID, err)
+		}
+	}
+	logger.InfoF(ctx, "项目[%s]为候补[%d]用户 %d 预占 item %d", p.ID, entry.ID, entry.UserID, itemID)
+	return true, nil
+}
+
+// isWaitlistEligible 校验候补用户是否仍可获得预占:未达到领取上限，付费项目下无进行中的订单
+func isWaitlistEligible(tx *gorm.DB, p *project.Project, entry *project.ProjectWaitlist) (bool, error) {
+	var user oauth.User
+	if err := user.Exact(tx, entry.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if p.ValidateRequirement(&user) != nil {
+		return false, nil
+	}
+	claimed, err := p.ClaimCount(tx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	if claimed >= int64(p.QuotaFor(&user)) {
+		return false, nil
+	}
+	if !p.IsPaid() {
+		return true, nil
+	}
+
+	var active int64
+	if err := tx.Model(&PaymentOrder{}).
+		Where("project_id = ? AND payer_id = ? AND status IN ?", p.ID, user.ID,
+			[]OrderStatus{OrderStatusPending, OrderStatusPaid}).
+		Count(&active).Error; err != nil {
+		return false, err
+	}
+	return active == 0, nil
+}
@@ -0,0 +1,195 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/db"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProjectClaim 用户在项目下的领取记录,(project_id, user_id, round, seq) 唯一约束保证并发下不会超出每轮领取上限
+type ProjectClaim struct {
+	ID        uint64    `json:"id" gorm:"primaryKey,autoIncrement"`
+	ProjectID string    `json:"project_id" gorm:"size:64;uniqueIndex:idx_project_user_round_seq,priority:1"`
+	UserID    uint64    `json:"user_id" gorm:"index;uniqueIndex:idx_project_user_round_seq,priority:2"`
+	Round     int       `json:"round" gorm:"default:0;not null;uniqueIndex:idx_project_user_round_seq,priority:3"`
+	Seq       int       `json:"seq" gorm:"uniqueIndex:idx_project_user_round_seq,priority:4"`
+	ItemID    uint64    `json:"item_id" gorm:"index"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TrustLevelQuota 按信任等级覆盖的每人领取上限
+type TrustLevelQuota map[oauth.TrustLevel]int
+
+func (q *TrustLevelQuota) Scan(value interface{}) error {
+	if value == nil {
+		*q = nil
+		return nil
+	}
+	bytesValue, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid value: %v", value)
+	}
+	return json.Unmarshal(bytesValue, q)
+}
+
+func (q TrustLevelQuota) Value() (driver.Value, error) {
+	if len(q) <= 0 {
+		return nil, nil
+	}
+	return json.Marshal(q)
+}
+
+// Validate 校验覆盖配置的信任等级与上限取值
+func (q TrustLevelQuota) Validate() error {
+	for level, quota := range q {
+		if level < oauth.TrustLevelNewUser || level > oauth.TrustLevelLeader {
+			return fmt.Errorf(MaxPerUserLevelInvalid, level)
+		}
+		if quota < 1 || quota > maxPerUserLimit {
+			return fmt.Errorf(MaxPerUserInvalid, maxPerUserLimit)
+		}
+	}
+	return nil
+}
+
+// QuotaFor 返回指定用户在项目下的领取上限，优先使用其信任等级的覆盖配置
+func (p *Project) QuotaFor(user *oauth.User) int {
+	if p.IsWinnerBased() {
+		return 1
+	}
+	if quota, ok := p.MaxPerUserOverrides[user.TrustLevel]; ok && quota > 0 {
+		return quota
+	}
+	if p.MaxPerUser > 0 {
+		return p.MaxPerUser
+	}
+	return 1
+}
+
+// applyClaimQuota 设置每人领取上限，未填写时默认 1;按中奖者发放的项目固定为 1
+func (p *Project) applyClaimQuota(maxPerUser int, overrides TrustLevelQuota) {
+	if maxPerUser <= 0 || p.IsWinnerBased() {
+		maxPerUser = 1
+	}
+	if p.IsWinnerBased() {
+		overrides = nil
+	}
+	p.MaxPerUser = maxPerUser
+	p.MaxPerUserOverrides = overrides
+}
+
+// claimQuotaSQL 按信任等级计算每人领取上限的 SQL 片段(projects 别名为 p),占位参数为 quotaJSONPath 的返回值
+const claimQuotaSQL = "COALESCE(JSON_EXTRACT(p.max_per_user_overrides, ?), p.max_per_user)"
+
+// quotaJSONPath 返回信任等级覆盖配置的 JSON 路径
+func quotaJSONPath(level oauth.TrustLevel) string {
+	return fmt.Sprintf(`$."%d"`, level)
+}
+
+// ClaimCount 统计用户在项目当前轮次已领取的数量
+func (p *Project) ClaimCount(tx *gorm.DB, userID uint64) (int64, error) {
+	var count int64
+	err := tx.Model(&ProjectClaim{}).
+		Where("project_id = ? AND user_id = ? AND round = ?", p.ID, userID, p.Round).
+		Count(&count).Error
+	return count, err
+}
+
+// CheckClaimQuota 校验用户是否已达到领取上限
+func (p *Project) CheckClaimQuota(ctx context.Context, user *oauth.User) error {
+	count, err := p.ClaimCount(db.DB(ctx), user.ID)
+	if err != nil {
+		return err
+	}
+	if count >= int64(p.QuotaFor(user)) {
+		return errors.New(ReceiveLimitReached)
+	}
+	return nil
+}
+
+// createClaim 在发放事务中登记领取记录;已领数量达到上限或并发写入同一序号时拒绝。
+// 序号取当前轮次最大序号 + 1,领取记录被收回后序号不连续也不会与已有记录冲突
+func (p *Project) createClaim(tx *gorm.DB, user *oauth.User, itemID uint64) error {
+	var count, maxSeq int64
+	if err := tx.Model(&ProjectClaim{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("COUNT(*), COALESCE(MAX(seq), 0)").
+		Where("project_id = ? AND user_id = ? AND round = ?", p.ID, user.ID, p.Round).
+		Row().Scan(&count, &maxSeq); err != nil {
+		return err
+	}
+	if count >= int64(p.QuotaFor(user)) {
+		return errors.New(ReceiveLimitReached)
+	}
+	if err := tx.Create(&ProjectClaim{ProjectID: p.ID, UserID: user.ID, Round: p.Round, Seq: int(maxSeq) + 1, ItemID: itemID}).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "Duplicate") {
+			return errors.New(ReceiveLimitReached)
+		}
+		return err
+	}
+	return nil
+}
+
+// GetReceivedItems 返回用户在项目下领取的全部 item,按领取时间倒序
+func (p *Project) GetReceivedItems(ctx context.Context, userID uint64) ([]ProjectItem, error) {
+	var items []ProjectItem
+	err := db.DB(ctx).
+		Where("project_id = ? AND receiver_id = ?", p.ID, userID).
+		Order("received_at DESC, id DESC").
+		Find(&items).Error
+	return items, err
+}
+
+// RevokeReceivedItem 收回用户已领取的 item(如退款时认定内容失效):清空领取人并删除领取记录，领取人的额度随之恢复;
+// item 标记为作废且从 total_items 中扣除，不会回到库存被再次发放。
+func RevokeReceivedItem(tx *gorm.DB, projectID string, itemID, receiverID uint64) error {
+	now := time.Now()
+	result := tx.Model(&ProjectItem{}).
+		Where("id = ? AND project_id = ? AND receiver_id = ?", itemID, projectID, receiverID).
+		Updates(map[string]interface{}{"receiver_id": nil, "received_at": nil, "revoked_at": &now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(ClaimNotFound)
+	}
+	if err := tx.Where("item_id = ? AND user_id = ?", itemID, receiverID).Delete(&ProjectClaim{}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&Project{}).
+		Where("id = ? AND total_items > 0", projectID).
+		Update("total_items", gorm.Expr("total_items - 1")).Error
+}
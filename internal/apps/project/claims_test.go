@@ -0,0 +1,149 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/db"
+	"gorm.io/gorm"
+)
+
+func TestQuotaFor(t *testing.T) {
+	p := &Project{DistributionType: DistributionTypeOneForEach}
+	p.applyClaimQuota(3, TrustLevelQuota{oauth.TrustLevelLeader: 5})
+
+	if got := p.QuotaFor(&oauth.User{TrustLevel: oauth.TrustLevelUser}); got != 3 {
+		t.Fatalf("want default quota 3, got %d", got)
+	}
+	if got := p.QuotaFor(&oauth.User{TrustLevel: oauth.TrustLevelLeader}); got != 5 {
+		t.Fatalf("want override quota 5, got %d", got)
+	}
+
+	// 按中奖者发放的项目固定为 1
+	lottery := &Project{DistributionType: DistributionTypeLottery}
+	lottery.applyClaimQuota(3, TrustLevelQuota{oauth.TrustLevelLeader: 5})
+	if got := lottery.QuotaFor(&oauth.User{TrustLevel: oauth.TrustLevelLeader}); got != 1 {
+		t.Fatalf("want lottery quota 1, got %d", got)
+	}
+}
+
+func TestTrustLevelQuotaValidate(t *testing.T) {
+	if err := (TrustLevelQuota{oauth.TrustLevelUser: 2}).Validate(); err != nil {
+		t.Fatalf("want valid, got %v", err)
+	}
+	if err := (TrustLevelQuota{oauth.TrustLevel(9): 2}).Validate(); err == nil {
+		t.Fatalf("want invalid trust level rejected")
+	}
+	if err := (TrustLevelQuota{oauth.TrustLevelUser: 0}).Validate(); err == nil {
+		t.Fatalf("want zero quota rejected")
+	}
+}
+
+// claimNext 从库存队列取出下一个 item 并为用户发放
+func claimNext(t *testing.T, p *Project, userID uint64, ip string) {
+	t.Helper()
+	ctx := context.Background()
+	id, err := db.Redis.LPop(ctx, p.ItemsKey()).Uint64()
+	if err != nil {
+		t.Fatalf("pop stock: %v", err)
+	}
+	var item ProjectItem
+	if err := item.Exact(db.DB(ctx), id); err != nil {
+		t.Fatalf("load item: %v", err)
+	}
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		return p.FulfillForReceiver(ctx, tx, &item, userID, ip)
+	}); err != nil {
+		t.Fatalf("fulfill: %v", err)
+	}
+}
+
+func TestSameIPAllowsRepeatClaimsBySameUser(t *testing.T) {
+	setupItemStore(t)
+	ctx := context.Background()
+
+	alice := &oauth.User{ID: 1, Username: "alice", Score: oauth.BaseUserScore}
+	bob := &oauth.User{ID: 2, Username: "bob", Score: oauth.BaseUserScore}
+	if err := db.DB(ctx).Create([]*oauth.User{alice, bob}).Error; err != nil {
+		t.Fatalf("create users: %v", err)
+	}
+	p := &Project{DistributionType: DistributionTypeOneForEach, MaxPerUser: 2}
+	createStockedProject(t, p, "KEY-1", "KEY-2", "KEY-3")
+
+	const ip = "203.0.113.7"
+	for i := 0; i < 2; i++ {
+		if err := p.IsReceivable(ctx, time.Now(), alice, ip); err != nil {
+			t.Fatalf("claim %d by same user from same ip should be allowed, got %v", i+1, err)
+		}
+		claimNext(t, p, alice.ID, ip)
+	}
+	if err := p.IsReceivable(ctx, time.Now(), alice, ip); err == nil || err.Error() != ReceiveLimitReached {
+		t.Fatalf("want %q after quota is used up, got %v", ReceiveLimitReached, err)
+	}
+
+	// 其他用户在同一 IP 下仍被拒绝，换 IP 后可领取
+	if err := p.IsReceivable(ctx, time.Now(), bob, ip); err == nil || err.Error() != SameIPReceived {
+		t.Fatalf("want %q for another user on the same ip, got %v", SameIPReceived, err)
+	}
+	if err := p.IsReceivable(ctx, time.Now(), bob, "203.0.113.8"); err != nil {
+		t.Fatalf("want another ip allowed, got %v", err)
+	}
+}
+
+func TestClaimAfterRevokingEarlierClaim(t *testing.T) {
+	setupItemStore(t)
+	ctx := context.Background()
+
+	alice := &oauth.User{ID: 1, Username: "alice", Score: oauth.BaseUserScore}
+	if err := db.DB(ctx).Create(alice).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	p := &Project{DistributionType: DistributionTypeOneForEach, MaxPerUser: 3, AllowSameIP: true}
+	items := createStockedProject(t, p, "KEY-1", "KEY-2", "KEY-3", "KEY-4")
+
+	claimNext(t, p, alice.ID, "203.0.113.7")
+	claimNext(t, p, alice.ID, "203.0.113.7")
+
+	// 收回中间的领取记录后额度恢复，新领取的序号不与剩余记录冲突
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		return RevokeReceivedItem(tx, p.ID, items[0].ID, alice.ID)
+	}); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	claimNext(t, p, alice.ID, "203.0.113.7")
+	claimNext(t, p, alice.ID, "203.0.113.7")
+
+	count, err := p.ClaimCount(db.DB(ctx), alice.ID)
+	if err != nil || count != 3 {
+		t.Fatalf("want 3 claims after revoke and reclaim, got %d (%v)", count, err)
+	}
+	if err := p.CheckClaimQuota(ctx, alice); err == nil || err.Error() != ReceiveLimitReached {
+		t.Fatalf("want %q once quota is used up again, got %v", ReceiveLimitReached, err)
+	}
+}
@@ -25,9 +25,27 @@
 package project
 
 const (
-	ProjectObjKey = "project_obj"
+	ProjectObjKey        = "project_obj"
+	ProjectInviteObjKey  = "project_invite_obj"
+	ReceiveRequestObjKey = "receive_request_obj"
 	// projectItemInsertBatchSize limits batch inserts to avoid exceeding MySQL's placeholder ceiling.
 	projectItemInsertBatchSize = 1000
+	// projectItemMaxLength 与 ProjectItem.Content 列宽一致
+	projectItemMaxLength = 1024
+	// itemImportRejectReportLimit 限制导入报告中逐行列出的拒绝数量
+	itemImportRejectReportLimit = 1000
+	// itemImportMaxFileSize 导入文件大小上限
+	itemImportMaxFileSize = 64 << 20
+	// itemImportLineMaxBytes 导入时单行读取上限,超出部分直接丢弃并按超长拒绝该行
+	itemImportLineMaxBytes = 64 << 10
+	// itemExportBatchSize 导出时每批读取的 item 数量
+	itemExportBatchSize = 1000
+	// maxPerUserLimit 每人领取上限的最大取值
+	maxPerUserLimit = 100
+	// maxTemplatesPerUser 每个用户可保存的项目模板数量上限
+	maxTemplatesPerUser = 50
+	// maxPriceSteps 库存阶梯价的最大档位数
+	maxPriceSteps = 10
 )
 
 type DistributionType int8
@@ -36,6 +54,7 @@ const (
 	DistributionTypeOneForEach DistributionType = iota
 	DistributionTypeLottery
 	DistributionTypeInvite
+	DistributionTypeDraw
 )
 
 type ProjectStatus uint8
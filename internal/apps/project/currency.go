@@ -0,0 +1,85 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// Currency 付费项目与支付配置的计价币种
+type Currency string
+
+const (
+	CurrencyLDC    Currency = "LDC"    // LDC 积分，精确到 0.01
+	CurrencyCNY    Currency = "CNY"    // 人民币，精确到 0.01
+	CurrencyCredit Currency = "CREDIT" // 整数积分，仅以整数单位结算
+)
+
+// DefaultCurrency 未指定币种时的默认值，兼容引入币种前的项目与订单
+const DefaultCurrency = CurrencyLDC
+
+// currencyPrecisions 各币种允许的小数位数
+var currencyPrecisions = map[Currency]int32{
+	CurrencyLDC:    2,
+	CurrencyCNY:    2,
+	CurrencyCredit: 0,
+}
+
+// OrDefault 空值视为默认币种
+func (c Currency) OrDefault() Currency {
+	if c == "" {
+		return DefaultCurrency
+	}
+	return c
+}
+
+// IsValid 是否为支持的币种
+func (c Currency) IsValid() bool {
+	_, ok := currencyPrecisions[c.OrDefault()]
+	return ok
+}
+
+// Precision 币种允许的小数位数
+func (c Currency) Precision() int32 {
+	if precision, ok := currencyPrecisions[c.OrDefault()]; ok {
+		return precision
+	}
+	return currencyPrecisions[DefaultCurrency]
+}
+
+// MinUnit 币种的最小计价单位
+func (c Currency) MinUnit() decimal.Decimal {
+	return decimal.New(1, -c.Precision())
+}
+
+// Round 按币种精度四舍五入
+func (c Currency) Round(d decimal.Decimal) decimal.Decimal {
+	return d.Round(c.Precision())
+}
+
+// Format 按币种精度格式化金额，固定输出对应的小数位数
+func (c Currency) Format(d decimal.Decimal) string {
+	return d.StringFixed(c.Precision())
+}
@@ -0,0 +1,124 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/db"
+	"gorm.io/gorm"
+)
+
+// EnterProject
+// @Tags project
+// @Produce json
+// @Param id path string true "项目ID"
+// @Success 200 {object} ProjectResponse
+// @Router /api/v1/projects/{id}/entries [post]
+func EnterProject(c *gin.Context) {
+	// load user
+	user, _ := oauth.GetUserFromContext(c)
+
+	// load project
+	project := &Project{}
+	if err := project.Exact(db.DB(c.Request.Context()), c.Param("id"), true); err != nil {
+		c.JSON(http.StatusNotFound, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// do enter
+	if err := project.Enter(c.Request.Context(), user); err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ProjectResponse{})
+}
+
+type ProjectDrawEntrant struct {
+	Username  string    `json:"username"`
+	IsWinner  bool      `json:"is_winner"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type GetProjectDrawResponseData struct {
+	DrawSeedHash string               `json:"draw_seed_hash"`
+	DrawSeed     string               `json:"draw_seed"`
+	DrawnAt      *time.Time           `json:"drawn_at"`
+	EntrantCount int                  `json:"entrant_count"`
+	Entrants     []ProjectDrawEntrant `json:"entrants"`
+}
+
+type GetProjectDrawResponse struct {
+	ErrorMsg string                     `json:"error_msg"`
+	Data     GetProjectDrawResponseData `json:"data"`
+}
+
+// GetProjectDraw 获取平台抽奖的承诺值、开奖种子及报名名单,开奖后任何人可据此复现中奖结果
+// @Tags project
+// @Produce json
+// @Param id path string true "项目ID"
+// @Success 200 {object} GetProjectDrawResponse
+// @Router /api/v1/projects/{id}/draw [get]
+func GetProjectDraw(c *gin.Context) {
+	// load project
+	project := &Project{}
+	if err := project.Exact(db.DB(c.Request.Context()), c.Param("id"), true); err != nil {
+		c.JSON(http.StatusNotFound, GetProjectDrawResponse{ErrorMsg: err.Error()})
+		return
+	}
+	if project.DistributionType != DistributionTypeDraw {
+		c.JSON(http.StatusBadRequest, GetProjectDrawResponse{ErrorMsg: DrawOnly})
+		return
+	}
+
+	// load entrants
+	var entrants []ProjectDrawEntrant
+	if err := db.DB(c.Request.Context()).
+		Table("project_entries pe").
+		Select("u.username, pe.is_winner, pe.created_at").
+		Joins("INNER JOIN users u ON u.id = pe.user_id").
+		Where("pe.project_id = ?", project.ID).
+		Order("pe.user_id ASC").
+		Scan(&entrants).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, GetProjectDrawResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	data := GetProjectDrawResponseData{
+		DrawSeedHash: project.DrawSeedHash,
+		DrawnAt:      project.DrawnAt,
+		EntrantCount: len(entrants),
+		Entrants:     entrants,
+	}
+	if project.IsDrawn() {
+		data.DrawSeed = project.DrawSeed
+	}
+	c.JSON(http.StatusOK, GetProjectDrawResponse{Data: data})
+}
@@ -0,0 +1,259 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/cdk/internal/apps/forum"
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/config"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/logger"
+	"github.com/linux-do/cdk/internal/task"
+	"github.com/linux-do/cdk/internal/task/schedule"
+	"gorm.io/gorm"
+)
+
+// ProjectEntry 平台抽奖模式下的报名记录
+type ProjectEntry struct {
+	ID        uint64    `json:"id" gorm:"primaryKey,autoIncrement"`
+	ProjectID string    `json:"project_id" gorm:"size:64;index;uniqueIndex:idx_project_entrant"`
+	UserID    uint64    `json:"user_id" gorm:"index;uniqueIndex:idx_project_entrant"`
+	IsWinner  bool      `json:"is_winner" gorm:"default:false"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// IsWinnerBased 是否按中奖者发放:item 以 username -> itemID 存放于 Redis Hash
+func (p *Project) IsWinnerBased() bool {
+	return p.DistributionType == DistributionTypeLottery || p.DistributionType == DistributionTypeDraw
+}
+
+// IsDrawn 平台抽奖是否已开奖
+func (p *Project) IsDrawn() bool {
+	return p.DrawnAt != nil
+}
+
+// genDrawSeed 生成开奖种子及其承诺值(种子的 SHA-256),承诺值在创建时公开,种子在开奖后公开
+func genDrawSeed() (seed, commitment string, err error) {
+	var b [32]byte
+	if _, err = rand.Read(b[:]); err != nil {
+		return "", "", err
+	}
+	seed = hex.EncodeToString(b[:])
+	return seed, drawCommitment(seed), nil
+}
+
+// drawCommitment 计算种子承诺值
+func drawCommitment(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:])
+}
+
+// drawWinners 以种子对按 user_id 升序排列的报名者做 Fisher-Yates 洗牌，取前 count 位。
+// 第 i 轮的随机数为 SHA-256(seed + ":" + i) 的前 8 字节(大端)对 i+1 取模，便于任何人复现。
+func drawWinners(seed string, entrants []uint64, count int) []uint64 {
+	shuffled := make([]uint64, len(entrants))
+	copy(shuffled, entrants)
+	sort.Slice(shuffled, func(i, j int) bool { return shuffled[i] < shuffled[j] })
+
+	for i := len(shuffled) - 1; i > 0; i-- {
+		sum := sha256.Sum256([]byte(seed + ":" + strconv.Itoa(i)))
+		j := int(binary.BigEndian.Uint64(sum[:8]) % uint64(i+1))
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	}
+
+	if count > len(shuffled) {
+		count = len(shuffled)
+	}
+	return shuffled[:count]
+}
+
+// Enter 报名平台抽奖,报名窗口为项目创建至 StartTime
+func (p *Project) Enter(ctx context.Context, user *oauth.User) error {
+	if p.DistributionType != DistributionTypeDraw {
+		return errors.New(DrawOnly)
+	}
+	if !time.Now().Before(p.StartTime) || p.IsDrawn() {
+		return errors.New(EntryClosed)
+	}
+	if err := p.ValidateRequirement(user); err != nil {
+		return err
+	}
+	var count int64
+	if err := db.DB(ctx).Model(&ProjectEntry{}).
+		Where("project_id = ? AND user_id = ?", p.ID, user.ID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New(AlreadyEntered)
+	}
+	return db.DB(ctx).Create(&ProjectEntry{ProjectID: p.ID, UserID: user.ID}).Error
+}
+
+// drawTaskPayload 开奖任务参数
+type drawTaskPayload struct {
+	ProjectID string `json:"project_id"`
+}
+
+// EnqueueDraw 下发在 StartTime 执行的开奖任务;开奖通过 DrawnAt CAS 保证幂等，重复下发无副作用
+func (p *Project) EnqueueDraw(ctx context.Context) error {
+	payload, _ := json.Marshal(drawTaskPayload{ProjectID: p.ID})
+	if _, err := schedule.AsynqClient.EnqueueContext(
+		ctx,
+		asynq.NewTask(task.DrawProjectLotteryTask, payload),
+		asynq.ProcessAt(p.StartTime),
+		asynq.MaxRetry(5),
+	); err != nil {
+		return err
+	}
+	logger.InfoF(ctx, "下发项目[%s]开奖任务成功，开奖时间 %s", p.ID, p.StartTime.Format(time.RFC3339))
+	return nil
+}
+
+// Draw 执行开奖:抽取中奖者、分配 item、写入 Redis Hash 并公开种子。
+func (p *Project) Draw(ctx context.Context) error {
+	return db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		// CAS 占有开奖权
+		now := time.Now()
+		result := tx.Model(&Project{}).
+			Where("id = ? AND drawn_at IS NULL", p.ID).
+			Update("drawn_at", &now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		p.DrawnAt = &now
+
+		var entrants []uint64
+		if err := tx.Model(&ProjectEntry{}).
+			Where("project_id = ?", p.ID).
+			Pluck("user_id", &entrants).Error; err != nil {
+			return err
+		}
+		var itemIDs []uint64
+		if err := tx.Model(&ProjectItem{}).
+			Where("project_id = ? AND receiver_id IS NULL", p.ID).
+			Order("id ASC").
+			Pluck("id", &itemIDs).Error; err != nil {
+			return err
+		}
+
+		winners := drawWinners(p.DrawSeed, entrants, len(itemIDs))
+		if len(winners) <= 0 {
+			logger.InfoF(ctx, "项目[%s]开奖完成，无人报名", p.ID)
+			return nil
+		}
+
+		var users []oauth.User
+		if err := tx.Select("id, username").Where("id IN ?", winners).Find(&users).Error; err != nil {
+			return err
+		}
+		usernames := make(map[uint64]string, len(users))
+		for _, user := range users {
+			usernames[user.ID] = user.Username
+		}
+
+		itemUserMap := make(map[string]interface{}, len(winners))
+		for i, winner := range winners {
+			username, ok := usernames[winner]
+			if !ok {
+				return fmt.Errorf("中奖用户[%d]不存在", winner)
+			}
+			itemUserMap[username] = itemIDs[i]
+		}
+		if err := tx.Model(&ProjectEntry{}).
+			Where("project_id = ? AND user_id IN ?", p.ID, winners).
+			Update("is_winner", true).Error; err != nil {
+			return err
+		}
+
+		// push winners to redis
+		if err := db.Redis.HSet(ctx, p.ItemsKey(), itemUserMap).Err(); err != nil {
+			return err
+		}
+		logger.InfoF(ctx, "项目[%s]开奖完成，报名 %d 人，中奖 %d 人", p.ID, len(entrants), len(winners))
+		return nil
+	})
+}
+
+// HandleDrawLottery 处理平台抽奖开奖任务
+func HandleDrawLottery(ctx context.Context, t *asynq.Task) error {
+	var payload drawTaskPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("解析任务参数失败: %w", err)
+	}
+
+	var p Project
+	if err := db.DB(ctx).Where("id = ?", payload.ProjectID).First(&p).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.InfoF(ctx, "项目[%s]不存在，跳过开奖", payload.ProjectID)
+			return nil
+		}
+		return err
+	}
+	if p.DistributionType != DistributionTypeDraw || p.IsDrawn() {
+		return nil
+	}
+
+	// StartTime 被推迟时按新的时间重新下发
+	if time.Now().Before(p.StartTime) {
+		return p.EnqueueDraw(ctx)
+	}
+	if err := p.Draw(ctx); err != nil {
+		return err
+	}
+	p.NotifyWinners(ctx)
+	return nil
+}
+
+// NotifyWinners 向尚未领取的中奖者发送论坛私信，需在写入中奖者的事务提交后调用;私信按中奖者去重，重复调用无副作用
+func (p *Project) NotifyWinners(ctx context.Context) {
+	if !p.IsWinnerBased() || !config.Config.ForumNotify.Enabled {
+		return
+	}
+	usernames, err := db.Redis.HKeys(ctx, p.ItemsKey()).Result()
+	if err != nil {
+		logger.ErrorF(ctx, "获取项目[%s]中奖者失败: %v", p.ID, err)
+		return
+	}
+	for _, username := range usernames {
+		forum.EnqueuePrivateMessage(ctx, forum.KindWinner, forum.WinnerKey(p.ID, username), username, p.ID, p.Name)
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"testing"
+)
+
+func TestDrawCommitment(t *testing.T) {
+	seed, commitment, err := genDrawSeed()
+	if err != nil {
+		t.Fatalf("gen seed: %v", err)
+	}
+	if len(seed) != 64 || len(commitment) != 64 {
+		t.Fatalf("want 64-char hex seed and commitment, got %d/%d", len(seed), len(commitment))
+	}
+	if drawCommitment(seed) != commitment {
+		t.Fatalf("commitment must be reproducible from seed")
+	}
+}
+
+func TestDrawWinnersDeterministic(t *testing.T) {
+	entrants := []uint64{5, 3, 9, 1, 7, 2}
+	reordered := []uint64{9, 7, 5, 3, 2, 1}
+
+	a := drawWinners("seed", entrants, 3)
+	b := drawWinners("seed", reordered, 3)
+	if len(a) != 3 {
+		t.Fatalf("want 3 winners, got %d", len(a))
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("winners must not depend on entrant order: %v vs %v", a, b)
+		}
+	}
+	if entrants[0] != 5 {
+		t.Fatalf("entrants must not be mutated")
+	}
+}
+
+func TestDrawWinnersUnique(t *testing.T) {
+	entrants := []uint64{1, 2, 3, 4}
+	winners := drawWinners("another-seed", entrants, 10)
+	if len(winners) != len(entrants) {
+		t.Fatalf("winner count must be capped by entrants, got %d", len(winners))
+	}
+	seen := make(map[uint64]bool)
+	for _, w := range winners {
+		if seen[w] {
+			t.Fatalf("duplicate winner %d", w)
+		}
+		seen[w] = true
+	}
+}
@@ -38,11 +38,73 @@ const (
 	AlreadyReported    = "已举报过当前项目"
 	RequirementsFailed = "未达到项目发起者设置的条件"
 	TooManyRequests    = "创建项目太频繁，请稍后再试"
+	// 领取上限相关
+	ReceiveLimitReached    = "已达到领取上限"
+	ClaimNotFound          = "领取记录不存在"
+	MaxPerUserInvalid      = "每人领取上限需在 1 到 %d 之间"
+	MaxPerUserLevelInvalid = "无效的信任等级 %d"
+	// Waitlist 相关
+	WaitlistNotSupported  = "仅一码一用项目支持候补"
+	WaitlistHasStock      = "当前仍有库存，请直接领取"
+	WaitlistAlreadyJoined = "已在候补队列中"
+	WaitlistNotJoined     = "未加入候补队列"
+	WaitlistOrderPending  = "已为你保留库存并创建待支付订单，请完成支付或等待订单过期"
+	WaitlistNoHold        = "暂无为你保留的库存"
+	// Recurrence 相关
+	RecurrenceInvalid        = "周期表达式无效: %v"
+	RecurrenceOverlap        = "单轮时长不能超过两次开启的间隔"
+	RecurrenceOneForEachOnly = "仅一码一用项目支持周期开启"
+	// Template 相关
+	TemplateNotFound     = "模板不存在"
+	TemplateLimitReached = "模板数量已达上限"
+	// Invite 相关
+	InviteRequired       = "该项目仅限邀请码领取"
+	InviteInvalid        = "邀请码无效或已过期"
+	InviteAlreadyUsed    = "邀请码已被使用，无法撤销"
+	InviteAlreadyRevoked = "邀请码已撤销"
+	InviteExpireInvalid  = "邀请码过期时间必须晚于当前时间"
+	InviteOnlyForInvite  = "仅邀请码分发项目支持生成邀请码"
+	// Draw 相关
+	DrawOnly       = "仅平台抽奖项目支持报名"
+	EntryClosed    = "报名已截止"
+	AlreadyEntered = "已报名当前项目"
+	// Topic 回帖相关
+	AnnounceOnlyForLottery = "仅关联抽奖话题的抽奖项目支持回帖公告"
+	AnnounceTopicNotOwned  = "仅能向自己发起的抽奖话题回帖公告"
+	// Item 导入导出相关
+	ImportNotSupported  = "抽奖项目不支持导入库存"
+	ImportFileRequired  = "请上传待导入的文件"
+	ImportFileInvalid   = "导入文件读取失败"
+	ImportLineEmpty     = "内容为空"
+	ImportLineTooLong   = "内容超过 %d 个字符"
+	ImportLineExists    = "与已有内容重复"
+	ImportLineRepeated  = "与文件中第 %d 行重复"
+	ExportFormatInvalid = "导出格式仅支持 csv 或 jsonl"
+	// Item 管理相关
+	ItemDeleteNotSupported = "抽奖项目不支持删除库存"
+	ItemSelectorRequired   = "请指定待删除的 item ID 或内容"
+	ItemNotReceived        = "仅支持替换已领取的内容"
+	// Item 加密相关
+	ItemEncryptionKeyMissing = "未配置版本为 %s 的内容加密密钥"
+	ItemEncryptionKeyInvalid = "版本为 %s 的内容加密密钥须恰好 32 字节(原文或 base64)"
+	ItemHashKeyMissing       = "启用内容加密时必须配置 item_hash_key"
+	ItemHashKeyInvalid       = "item_hash_key 须恰好 32 字节(原文或 base64)"
+	ItemContentCorrupted     = "加密内容格式错误"
 	// Payment 相关
 	InvalidPrice         = "金额必须大于等于 0"
-	InvalidPriceDecimals = "金额最多保留 2 位小数"
+	InvalidPriceDecimals = "%s 金额最多保留 %d 位小数"
 	PriceTooLarge        = "金额超出允许范围"
 	PriceOnlyOneForEach  = "仅一码一用分发支持设置金额"
 	PaymentDisabled      = "平台支付功能未启用"
 	CreatorNotConfigured = "请先在账户设置中配置支付凭据"
+	CreatorNotVerified   = "支付凭据尚未通过校验，请在账户设置中重新保存或校验"
+	CurrencyInvalid      = "不支持的币种"
+	CurrencyMismatch     = "项目币种需与支付配置的币种 %s 一致"
+	// Pricing 相关
+	PricingRequiresPrice    = "仅付费项目支持设置定价规则"
+	PricingPriceNotPositive = "定价规则中的金额必须大于 0"
+	PricingEarlyBirdInvalid = "早鸟价与早鸟截止时间需同时设置"
+	PricingDiscountInvalid  = "信任等级折扣需在 1 到 99 之间"
+	PricingStepInvalid      = "库存阶梯的剩余数量需大于 0 且不能重复"
+	PricingTooManySteps     = "库存阶梯最多 %d 档"
 )
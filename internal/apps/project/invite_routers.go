@@ -0,0 +1,179 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/logger"
+	"gorm.io/gorm"
+)
+
+type ListProjectInvitesRequest struct {
+	Current int `json:"current" form:"current" binding:"min=1"`
+	Size    int `json:"size" form:"size" binding:"min=1,max=100"`
+}
+
+type ListProjectInvitesResponseData struct {
+	Total   int64           `json:"total"`
+	Results []ProjectInvite `json:"results"`
+}
+
+type ListProjectInvitesResponse struct {
+	ErrorMsg string                         `json:"error_msg"`
+	Data     ListProjectInvitesResponseData `json:"data"`
+}
+
+// ListProjectInvites
+// @Tags project
+// @Produce json
+// @Param id path string true "项目ID"
+// @Param request query ListProjectInvitesRequest true "request query"
+// @Success 200 {object} ListProjectInvitesResponse
+// @Router /api/v1/projects/{id}/invites [get]
+func ListProjectInvites(c *gin.Context) {
+	// load project
+	project, _ := GetProjectFromContext(c)
+
+	// validate req
+	req := &ListProjectInvitesRequest{}
+	if err := c.ShouldBindQuery(req); err != nil {
+		c.JSON(http.StatusBadRequest, ListProjectInvitesResponse{ErrorMsg: err.Error()})
+		return
+	}
+	offset := (req.Current - 1) * req.Size
+
+	query := db.DB(c.Request.Context()).Model(&ProjectInvite{}).Where("project_id = ?", project.ID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ListProjectInvitesResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	var invites []ProjectInvite
+	if err := query.Order("id DESC").Offset(offset).Limit(req.Size).Find(&invites).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ListProjectInvitesResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListProjectInvitesResponse{
+		Data: ListProjectInvitesResponseData{Total: total, Results: invites},
+	})
+}
+
+type CreateProjectInvitesRequestBody struct {
+	Count    int       `json:"count" binding:"required,min=1,max=10000"`
+	ExpireAt time.Time `json:"expire_at"`
+}
+
+// CreateProjectInvites
+// @Tags project
+// @Accept json
+// @Produce json
+// @Param id path string true "项目ID"
+// @Param invites body CreateProjectInvitesRequestBody true "邀请码生成参数"
+// @Success 200 {object} ProjectResponse{data=[]ProjectInvite}
+// @Router /api/v1/projects/{id}/invites [post]
+func CreateProjectInvites(c *gin.Context) {
+	// validate req
+	var req CreateProjectInvitesRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// load project
+	project, _ := GetProjectFromContext(c)
+	if !project.IsInviteOnly() {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: InviteOnlyForInvite})
+		return
+	}
+	expireAt := req.ExpireAt
+	if expireAt.IsZero() {
+		expireAt = project.EndTime
+	}
+
+	// create invites
+	var invites []ProjectInvite
+	if err := db.DB(c.Request.Context()).Transaction(
+		func(tx *gorm.DB) error {
+			var err error
+			invites, err = project.CreateInvites(c.Request.Context(), tx, req.Count, expireAt)
+			return err
+		},
+	); err != nil {
+		c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ProjectResponse{Data: invites})
+}
+
+// RevokeProjectInvite
+// @Tags project
+// @Produce json
+// @Param id path string true "项目ID"
+// @Param invite_id path int true "邀请码ID"
+// @Success 200 {object} ProjectResponse
+// @Router /api/v1/projects/{id}/invites/{invite_id} [delete]
+func RevokeProjectInvite(c *gin.Context) {
+	inviteID, err := strconv.ParseUint(c.Param("invite_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// load project
+	project, _ := GetProjectFromContext(c)
+
+	// do revoke
+	var invite *ProjectInvite
+	if err := db.DB(c.Request.Context()).Transaction(
+		func(tx *gorm.DB) error {
+			invite, err = project.RevokeInvite(tx, inviteID)
+			return err
+		},
+	); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ProjectResponse{ErrorMsg: err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// 提交后再清理索引，数据库记录已是权威状态,失败仅影响快速拒绝
+	if err := project.DropInviteIndex(c.Request.Context(), invite.Token); err != nil {
+		logger.ErrorF(c.Request.Context(), "[RevokeProjectInvite] 清理邀请码[%d]索引失败: %v", invite.ID, err)
+	}
+
+	c.JSON(http.StatusOK, ProjectResponse{})
+}
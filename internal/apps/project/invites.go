@@ -0,0 +1,192 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// ProjectInvite 邀请码分发模式下的单次邀请凭证
+type ProjectInvite struct {
+	ID        uint64     `json:"id" gorm:"primaryKey,autoIncrement"`
+	ProjectID string     `json:"project_id" gorm:"size:64;index"`
+	Token     string     `json:"token" gorm:"size:64;uniqueIndex"`
+	ExpireAt  time.Time  `json:"expire_at"`
+	UsedBy    *uint64    `json:"used_by" gorm:"index"`
+	UsedAt    *time.Time `json:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// IsInviteOnly 是否为邀请码分发项目
+func (p *Project) IsInviteOnly() bool {
+	return p.DistributionType == DistributionTypeInvite
+}
+
+// InviteKey 邀请码在 Redis 中的索引 key,value 为邀请记录 ID,TTL 与邀请码有效期一致
+func (p *Project) InviteKey(token string) string {
+	return fmt.Sprintf("project:%s:invite:%s", p.ID, token)
+}
+
+// genInviteToken 生成 24 位随机十六进制邀请码
+func genInviteToken() (string, error) {
+	var b [12]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// CreateInvites 为项目批量生成单次使用的邀请码,并写入 Redis 索引。
+func (p *Project) CreateInvites(ctx context.Context, tx *gorm.DB, count int, expireAt time.Time) ([]ProjectInvite, error) {
+	if count <= 0 {
+		return nil, nil
+	}
+	ttl := time.Until(expireAt)
+	if ttl <= 0 {
+		return nil, errors.New(InviteExpireInvalid)
+	}
+
+	invites := make([]ProjectInvite, count)
+	for i := range invites {
+		token, err := genInviteToken()
+		if err != nil {
+			return nil, err
+		}
+		invites[i] = ProjectInvite{ProjectID: p.ID, Token: token, ExpireAt: expireAt}
+	}
+	if err := tx.CreateInBatches(&invites, projectItemInsertBatchSize).Error; err != nil {
+		return nil, err
+	}
+
+	// push invites to redis
+	pipe := db.Redis.Pipeline()
+	for _, invite := range invites {
+		pipe.Set(ctx, p.InviteKey(invite.Token), invite.ID, ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
+
+// LoadInvite 校验邀请码是否可用:先查 Redis 索引快速拒绝,再以数据库记录为准。
+func (p *Project) LoadInvite(ctx context.Context, token string) (*ProjectInvite, error) {
+	if token == "" {
+		return nil, errors.New(InviteRequired)
+	}
+	if _, err := db.Redis.Get(ctx, p.InviteKey(token)).Result(); errors.Is(err, redis.Nil) {
+		return nil, errors.New(InviteInvalid)
+	} else if err != nil {
+		return nil, err
+	}
+
+	invite := &ProjectInvite{}
+	err := db.DB(ctx).
+		Where("project_id = ? AND token = ? AND used_by IS NULL AND revoked_at IS NULL AND expire_at > ?", p.ID, token, time.Now()).
+		First(invite).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New(InviteInvalid)
+	} else if err != nil {
+		return nil, err
+	}
+	return invite, nil
+}
+
+// ConsumeInvite 在领取事务中通过 CAS 核销邀请码,需与 FulfillForReceiver 共用同一个 tx,
+// 任一失败都会回滚，保证邀请码与 item 同时生效。Redis 索引需在事务提交后由调用方通过 DropInviteIndex 清理。
+func (p *Project) ConsumeInvite(ctx context.Context, tx *gorm.DB, invite *ProjectInvite, receiverID uint64) error {
+	now := time.Now()
+	result := tx.Model(&ProjectInvite{}).
+		Where("id = ? AND used_by IS NULL AND revoked_at IS NULL AND expire_at > ?", invite.ID, now).
+		Updates(map[string]interface{}{"used_by": receiverID, "used_at": &now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(InviteInvalid)
+	}
+	return nil
+}
+
+// DropInviteIndex 删除邀请码的 Redis 索引,须在核销/撤销事务提交后调用，避免回滚后邀请码被提前作废
+func (p *Project) DropInviteIndex(ctx context.Context, token string) error {
+	return db.Redis.Del(ctx, p.InviteKey(token)).Err()
+}
+
+// RevokeInvite 撤销未使用且未撤销的邀请码,返回被撤销的记录供调用方在提交后清理 Redis 索引;
+// 通过 CAS 更新避免与核销或重复撤销并发时覆盖已有状态
+func (p *Project) RevokeInvite(tx *gorm.DB, inviteID uint64) (*ProjectInvite, error) {
+	invite := &ProjectInvite{}
+	if err := tx.Where("id = ? AND project_id = ?", inviteID, p.ID).First(invite).Error; err != nil {
+		return nil, err
+	}
+	if invite.UsedBy != nil {
+		return nil, errors.New(InviteAlreadyUsed)
+	}
+	if invite.RevokedAt != nil {
+		return nil, errors.New(InviteAlreadyRevoked)
+	}
+	now := time.Now()
+	result := tx.Model(&ProjectInvite{}).
+		Where("id = ? AND used_by IS NULL AND revoked_at IS NULL", invite.ID).
+		Update("revoked_at", &now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(InviteInvalid)
+	}
+	invite.RevokedAt = &now
+	return invite, nil
+}
+
+// DeleteInvites 删除项目下全部邀请码及其 Redis 索引
+func (p *Project) DeleteInvites(ctx context.Context, tx *gorm.DB) error {
+	var tokens []string
+	if err := tx.Model(&ProjectInvite{}).Where("project_id = ?", p.ID).Pluck("token", &tokens).Error; err != nil {
+		return err
+	}
+	if len(tokens) <= 0 {
+		return nil
+	}
+	if err := tx.Where("project_id = ?", p.ID).Delete(&ProjectInvite{}).Error; err != nil {
+		return err
+	}
+	keys := make([]string, len(tokens))
+	for i, token := range tokens {
+		keys[i] = p.InviteKey(token)
+	}
+	return db.Redis.Del(ctx, keys...).Err()
+}
@@ -0,0 +1,184 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/db"
+	"gorm.io/gorm"
+)
+
+// createInviteProject 创建带库存的邀请码项目并生成 count 个邀请码
+func createInviteProject(t *testing.T, count int, contents ...string) (*Project, []ProjectInvite) {
+	t.Helper()
+	ctx := context.Background()
+	if err := db.DB(ctx).AutoMigrate(&ProjectInvite{}); err != nil {
+		t.Fatalf("migrate invites: %v", err)
+	}
+	users := []*oauth.User{
+		{ID: 1, Username: "alice", Score: oauth.BaseUserScore},
+		{ID: 2, Username: "bob", Score: oauth.BaseUserScore},
+	}
+	if err := db.DB(ctx).Create(users).Error; err != nil {
+		t.Fatalf("create users: %v", err)
+	}
+	p := &Project{DistributionType: DistributionTypeInvite, AllowSameIP: true}
+	createStockedProject(t, p, contents...)
+	invites, err := p.CreateInvites(ctx, db.DB(ctx), count, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("create invites: %v", err)
+	}
+	return p, invites
+}
+
+// receiveWithInvite 按 DispatchReceive 的流程在同一事务内核销邀请码并发放 item,提交后清理索引
+func receiveWithInvite(ctx context.Context, p *Project, invite *ProjectInvite, userID uint64) error {
+	id, err := db.Redis.LPop(ctx, p.ItemsKey()).Uint64()
+	if err != nil {
+		return err
+	}
+	var item ProjectItem
+	if err := item.Exact(db.DB(ctx), id); err != nil {
+		return err
+	}
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := p.ConsumeInvite(ctx, tx, invite, userID); err != nil {
+			return err
+		}
+		return p.FulfillForReceiver(ctx, tx, &item, userID, "203.0.113.7")
+	}); err != nil {
+		db.Redis.RPush(ctx, p.ItemsKey(), id)
+		return err
+	}
+	return p.DropInviteIndex(ctx, invite.Token)
+}
+
+func TestConsumeInvite(t *testing.T) {
+	setupItemStore(t)
+	ctx := context.Background()
+	p, invites := createInviteProject(t, 1, "KEY-1", "KEY-2")
+
+	invite, err := p.LoadInvite(ctx, invites[0].Token)
+	if err != nil {
+		t.Fatalf("load invite: %v", err)
+	}
+	if err := receiveWithInvite(ctx, p, invite, 1); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	var stored ProjectInvite
+	db.DB(ctx).First(&stored, invite.ID)
+	if stored.UsedBy == nil || *stored.UsedBy != 1 || stored.UsedAt == nil {
+		t.Fatalf("want invite consumed by user 1, got %+v", stored)
+	}
+
+	// 已核销的邀请码无法再次加载，并发持有旧记录的二次核销被 CAS 拒绝且不发放 item
+	if _, err := p.LoadInvite(ctx, invite.Token); err == nil || err.Error() != InviteInvalid {
+		t.Fatalf("want %q after consume, got %v", InviteInvalid, err)
+	}
+	if err := receiveWithInvite(ctx, p, invite, 2); err == nil || err.Error() != InviteInvalid {
+		t.Fatalf("want double consume rejected with %q, got %v", InviteInvalid, err)
+	}
+	if stock, err := p.Stock(ctx); err != nil || stock != 1 {
+		t.Fatalf("want rejected claim to return stock, got %d (%v)", stock, err)
+	}
+}
+
+func TestConsumeInviteRollback(t *testing.T) {
+	setupItemStore(t)
+	ctx := context.Background()
+	p, invites := createInviteProject(t, 1, "KEY-1")
+	invite := &invites[0]
+
+	// 发放失败时核销随事务回滚，邀请码仍可使用
+	errFulfill := errors.New("fulfill failed")
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := p.ConsumeInvite(ctx, tx, invite, 1); err != nil {
+			return err
+		}
+		return errFulfill
+	}); !errors.Is(err, errFulfill) {
+		t.Fatalf("want fulfill error, got %v", err)
+	}
+	if _, err := p.LoadInvite(ctx, invite.Token); err != nil {
+		t.Fatalf("want invite usable after rollback, got %v", err)
+	}
+}
+
+func TestRevokeInvite(t *testing.T) {
+	setupItemStore(t)
+	ctx := context.Background()
+	p, invites := createInviteProject(t, 2, "KEY-1", "KEY-2")
+
+	revoke := func(id uint64) (*ProjectInvite, error) {
+		var revoked *ProjectInvite
+		err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			revoked, err = p.RevokeInvite(tx, id)
+			return err
+		})
+		return revoked, err
+	}
+
+	revoked, err := revoke(invites[0].ID)
+	if err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if err := p.DropInviteIndex(ctx, revoked.Token); err != nil {
+		t.Fatalf("drop index: %v", err)
+	}
+	var stored ProjectInvite
+	db.DB(ctx).First(&stored, invites[0].ID)
+	revokedAt := stored.RevokedAt
+
+	// 重复撤销返回错误且不改写撤销时间
+	if _, err := revoke(invites[0].ID); err == nil || err.Error() != InviteAlreadyRevoked {
+		t.Fatalf("want %q on second revoke, got %v", InviteAlreadyRevoked, err)
+	}
+	db.DB(ctx).First(&stored, invites[0].ID)
+	if revokedAt == nil || !stored.RevokedAt.Equal(*revokedAt) {
+		t.Fatalf("want revoked_at unchanged, got %v -> %v", revokedAt, stored.RevokedAt)
+	}
+
+	// 撤销后无法加载，持有旧记录的核销也被拒绝
+	if _, err := p.LoadInvite(ctx, invites[0].Token); err == nil || err.Error() != InviteInvalid {
+		t.Fatalf("want %q after revoke, got %v", InviteInvalid, err)
+	}
+	if err := receiveWithInvite(ctx, p, &invites[0], 1); err == nil || err.Error() != InviteInvalid {
+		t.Fatalf("want consume after revoke rejected, got %v", err)
+	}
+
+	// 已使用的邀请码不能撤销
+	if err := receiveWithInvite(ctx, p, &invites[1], 2); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	if _, err := revoke(invites[1].ID); err == nil || err.Error() != InviteAlreadyUsed {
+		t.Fatalf("want %q for used invite, got %v", InviteAlreadyUsed, err)
+	}
+}
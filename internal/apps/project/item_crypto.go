@@ -0,0 +1,192 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/linux-do/cdk/internal/config"
+	"github.com/linux-do/cdk/internal/utils"
+	"gorm.io/gorm"
+)
+
+// itemContentEncPrefix 加密内容前缀，完整格式为 enc:<密钥版本>:<base64 密文>,无前缀的内容视为明文;
+// 未启用加密时以该前缀开头的明文会转义为 enc::<明文>(空版本号),避免被误当作密文解密
+const itemContentEncPrefix = "enc:"
+
+// itemContentHashKey 返回内容去重哈希的专用密钥，不随内容加密密钥或支付凭据密钥轮换;未配置时返回 nil
+func itemContentHashKey() ([]byte, error) {
+	raw := config.Config.Payment.ItemHashKey
+	if raw == "" {
+		return nil, nil
+	}
+	key, err := utils.ParseAESKey(raw)
+	if err != nil {
+		return nil, errors.New(ItemHashKeyInvalid)
+	}
+	return key, nil
+}
+
+// itemContentKey 按版本号加载 CDK 内容加密密钥，要求恰好 32 字节，不做填充派生
+func itemContentKey(version string) ([]byte, error) {
+	raw, ok := config.Config.Payment.ItemEncryptionKeys[version]
+	if !ok || raw == "" || strings.Contains(version, ":") {
+		return nil, fmt.Errorf(ItemEncryptionKeyMissing, version)
+	}
+	key, err := utils.ParseAESKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf(ItemEncryptionKeyInvalid, version)
+	}
+	return key, nil
+}
+
+// ValidateItemKeys 启动时校验 CDK 内容加密与去重哈希密钥配置，密钥格式错误或启用加密而缺少 item_hash_key 时返回错误
+func ValidateItemKeys() error {
+	for version := range config.Config.Payment.ItemEncryptionKeys {
+		if _, err := itemContentKey(version); err != nil {
+			return err
+		}
+	}
+	hashKey, err := itemContentHashKey()
+	if err != nil {
+		return err
+	}
+	version := config.Config.Payment.ItemEncryptionKeyVersion
+	if version == "" {
+		return nil
+	}
+	if hashKey == nil {
+		return errors.New(ItemHashKeyMissing)
+	}
+	_, err = itemContentKey(version)
+	return err
+}
+
+// EncryptItemContent 使用当前版本密钥加密 CDK 内容，未启用加密时原样返回(以加密前缀开头的明文会被转义)
+func EncryptItemContent(plain string) (string, error) {
+	version := config.Config.Payment.ItemEncryptionKeyVersion
+	if version == "" {
+		if strings.HasPrefix(plain, itemContentEncPrefix) {
+			return itemContentEncPrefix + ":" + plain, nil
+		}
+		return plain, nil
+	}
+	if hashKey, err := itemContentHashKey(); err != nil {
+		return "", err
+	} else if hashKey == nil {
+		return "", errors.New(ItemHashKeyMissing)
+	}
+	key, err := itemContentKey(version)
+	if err != nil {
+		return "", err
+	}
+	ct, err := utils.EncryptAESGCM(plain, key)
+	if err != nil {
+		return "", err
+	}
+	return itemContentEncPrefix + version + ":" + ct, nil
+}
+
+// DecryptItemContent 按内容携带的密钥版本解密，明文内容原样返回
+func DecryptItemContent(stored string) (string, error) {
+	if !strings.HasPrefix(stored, itemContentEncPrefix) {
+		return stored, nil
+	}
+	version, ct, ok := strings.Cut(strings.TrimPrefix(stored, itemContentEncPrefix), ":")
+	if !ok {
+		return "", errors.New(ItemContentCorrupted)
+	}
+	// 空版本号为转义后的明文
+	if version == "" {
+		return ct, nil
+	}
+	key, err := itemContentKey(version)
+	if err != nil {
+		return "", err
+	}
+	return utils.DecryptAESGCM(ct, key)
+}
+
+// ItemContentHash 计算 CDK 内容的带密钥哈希，用于加密后仍能去重和按内容定位;
+// 以专用的 ItemHashKey 为密钥，不随内容加密密钥或支付凭据密钥轮换而变化
+func ItemContentHash(plain string) (string, error) {
+	key, err := itemContentHashKey()
+	if err != nil {
+		return "", err
+	}
+	return utils.HMACSHA256Hex(key, plain), nil
+}
+
+// PlainContent 返回解密后的内容，仅在向领取人或创建者展示时调用
+func (i *ProjectItem) PlainContent() (string, error) {
+	return DecryptItemContent(i.Content)
+}
+
+// newProjectItem 构造待入库的 item,按配置加密内容并写入内容哈希
+func newProjectItem(projectID string, content string) (ProjectItem, error) {
+	enc, err := EncryptItemContent(content)
+	if err != nil {
+		return ProjectItem{}, err
+	}
+	hash, err := ItemContentHash(content)
+	if err != nil {
+		return ProjectItem{}, err
+	}
+	return ProjectItem{ProjectID: projectID, Content: enc, ContentHash: hash}, nil
+}
+
+// existingContentHashes 返回项目已有 item 的内容哈希集合;未写入哈希的存量明文数据即时计算
+func (p *Project) existingContentHashes(tx *gorm.DB) (map[string]bool, error) {
+	var rows []struct {
+		Content     string
+		ContentHash string
+	}
+	if err := tx.Model(&ProjectItem{}).
+		Select("content, content_hash").
+		Where("project_id = ?", p.ID).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		if row.ContentHash != "" {
+			hashes[row.ContentHash] = true
+			continue
+		}
+		plain, err := DecryptItemContent(row.Content)
+		if err != nil {
+			return nil, err
+		}
+		hash, err := ItemContentHash(plain)
+		if err != nil {
+			return nil, err
+		}
+		hashes[hash] = true
+	}
+	return hashes, nil
+}
@@ -0,0 +1,172 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/linux-do/cdk/internal/config"
+)
+
+func TestItemContentEncryptionRoundTrip(t *testing.T) {
+	payment := config.Config.Payment
+	defer func() { config.Config.Payment = payment }()
+
+	config.Config.Payment.ItemHashKey = "0123456789abcdef0123456789abcdef"
+	config.Config.Payment.ItemEncryptionKeys = map[string]string{
+		"v1": "v1-item-key-v1-item-key-v1-item-",
+		"v2": "v2-item-key-v2-item-key-v2-item-",
+	}
+	config.Config.Payment.ItemEncryptionKeyVersion = "v1"
+
+	old, err := EncryptItemContent("CDK-001")
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if !strings.HasPrefix(old, "enc:v1:") {
+		t.Fatalf("want v1 tagged ciphertext, got %s", old)
+	}
+
+	// 轮换后旧版本内容仍可解密
+	config.Config.Payment.ItemEncryptionKeyVersion = "v2"
+	rotated, err := EncryptItemContent("CDK-001")
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if !strings.HasPrefix(rotated, "enc:v2:") {
+		t.Fatalf("want v2 tagged ciphertext, got %s", rotated)
+	}
+	for _, stored := range []string{old, rotated} {
+		plain, err := DecryptItemContent(stored)
+		if err != nil {
+			t.Fatalf("decrypt: %v", err)
+		}
+		if plain != "CDK-001" {
+			t.Fatalf("want CDK-001, got %s", plain)
+		}
+	}
+}
+
+func TestItemContentPlaintextPassthrough(t *testing.T) {
+	payment := config.Config.Payment
+	defer func() { config.Config.Payment = payment }()
+
+	config.Config.Payment.ItemEncryptionKeyVersion = ""
+	stored, err := EncryptItemContent("CDK-002")
+	if err != nil || stored != "CDK-002" {
+		t.Fatalf("want plaintext passthrough, got %q, %v", stored, err)
+	}
+	plain, err := DecryptItemContent(stored)
+	if err != nil || plain != "CDK-002" {
+		t.Fatalf("want plaintext passthrough, got %q, %v", plain, err)
+	}
+	first, _ := ItemContentHash("CDK-002")
+	again, _ := ItemContentHash("CDK-002")
+	other, _ := ItemContentHash("CDK-003")
+	if first != again || first == other {
+		t.Fatalf("content hash must be deterministic and distinct")
+	}
+}
+
+func TestItemContentPlaintextWithEncPrefix(t *testing.T) {
+	payment := config.Config.Payment
+	defer func() { config.Config.Payment = payment }()
+
+	config.Config.Payment.ItemEncryptionKeyVersion = ""
+	for _, content := range []string{"enc:v1:looks-like-ciphertext", "enc:", "enc::x"} {
+		stored, err := EncryptItemContent(content)
+		if err != nil {
+			t.Fatalf("encrypt %q: %v", content, err)
+		}
+		plain, err := DecryptItemContent(stored)
+		if err != nil || plain != content {
+			t.Fatalf("want %q round trip, got %q, %v", content, plain, err)
+		}
+	}
+}
+
+func TestItemContentHashKeyIndependentOfConfigKey(t *testing.T) {
+	payment := config.Config.Payment
+	defer func() { config.Config.Payment = payment }()
+
+	config.Config.Payment.ItemHashKey = "0123456789abcdef0123456789abcdef"
+	config.Config.Payment.ConfigEncryptionKey = "0123456789abcdef0123456789abcdef"
+	before, err := ItemContentHash("CDK-004")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+
+	// 轮换支付凭据密钥不影响哈希
+	config.Config.Payment.ConfigEncryptionKey = "fedcba9876543210fedcba9876543210"
+	if after, err := ItemContentHash("CDK-004"); err != nil || after != before {
+		t.Fatalf("hash changed after config key rotation: %s != %s (%v)", after, before, err)
+	}
+
+	// 启用加密时必须配置专用哈希密钥，不回退到支付凭据密钥
+	config.Config.Payment.ItemEncryptionKeyVersion = "v1"
+	config.Config.Payment.ItemEncryptionKeys = map[string]string{"v1": "v1-item-key-v1-item-key-v1-item-"}
+	config.Config.Payment.ItemHashKey = ""
+	if _, err := EncryptItemContent("CDK-004"); err == nil || err.Error() != ItemHashKeyMissing {
+		t.Fatalf("want %q, got %v", ItemHashKeyMissing, err)
+	}
+	if err := ValidateItemKeys(); err == nil || err.Error() != ItemHashKeyMissing {
+		t.Fatalf("want %q at startup, got %v", ItemHashKeyMissing, err)
+	}
+}
+
+func TestValidateItemKeys(t *testing.T) {
+	payment := config.Config.Payment
+	defer func() { config.Config.Payment = payment }()
+
+	config.Config.Payment.ItemEncryptionKeyVersion = "v1"
+	config.Config.Payment.ItemEncryptionKeys = map[string]string{"v1": "v1-item-key-v1-item-key-v1-item-"}
+	config.Config.Payment.ItemHashKey = "0123456789abcdef0123456789abcdef"
+	if err := ValidateItemKeys(); err != nil {
+		t.Fatalf("want valid config accepted, got %v", err)
+	}
+
+	// 长度不足 32 字节的密钥不做填充派生，启动时拒绝
+	config.Config.Payment.ItemEncryptionKeys["v0"] = "short"
+	if err := ValidateItemKeys(); err == nil || err.Error() != fmt.Sprintf(ItemEncryptionKeyInvalid, "v0") {
+		t.Fatalf("want weak item key rejected, got %v", err)
+	}
+	delete(config.Config.Payment.ItemEncryptionKeys, "v0")
+
+	config.Config.Payment.ItemHashKey = "short"
+	if err := ValidateItemKeys(); err == nil || err.Error() != ItemHashKeyInvalid {
+		t.Fatalf("want weak hash key rejected, got %v", err)
+	}
+	if _, err := ItemContentHash("CDK-005"); err == nil {
+		t.Fatalf("want hashing with a weak key to fail")
+	}
+
+	config.Config.Payment.ItemHashKey = "0123456789abcdef0123456789abcdef"
+	config.Config.Payment.ItemEncryptionKeyVersion = "v9"
+	if err := ValidateItemKeys(); err == nil || err.Error() != fmt.Sprintf(ItemEncryptionKeyMissing, "v9") {
+		t.Fatalf("want missing current version rejected, got %v", err)
+	}
+}
@@ -0,0 +1,294 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/logger"
+	"gorm.io/gorm"
+)
+
+type ImportProjectItemsRequest struct {
+	EnableFilter bool `json:"enable_filter" form:"enable_filter"`
+}
+
+type ImportProjectItemsResponse struct {
+	ErrorMsg string           `json:"error_msg"`
+	Data     ItemImportReport `json:"data"`
+}
+
+// ImportProjectItems 以 multipart 上传 CSV/TXT 文件批量导入库存,每行一个内容,CSV 取第一列
+// @Tags project
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "项目ID"
+// @Param file formData file true "导入文件(.csv 或 .txt)"
+// @Param request query ImportProjectItemsRequest false "request query"
+// @Success 200 {object} ImportProjectItemsResponse
+// @Router /api/v1/projects/{id}/items/import [post]
+func ImportProjectItems(c *gin.Context) {
+	// validate req
+	req := &ImportProjectItemsRequest{}
+	if err := c.ShouldBindQuery(req); err != nil {
+		c.JSON(http.StatusBadRequest, ImportProjectItemsResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// load project
+	project, _ := GetProjectFromContext(c)
+	if project.IsWinnerBased() {
+		c.JSON(http.StatusBadRequest, ImportProjectItemsResponse{ErrorMsg: ImportNotSupported})
+		return
+	}
+
+	// find file part
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, itemImportMaxFileSize)
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ImportProjectItemsResponse{ErrorMsg: err.Error()})
+		return
+	}
+	var file io.Reader
+	var isCSV bool
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			c.JSON(http.StatusBadRequest, ImportProjectItemsResponse{ErrorMsg: err.Error()})
+			return
+		}
+		if part.FormName() == "file" {
+			file = part
+			isCSV = strings.EqualFold(filepath.Ext(part.FileName()), ".csv")
+			break
+		}
+	}
+	if file == nil {
+		c.JSON(http.StatusBadRequest, ImportProjectItemsResponse{ErrorMsg: ImportFileRequired})
+		return
+	}
+
+	// do import
+	var report *ItemImportReport
+	if err := db.DB(c.Request.Context()).Transaction(
+		func(tx *gorm.DB) error {
+			report, err = project.ImportItems(c.Request.Context(), tx, file, isCSV, req.EnableFilter)
+			return err
+		},
+	); err != nil {
+		if errors.Is(err, errImportFileInvalid) {
+			c.JSON(http.StatusBadRequest, ImportProjectItemsResponse{ErrorMsg: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ImportProjectItemsResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// 补充库存后分配给候补用户
+	if report.ImportedCount > 0 && project.IsWaitlistable() {
+		EnqueueWaitlistOffer(c.Request.Context(), project.ID)
+	}
+
+	c.JSON(http.StatusOK, ImportProjectItemsResponse{Data: *report})
+}
+
+type ExportProjectItemsRequest struct {
+	Format string `json:"format" form:"format" binding:"omitempty,oneof=csv jsonl"`
+}
+
+// ExportProjectItems 流式导出项目全部 item,包含领取人、领取时间及是否仍在库存中
+// @Tags project
+// @Produce plain
+// @Param id path string true "项目ID"
+// @Param request query ExportProjectItemsRequest false "request query"
+// @Success 200 {string} string "CSV 或 JSONL 文件"
+// @Failure 400 {object} ProjectResponse
+// @Router /api/v1/projects/{id}/items/export [get]
+func ExportProjectItems(c *gin.Context) {
+	// validate req
+	req := &ExportProjectItemsRequest{}
+	if err := c.ShouldBindQuery(req); err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: ExportFormatInvalid})
+		return
+	}
+	if req.Format == "" {
+		req.Format = "csv"
+	}
+
+	// load project
+	project, _ := GetProjectFromContext(c)
+
+	// write headers
+	contentType := "text/csv; charset=utf-8"
+	if req.Format == "jsonl" {
+		contentType = "application/x-ndjson; charset=utf-8"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="project-%s-items.%s"`, project.ID, req.Format))
+	c.Status(http.StatusOK)
+
+	// stream items
+	var err error
+	if req.Format == "jsonl" {
+		encoder := json.NewEncoder(c.Writer)
+		err = project.ExportItems(c.Request.Context(), func(item *ExportedItem) error {
+			return encoder.Encode(item)
+		})
+	} else {
+		writer := csv.NewWriter(c.Writer)
+		if err = writer.Write([]string{"id", "content", "receiver_username", "received_at", "in_stock"}); err == nil {
+			err = project.ExportItems(c.Request.Context(), func(item *ExportedItem) error {
+				receivedAt := ""
+				if item.ReceivedAt != nil {
+					receivedAt = item.ReceivedAt.Format(time.RFC3339)
+				}
+				return writer.Write([]string{
+					strconv.FormatUint(item.ID, 10),
+					item.Content,
+					item.ReceiverUsername,
+					receivedAt,
+					strconv.FormatBool(item.InStock),
+				})
+			})
+		}
+		writer.Flush()
+		if err == nil {
+			err = writer.Error()
+		}
+	}
+
+	// 响应头已发送，只能记录日志并中断连接
+	if err != nil {
+		logger.ErrorF(c.Request.Context(), "导出项目[%s]库存失败: %v", project.ID, err)
+		c.Abort()
+	}
+}
+
+type DeleteProjectItemsRequestBody struct {
+	ItemIDs  []uint64 `json:"item_ids" binding:"max=1000"`
+	Contents []string `json:"contents" binding:"max=1000,dive,min=1,max=1024"`
+}
+
+type DeleteProjectItemsResponseData struct {
+	DeletedIDs []uint64 `json:"deleted_ids"`
+	SkippedIDs []uint64 `json:"skipped_ids"`
+}
+
+type DeleteProjectItemsResponse struct {
+	ErrorMsg string                         `json:"error_msg"`
+	Data     DeleteProjectItemsResponseData `json:"data"`
+}
+
+// DeleteProjectItems 按 ID 或内容删除未领取的 item,被待支付订单预占的 item 会出现在 skipped_ids 中
+// @Tags project
+// @Accept json
+// @Produce json
+// @Param id path string true "项目ID"
+// @Param items body DeleteProjectItemsRequestBody true "待删除的 item"
+// @Success 200 {object} DeleteProjectItemsResponse
+// @Router /api/v1/projects/{id}/items [delete]
+func DeleteProjectItems(c *gin.Context) {
+	// validate req
+	var req DeleteProjectItemsRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, DeleteProjectItemsResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// load project
+	project, _ := GetProjectFromContext(c)
+
+	// do delete
+	var data DeleteProjectItemsResponseData
+	var err error
+	if data.DeletedIDs, data.SkippedIDs, err = project.DeleteUnreceivedItems(c.Request.Context(), req.ItemIDs, req.Contents); err != nil {
+		c.JSON(http.StatusBadRequest, DeleteProjectItemsResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, DeleteProjectItemsResponse{Data: data})
+}
+
+type ReplaceProjectItemRequestBody struct {
+	Content string `json:"content" binding:"required,min=1,max=1024"`
+	Reason  string `json:"reason" binding:"max=255"`
+}
+
+// ReplaceProjectItem 替换已领取 item 的内容，领取人可在领取记录中看到替换时间与原因
+// @Tags project
+// @Accept json
+// @Produce json
+// @Param id path string true "项目ID"
+// @Param item_id path int true "item ID"
+// @Param item body ReplaceProjectItemRequestBody true "新内容"
+// @Success 200 {object} ProjectResponse
+// @Router /api/v1/projects/{id}/items/{item_id} [put]
+func ReplaceProjectItem(c *gin.Context) {
+	itemID, err := strconv.ParseUint(c.Param("item_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// validate req
+	var req ReplaceProjectItemRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// load project
+	project, _ := GetProjectFromContext(c)
+
+	// do replace
+	if err := db.DB(c.Request.Context()).Transaction(
+		func(tx *gorm.DB) error {
+			_, err := project.ReplaceReceivedItemContent(tx, itemID, strings.TrimSpace(req.Content), req.Reason)
+			return err
+		},
+	); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ProjectResponse{ErrorMsg: err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ProjectResponse{})
+}
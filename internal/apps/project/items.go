@@ -0,0 +1,462 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/linux-do/cdk/internal/apps/notification"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/logger"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ItemImportReject 导入时被拒绝的行
+type ItemImportReject struct {
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+// ItemImportReport 导入结果报告
+type ItemImportReport struct {
+	TotalLines    int                `json:"total_lines"`
+	ImportedCount int64              `json:"imported_count"`
+	RejectedCount int                `json:"rejected_count"`
+	Rejects       []ItemImportReject `json:"rejects"`
+}
+
+func (r *ItemImportReport) reject(line int, reason string) {
+	r.RejectedCount++
+	if len(r.Rejects) < itemImportRejectReportLimit {
+		r.Rejects = append(r.Rejects, ItemImportReject{Line: line, Reason: reason})
+	}
+}
+
+// errImportFileInvalid 标记导入文件本身的读取/解析错误,与存储错误区分
+var errImportFileInvalid = errors.New(ImportFileInvalid)
+
+// scanImportLines 逐行流式解析导入文件,CSV 取每行第一列,TXT 取整行;首行 UTF-8 BOM 会被去除。
+// TXT 单行超过 itemImportLineMaxBytes 时丢弃该行剩余内容并以 overflow 回调，不中断整个导入。
+func scanImportLines(r io.Reader, isCSV bool, fn func(line int, content string, overflow bool) error) error {
+	trim := func(line int, content string) string {
+		if line == 1 {
+			content = strings.TrimPrefix(content, "\ufeff")
+		}
+		return strings.TrimSpace(content)
+	}
+
+	if isCSV {
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.LazyQuotes = true
+		reader.ReuseRecord = true
+		for {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("%w: %v", errImportFileInvalid, err)
+			}
+			line, _ := reader.FieldPos(0)
+			if err := fn(line, trim(line, record[0]), false); err != nil {
+				return err
+			}
+		}
+	}
+
+	reader := bufio.NewReaderSize(r, itemImportLineMaxBytes)
+	line := 0
+	for {
+		raw, readErr := reader.ReadSlice('\n')
+		overflow := errors.Is(readErr, bufio.ErrBufferFull)
+		for errors.Is(readErr, bufio.ErrBufferFull) {
+			_, readErr = reader.ReadSlice('\n')
+		}
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return fmt.Errorf("%w: %v", errImportFileInvalid, readErr)
+		}
+		if len(raw) <= 0 && !overflow {
+			return nil
+		}
+
+		line++
+		content := ""
+		if !overflow {
+			content = trim(line, string(raw))
+		}
+		if err := fn(line, content, overflow); err != nil {
+			return err
+		}
+		if readErr != nil {
+			return nil
+		}
+	}
+}
+
+// ImportItems 从文件流式导入 item:逐行校验、可选按已有内容去重,
+// 按 projectItemInsertBatchSize 分批写库，并以 pipeline 分批推入 Redis 库存队列。
+// 调用方需在同一事务中执行，任一批次失败都会整体回滚。
+func (p *Project) ImportItems(ctx context.Context, tx *gorm.DB, r io.Reader, isCSV bool, enableFilter bool) (*ItemImportReport, error) {
+	if p.IsWinnerBased() {
+		return nil, errors.New(ImportNotSupported)
+	}
+
+	// load existing content hashes
+	existingSet := make(map[string]bool)
+	if enableFilter {
+		var err error
+		if existingSet, err = p.existingContentHashes(tx); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &ItemImportReport{Rejects: []ItemImportReject{}}
+	seenLines := make(map[string]int)
+	batch := make([]ProjectItem, 0, projectItemInsertBatchSize)
+	var itemIDs []interface{}
+
+	flush := func() error {
+		if len(batch) <= 0 {
+			return nil
+		}
+		if err := tx.Create(&batch).Error; err != nil {
+			return err
+		}
+		for _, item := range batch {
+			itemIDs = append(itemIDs, item.ID)
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	if err := scanImportLines(r, isCSV, func(line int, content string, overflow bool) error {
+		report.TotalLines++
+		switch {
+		case overflow:
+			report.reject(line, fmt.Sprintf(ImportLineTooLong, projectItemMaxLength))
+			return nil
+		case content == "":
+			report.reject(line, ImportLineEmpty)
+			return nil
+		case utf8.RuneCountInString(content) > projectItemMaxLength:
+			report.reject(line, fmt.Sprintf(ImportLineTooLong, projectItemMaxLength))
+			return nil
+		}
+		hash, err := ItemContentHash(content)
+		if err != nil {
+			return err
+		}
+		if existingSet[hash] {
+			report.reject(line, ImportLineExists)
+			return nil
+		}
+		if enableFilter {
+			if firstLine, ok := seenLines[content]; ok {
+				report.reject(line, fmt.Sprintf(ImportLineRepeated, firstLine))
+				return nil
+			}
+			seenLines[content] = line
+		}
+
+		item, err := newProjectItem(p.ID, content)
+		if err != nil {
+			return err
+		}
+		batch = append(batch, item)
+		if len(batch) >= projectItemInsertBatchSize {
+			return flush()
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	if len(itemIDs) <= 0 {
+		return report, nil
+	}
+
+	// update project counts
+	report.ImportedCount = int64(len(itemIDs))
+	if err := tx.Model(&Project{}).
+		Where("id = ?", p.ID).
+		Updates(map[string]interface{}{
+			"total_items":  gorm.Expr("total_items + ?", report.ImportedCount),
+			"is_completed": false,
+		}).Error; err != nil {
+		return nil, err
+	}
+	p.TotalItems += report.ImportedCount
+	p.IsCompleted = false
+
+	// push items to redis
+	pipe := db.Redis.Pipeline()
+	for start := 0; start < len(itemIDs); start += projectItemInsertBatchSize {
+		end := min(start+projectItemInsertBatchSize, len(itemIDs))
+		pipe.RPush(ctx, p.ItemsKey(), itemIDs[start:end]...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// StockItemIDs 返回仍在 Redis 库存中的 item ID 集合
+func (p *Project) StockItemIDs(ctx context.Context) (map[uint64]bool, error) {
+	var values []string
+	var err error
+	if p.IsWinnerBased() {
+		values, err = db.Redis.HVals(ctx, p.ItemsKey()).Result()
+	} else {
+		values, err = db.Redis.LRange(ctx, p.ItemsKey(), 0, -1).Result()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[uint64]bool, len(values))
+	for _, value := range values {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, nil
+}
+
+// ExportedItem 导出的 item 记录
+type ExportedItem struct {
+	ID               uint64     `json:"id"`
+	Content          string     `json:"content"`
+	ReceiverUsername string     `json:"receiver_username"`
+	ReceivedAt       *time.Time `json:"received_at"`
+	InStock          bool       `json:"in_stock"`
+}
+
+// ExportItems 按 ID 升序分批读取项目全部 item 并逐条回调，避免一次性加载到内存
+func (p *Project) ExportItems(ctx context.Context, fn func(item *ExportedItem) error) error {
+	stockIDs, err := p.StockItemIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	var lastID uint64
+	for {
+		var items []ExportedItem
+		if err := db.DB(ctx).
+			Model(&ProjectItem{}).
+			Select("project_items.id, project_items.content, project_items.received_at, COALESCE(users.username, '') AS receiver_username").
+			Joins("LEFT JOIN users ON users.id = project_items.receiver_id").
+			Where("project_items.project_id = ? AND project_items.id > ?", p.ID, lastID).
+			Order("project_items.id ASC").
+			Limit(itemExportBatchSize).
+			Scan(&items).Error; err != nil {
+			return err
+		}
+
+		for i := range items {
+			items[i].InStock = stockIDs[items[i].ID]
+			if items[i].Content, err = DecryptItemContent(items[i].Content); err != nil {
+				return err
+			}
+			if err := fn(&items[i]); err != nil {
+				return err
+			}
+		}
+		if len(items) < itemExportBatchSize {
+			return nil
+		}
+		lastID = items[len(items)-1].ID
+	}
+}
+
+// DeleteUnreceivedItems 按 ID 或内容删除仍在库存中的 item:在事务内锁定数据库记录并从 Redis 队列 LREM,
+// 仅删除确实移出队列的 item;已被待支付订单预占(不在队列中)的 item 会被跳过，预备池中的 item 直接删除。
+// 事务自行开启，回滚或提交失败时会把已移出的 item 重新推回队列。
+func (p *Project) DeleteUnreceivedItems(ctx context.Context, ids []uint64, contents []string) (deleted []uint64, skipped []uint64, err error) {
+	if p.IsWinnerBased() {
+		return nil, nil, errors.New(ItemDeleteNotSupported)
+	}
+	if len(ids) <= 0 && len(contents) <= 0 {
+		return nil, nil, errors.New(ItemSelectorRequired)
+	}
+
+	var removed []uint64
+	if err = db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		var txErr error
+		deleted, skipped, txErr = p.deleteUnreceivedItems(ctx, tx, ids, contents, &removed)
+		return txErr
+	}); err != nil {
+		// 事务未提交，归还已移出的库存
+		if len(removed) > 0 {
+			values := make([]interface{}, len(removed))
+			for i, id := range removed {
+				values[i] = id
+			}
+			if pushErr := db.Redis.RPush(ctx, p.ItemsKey(), values...).Err(); pushErr != nil {
+				logger.ErrorF(ctx, "项目[%s]删除 item 失败后归还库存失败: %v", p.ID, pushErr)
+			}
+		}
+		return nil, nil, err
+	}
+	p.TotalItems -= int64(len(removed))
+	return deleted, skipped, nil
+}
+
+// deleteUnreceivedItems 在 tx 内执行删除，移出 Redis 队列的 item 会立即记入 removed,供回滚时归还
+func (p *Project) deleteUnreceivedItems(ctx context.Context, tx *gorm.DB, ids []uint64, contents []string, removed *[]uint64) ([]uint64, []uint64, error) {
+	// lock items
+	// 按内容哈希定位加密内容，按原文兼容未写入哈希的存量数据
+	hashes := make([]string, len(contents))
+	for i, content := range contents {
+		hash, err := ItemContentHash(content)
+		if err != nil {
+			return nil, nil, err
+		}
+		hashes[i] = hash
+	}
+	cond := tx.Where("id IN ?", ids)
+	if len(ids) <= 0 {
+		cond = tx.Where("content_hash IN ? OR content IN ?", hashes, contents)
+	} else if len(contents) > 0 {
+		cond = cond.Or("content_hash IN ? OR content IN ?", hashes, contents)
+	}
+	var lockedItems []ProjectItem
+	if err := tx.Model(&ProjectItem{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "reserved").
+		Where("project_id = ? AND receiver_id IS NULL", p.ID).
+		Where(cond).
+		Find(&lockedItems).Error; err != nil {
+		return nil, nil, err
+	}
+
+	// 预备池中的 item 不在 Redis 队列，也不计入 total_items,直接删除
+	itemIDs := make([]uint64, 0, len(lockedItems))
+	reservedIDs := make([]uint64, 0)
+	for _, item := range lockedItems {
+		if item.Reserved {
+			reservedIDs = append(reservedIDs, item.ID)
+		} else {
+			itemIDs = append(itemIDs, item.ID)
+		}
+	}
+	if len(reservedIDs) > 0 {
+		if err := tx.Where("id IN ?", reservedIDs).Delete(&ProjectItem{}).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(itemIDs) <= 0 {
+		return reservedIDs, []uint64{}, nil
+	}
+
+	// remove from redis
+	pipe := db.Redis.Pipeline()
+	cmds := make([]*redis.IntCmd, len(itemIDs))
+	for i, id := range itemIDs {
+		cmds[i] = pipe.LRem(ctx, p.ItemsKey(), 1, id)
+	}
+	_, pipeErr := pipe.Exec(ctx)
+	skipped := make([]uint64, 0)
+	for i, cmd := range cmds {
+		if cmd.Val() > 0 {
+			*removed = append(*removed, itemIDs[i])
+		} else {
+			skipped = append(skipped, itemIDs[i])
+		}
+	}
+	if pipeErr != nil {
+		return nil, nil, pipeErr
+	}
+	if len(*removed) <= 0 {
+		return reservedIDs, skipped, nil
+	}
+
+	// delete items
+	if err := tx.Where("id IN ?", *removed).Delete(&ProjectItem{}).Error; err != nil {
+		return nil, nil, err
+	}
+	updates := map[string]interface{}{"total_items": gorm.Expr("total_items - ?", len(*removed))}
+	hasStock, err := p.HasStock(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !hasStock {
+		updates["is_completed"] = true
+	}
+	if err := tx.Model(&Project{}).Where("id = ?", p.ID).Updates(updates).Error; err != nil {
+		return nil, nil, err
+	}
+	p.IsCompleted = p.IsCompleted || !hasStock
+	return append(reservedIDs, *removed...), skipped, nil
+}
+
+// ReplaceReceivedItemContent 替换已领取 item 的内容(如原 CDK 失效需补发),并记录替换时间与原因供领取人感知
+func (p *Project) ReplaceReceivedItemContent(tx *gorm.DB, itemID uint64, content string, reason string) (*ProjectItem, error) {
+	item := &ProjectItem{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND project_id = ?", itemID, p.ID).
+		First(item).Error; err != nil {
+		return nil, err
+	}
+	if item.ReceiverID == nil {
+		return nil, errors.New(ItemNotReceived)
+	}
+
+	enc, err := EncryptItemContent(content)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := ItemContentHash(content)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := tx.Model(item).Updates(map[string]interface{}{
+		"content":        enc,
+		"content_hash":   hash,
+		"replaced_at":    &now,
+		"replace_reason": reason,
+	}).Error; err != nil {
+		return nil, err
+	}
+	if err := notification.Send(tx, notification.ItemReplaced(p.ID, p.Name, reason), *item.ReceiverID); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
@@ -0,0 +1,333 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/linux-do/cdk/internal/apps/notification"
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/apps/webhook"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/db/dbtest"
+	"gorm.io/gorm"
+)
+
+// setupItemStore 准备项目相关表与 Redis,供涉及存储的用例使用，返回的 miniredis 可用于检查下发的任务
+func setupItemStore(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	return dbtest.Setup(t,
+		&oauth.User{}, &Project{}, &ProjectItem{}, &ProjectClaim{}, &ProjectWaitlist{},
+		&notification.Notification{}, &webhook.Webhook{}, &webhook.WebhookDelivery{},
+	)
+}
+
+// createStockedProject 创建一个进行中的项目，写入 item 并推入 Redis 库存队列
+func createStockedProject(t *testing.T, p *Project, contents ...string) []ProjectItem {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now()
+	if p.ID == "" {
+		p.ID = "project-" + strings.ReplaceAll(t.Name(), "/", "-")
+	}
+	if p.StartTime.IsZero() {
+		p.StartTime = now.Add(-time.Hour)
+	}
+	if p.EndTime.IsZero() {
+		p.EndTime = now.Add(time.Hour)
+	}
+	p.TotalItems = int64(len(contents))
+	if err := db.DB(ctx).Create(p).Error; err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	items := make([]ProjectItem, len(contents))
+	for i, content := range contents {
+		item, err := newProjectItem(p.ID, content)
+		if err != nil {
+			t.Fatalf("new item: %v", err)
+		}
+		items[i] = item
+	}
+	if len(items) <= 0 {
+		return items
+	}
+	if err := db.DB(ctx).Create(&items).Error; err != nil {
+		t.Fatalf("create items: %v", err)
+	}
+	for _, item := range items {
+		if err := db.Redis.RPush(ctx, p.ItemsKey(), item.ID).Err(); err != nil {
+			t.Fatalf("push stock: %v", err)
+		}
+	}
+	return items
+}
+
+// stockIDs 返回 Redis 库存队列中的 item ID
+func stockIDs(t *testing.T, p *Project) map[uint64]bool {
+	t.Helper()
+	ids, err := p.StockItemIDs(context.Background())
+	if err != nil {
+		t.Fatalf("stock ids: %v", err)
+	}
+	return ids
+}
+
+func collectImportLines(t *testing.T, input string, isCSV bool) map[int]string {
+	t.Helper()
+	lines := make(map[int]string)
+	if err := scanImportLines(strings.NewReader(input), isCSV, func(line int, content string, overflow bool) error {
+		if overflow {
+			content = "<overflow>"
+		}
+		lines[line] = content
+		return nil
+	}); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	return lines
+}
+
+func TestScanImportLinesText(t *testing.T) {
+	lines := collectImportLines(t, "\ufeffKEY-1\r\n  KEY-2  \n\nKEY-3", false)
+	want := map[int]string{1: "KEY-1", 2: "KEY-2", 3: "", 4: "KEY-3"}
+	if len(lines) != len(want) {
+		t.Fatalf("want %d lines, got %d: %v", len(want), len(lines), lines)
+	}
+	for line, content := range want {
+		if lines[line] != content {
+			t.Fatalf("line %d: want %q, got %q", line, content, lines[line])
+		}
+	}
+}
+
+func TestScanImportLinesTextOverflow(t *testing.T) {
+	long := strings.Repeat("x", itemImportLineMaxBytes*2+10)
+	lines := collectImportLines(t, "KEY-1\n"+long+"\nKEY-3\n"+long, false)
+	want := map[int]string{1: "KEY-1", 2: "<overflow>", 3: "KEY-3", 4: "<overflow>"}
+	if len(lines) != len(want) {
+		t.Fatalf("want %d lines, got %d", len(want), len(lines))
+	}
+	for line, content := range want {
+		if lines[line] != content {
+			t.Fatalf("line %d: want %q, got %.20q", line, content, lines[line])
+		}
+	}
+}
+
+func TestScanImportLinesReadError(t *testing.T) {
+	for _, isCSV := range []bool{false, true} {
+		r := io.MultiReader(strings.NewReader("KEY-1\n"), iotest.ErrReader(errors.New("broken")))
+		err := scanImportLines(r, isCSV, func(int, string, bool) error { return nil })
+		if !errors.Is(err, errImportFileInvalid) {
+			t.Fatalf("csv=%v: want errImportFileInvalid, got %v", isCSV, err)
+		}
+	}
+}
+
+func TestScanImportLinesCSV(t *testing.T) {
+	lines := collectImportLines(t, "KEY-1,note\n\"KEY,2\",x\n\nKEY-3\n", true)
+	want := map[int]string{1: "KEY-1", 2: "KEY,2", 4: "KEY-3"}
+	if len(lines) != len(want) {
+		t.Fatalf("want %d records, got %d: %v", len(want), len(lines), lines)
+	}
+	for line, content := range want {
+		if lines[line] != content {
+			t.Fatalf("line %d: want %q, got %q", line, content, lines[line])
+		}
+	}
+}
+
+func TestExportItems(t *testing.T) {
+	setupItemStore(t)
+	ctx := context.Background()
+
+	receiver := oauth.User{ID: 7, Username: "alice"}
+	if err := db.DB(ctx).Create(&receiver).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	p := &Project{}
+	items := createStockedProject(t, p, "KEY-1", "KEY-2", "KEY-3")
+	// KEY-1 已领取;KEY-2 被待支付订单预占(已出队);KEY-3 仍在库存
+	receivedAt := time.Now()
+	if err := db.DB(ctx).Model(&items[0]).Updates(map[string]interface{}{"receiver_id": receiver.ID, "received_at": &receivedAt}).Error; err != nil {
+		t.Fatalf("mark received: %v", err)
+	}
+	db.Redis.LRem(ctx, p.ItemsKey(), 1, items[0].ID)
+	db.Redis.LRem(ctx, p.ItemsKey(), 1, items[1].ID)
+
+	var exported []ExportedItem
+	if err := p.ExportItems(ctx, func(item *ExportedItem) error {
+		exported = append(exported, *item)
+		return nil
+	}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if len(exported) != 3 {
+		t.Fatalf("want 3 items, got %d", len(exported))
+	}
+	want := []struct {
+		content  string
+		username string
+		received bool
+		inStock  bool
+	}{
+		{"KEY-1", "alice", true, false},
+		{"KEY-2", "", false, false},
+		{"KEY-3", "", false, true},
+	}
+	for i, w := range want {
+		got := exported[i]
+		if got.ID != items[i].ID || got.Content != w.content || got.ReceiverUsername != w.username ||
+			(got.ReceivedAt != nil) != w.received || got.InStock != w.inStock {
+			t.Fatalf("item %d: want %+v, got %+v", i, w, got)
+		}
+	}
+}
+
+func TestDeleteUnreceivedItems(t *testing.T) {
+	setupItemStore(t)
+	ctx := context.Background()
+
+	p := &Project{}
+	items := createStockedProject(t, p, "KEY-1", "KEY-2", "KEY-3", "KEY-4")
+	// KEY-3 被待支付订单预占，不在队列中，应被跳过
+	db.Redis.LRem(ctx, p.ItemsKey(), 1, items[2].ID)
+	// 预备池中的 item 直接删除，不影响 total_items
+	reserved, err := newProjectItem(p.ID, "RESERVED")
+	if err != nil {
+		t.Fatalf("new item: %v", err)
+	}
+	reserved.Reserved = true
+	if err := db.DB(ctx).Create(&reserved).Error; err != nil {
+		t.Fatalf("create reserved: %v", err)
+	}
+
+	deleted, skipped, err := p.DeleteUnreceivedItems(ctx, []uint64{items[0].ID, items[2].ID, reserved.ID}, []string{"KEY-2"})
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if len(deleted) != 3 || len(skipped) != 1 || skipped[0] != items[2].ID {
+		t.Fatalf("want 3 deleted and item %d skipped, got deleted=%v skipped=%v", items[2].ID, deleted, skipped)
+	}
+
+	var remaining []uint64
+	db.DB(ctx).Model(&ProjectItem{}).Where("project_id = ?", p.ID).Order("id").Pluck("id", &remaining)
+	if len(remaining) != 2 || remaining[0] != items[2].ID || remaining[1] != items[3].ID {
+		t.Fatalf("unexpected remaining items: %v", remaining)
+	}
+	stock := stockIDs(t, p)
+	if len(stock) != 1 || !stock[items[3].ID] {
+		t.Fatalf("unexpected stock: %v", stock)
+	}
+	var stored Project
+	db.DB(ctx).First(&stored, "id = ?", p.ID)
+	if stored.TotalItems != 2 || p.TotalItems != 2 {
+		t.Fatalf("want total_items 2, got stored=%d local=%d", stored.TotalItems, p.TotalItems)
+	}
+}
+
+func TestDeleteUnreceivedItemsRestocksOnRollback(t *testing.T) {
+	setupItemStore(t)
+	ctx := context.Background()
+
+	p := &Project{}
+	items := createStockedProject(t, p, "KEY-1", "KEY-2")
+
+	// 让 total_items 更新失败，模拟 LREM 之后事务回滚
+	if err := db.DB(ctx).Callback().Update().Before("gorm:update").Register("test:fail_update", func(tx *gorm.DB) {
+		if tx.Statement.Table == "projects" {
+			_ = tx.AddError(errors.New("update failed"))
+		}
+	}); err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+
+	if _, _, err := p.DeleteUnreceivedItems(ctx, []uint64{items[0].ID}, nil); err == nil {
+		t.Fatal("want error")
+	}
+	var count int64
+	db.DB(ctx).Model(&ProjectItem{}).Where("project_id = ?", p.ID).Count(&count)
+	if count != 2 {
+		t.Fatalf("items should survive rollback, got %d", count)
+	}
+	stock := stockIDs(t, p)
+	if len(stock) != 2 || !stock[items[0].ID] || !stock[items[1].ID] {
+		t.Fatalf("removed item should be pushed back, got %v", stock)
+	}
+	if p.TotalItems != 2 {
+		t.Fatalf("local total_items should be untouched, got %d", p.TotalItems)
+	}
+}
+
+func TestReplaceReceivedItemContent(t *testing.T) {
+	setupItemStore(t)
+	ctx := context.Background()
+
+	p := &Project{Name: "replace"}
+	items := createStockedProject(t, p, "KEY-1", "KEY-2")
+	receiverID := uint64(9)
+	if err := db.DB(ctx).Model(&items[0]).Update("receiver_id", receiverID).Error; err != nil {
+		t.Fatalf("mark received: %v", err)
+	}
+
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		_, err := p.ReplaceReceivedItemContent(tx, items[1].ID, "NEW", "")
+		return err
+	}); err == nil || err.Error() != ItemNotReceived {
+		t.Fatalf("want %q for unreceived item, got %v", ItemNotReceived, err)
+	}
+
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		_, err := p.ReplaceReceivedItemContent(tx, items[0].ID, "KEY-1-NEW", "原 key 已失效")
+		return err
+	}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+
+	var stored ProjectItem
+	db.DB(ctx).First(&stored, items[0].ID)
+	content, err := stored.PlainContent()
+	if err != nil || content != "KEY-1-NEW" {
+		t.Fatalf("want replaced content, got %q (%v)", content, err)
+	}
+	newHash, _ := ItemContentHash("KEY-1-NEW")
+	if stored.ReplacedAt == nil || stored.ReplaceReason != "原 key 已失效" || stored.ContentHash != newHash {
+		t.Fatalf("replace metadata not recorded: %+v", stored)
+	}
+
+	// 领取人收到站内通知
+	var notices []notification.Notification
+	db.DB(ctx).Where("user_id = ?", receiverID).Find(&notices)
+	if len(notices) != 1 || notices[0].Type != notification.TypeItemReplaced || notices[0].ProjectID != p.ID {
+		t.Fatalf("want one item_replaced notification for receiver, got %+v", notices)
+	}
+}
@@ -115,6 +115,21 @@ func ReceiveProjectMiddleware() gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusForbidden, ProjectResponse{ErrorMsg: err.Error()})
 			return
 		}
+		// check invite
+		if project.IsInviteOnly() {
+			req, err := GetReceiveRequestFromContext(c)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+				return
+			}
+			invite, err := project.LoadInvite(ctx, req.InviteToken)
+			if err != nil {
+				recordErrProjectReceive(c, now, user.ID, user.Username, project.ID, project.StartTime, project.EndTime, err.Error())
+				c.AbortWithStatusJSON(http.StatusForbidden, ProjectResponse{ErrorMsg: err.Error()})
+				return
+			}
+			SetInviteToContext(c, invite)
+		}
 		// 将 project 注入 context 供 handler 复用,避免重复加载
 		SetProjectToContext(c, project)
 		// do next
@@ -39,7 +39,9 @@ import (
 
 	"github.com/linux-do/cdk/internal/utils"
 
+	"github.com/linux-do/cdk/internal/apps/forum"
 	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/apps/webhook"
 	"github.com/linux-do/cdk/internal/db"
 	"github.com/redis/go-redis/v9"
 	"github.com/shopspring/decimal"
@@ -47,25 +49,40 @@ import (
 )
 
 type Project struct {
-	ID                string           `json:"id" gorm:"primaryKey;size:64"`
-	Name              string           `json:"name" gorm:"size:32"`
-	Description       string           `json:"description" gorm:"size:1024"`
-	DistributionType  DistributionType `json:"distribution_type"`
-	TotalItems        int64            `json:"total_items"`
-	StartTime         time.Time        `json:"start_time"`
-	EndTime           time.Time        `json:"end_time" gorm:"index:idx_projects_end_completed_trust_risk,priority:1"`
-	MinimumTrustLevel oauth.TrustLevel `json:"minimum_trust_level" gorm:"index:idx_projects_end_completed_trust_risk,priority:4"`
-	AllowSameIP       bool             `json:"allow_same_ip"`
-	RiskLevel         int8             `json:"risk_level" gorm:"index:idx_projects_end_completed_trust_risk,priority:5"`
-	CreatorID         uint64           `json:"creator_id" gorm:"index"`
-	IsCompleted       bool             `json:"is_completed" gorm:"index:idx_projects_end_completed_trust_risk,priority:2"`
-	Status            ProjectStatus    `json:"status" gorm:"default:0;index;index:idx_projects_end_completed_trust_risk,priority:3"`
-	ReportCount       uint8            `json:"report_count" gorm:"default:0"`
-	HideFromExplore   bool             `json:"hide_from_explore" gorm:"default:false"`
-	Price             decimal.Decimal  `json:"price" gorm:"type:decimal(10,2);default:0;not null"`
-	Creator           oauth.User       `json:"-" gorm:"foreignKey:CreatorID"`
-	CreatedAt         time.Time        `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt         time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+	ID                  string           `json:"id" gorm:"primaryKey;size:64"`
+	Name                string           `json:"name" gorm:"size:32"`
+	Description         string           `json:"description" gorm:"size:1024"`
+	DistributionType    DistributionType `json:"distribution_type"`
+	TopicID             uint64           `json:"topic_id" gorm:"default:0;not null"`
+	TotalItems          int64            `json:"total_items"`
+	StartTime           time.Time        `json:"start_time"`
+	EndTime             time.Time        `json:"end_time" gorm:"index:idx_projects_end_completed_trust_risk,priority:1"`
+	MinimumTrustLevel   oauth.TrustLevel `json:"minimum_trust_level" gorm:"index:idx_projects_end_completed_trust_risk,priority:4"`
+	AllowSameIP         bool             `json:"allow_same_ip"`
+	RiskLevel           int8             `json:"risk_level" gorm:"index:idx_projects_end_completed_trust_risk,priority:5"`
+	CreatorID           uint64           `json:"creator_id" gorm:"index"`
+	IsCompleted         bool             `json:"is_completed" gorm:"index:idx_projects_end_completed_trust_risk,priority:2"`
+	Status              ProjectStatus    `json:"status" gorm:"default:0;index;index:idx_projects_end_completed_trust_risk,priority:3"`
+	ReportCount         uint8            `json:"report_count" gorm:"default:0"`
+	HideFromExplore     bool             `json:"hide_from_explore" gorm:"default:false"`
+	AnnounceToTopic     bool             `json:"announce_to_topic" gorm:"default:false"`
+	AnnounceProgress    bool             `json:"announce_progress" gorm:"default:false"`
+	Price               decimal.Decimal  `json:"price" gorm:"type:decimal(10,2);default:0;not null"`
+	Currency            Currency         `json:"currency" gorm:"size:16;default:'LDC';not null"`
+	MaxPerUser          int              `json:"max_per_user" gorm:"default:1;not null"`
+	MaxPerUserOverrides TrustLevelQuota  `json:"max_per_user_overrides" gorm:"type:json"`
+	PricingRules        PricingRules     `json:"pricing_rules" gorm:"type:json"`
+	DrawSeedHash        string           `json:"draw_seed_hash" gorm:"size:64"`
+	DrawSeed            string           `json:"-" gorm:"size:64"`
+	DrawnAt             *time.Time       `json:"drawn_at"`
+	RecurrenceCron      string           `json:"recurrence_cron" gorm:"size:64"`
+	RoundSize           int              `json:"round_size"`
+	RoundDuration       int              `json:"round_duration"`
+	Round               int              `json:"round" gorm:"default:0;not null"`
+	NextRoundAt         *time.Time       `json:"next_round_at" gorm:"index"`
+	Creator             oauth.User       `json:"-" gorm:"foreignKey:CreatorID"`
+	CreatedAt           time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 // IsPaid 是否为付费项目
@@ -147,6 +164,31 @@ var (
 	lotteryBotWinnerRegex        = regexp.MustCompile(`@(\S+)`)
 )
 
+// fetchLotteryTopic 获取话题基本信息，帖子流仅包含抽奖机器人的回复
+func fetchLotteryTopic(ctx context.Context, topicId uint64) (*TopicResponse, error) {
+	headers := map[string]string{
+		"Api-Key":      config.Config.LinuxDo.ApiKey,
+		"Api-Username": config.Config.LinuxDo.ApiUsername,
+	}
+
+	url := fmt.Sprintf("%s/t/%d.json?username_filters=lottery_bot&include_raw=true", forum.NewClient().BaseURL, topicId)
+	topicResp, errRequest := utils.Request(ctx, http.MethodGet, url, nil, headers, nil)
+	if errRequest != nil {
+		return nil, errRequest
+	}
+	defer topicResp.Body.Close()
+
+	if topicResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取话题信息失败，状态码: %d", topicResp.StatusCode)
+	}
+
+	var response TopicResponse
+	if errDecode := json.NewDecoder(topicResp.Body).Decode(&response); errDecode != nil {
+		return nil, fmt.Errorf("解析话题信息失败: %w", errDecode)
+	}
+	return &response, nil
+}
+
 func (p *Project) CreateItems(ctx context.Context, tx *gorm.DB, items []string, topicId uint64) error {
 	// skip create
 	if len(items) <= 0 {
@@ -154,26 +196,10 @@ func (p *Project) CreateItems(ctx context.Context, tx *gorm.DB, items []string,
 	}
 
 	if p.DistributionType == DistributionTypeLottery {
-		headers := map[string]string{
-			"Api-Key":      config.Config.LinuxDo.ApiKey,
-			"Api-Username": config.Config.LinuxDo.ApiUsername,
-		}
-
 		// 获取话题基本信息
-		url := fmt.Sprintf("https://linux.do/t/%d.json?username_filters=lottery_bot&include_raw=true", topicId)
-		topicResp, errRequest := utils.Request(ctx, http.MethodGet, url, nil, headers, nil)
-		if errRequest != nil {
-			return errRequest
-		}
-		defer topicResp.Body.Close()
-
-		if topicResp.StatusCode != http.StatusOK {
-			return fmt.Errorf("获取话题信息失败，状态码: %d", topicResp.StatusCode)
-		}
-
-		var response TopicResponse
-		if errDecode := json.NewDecoder(topicResp.Body).Decode(&response); errDecode != nil {
-			return fmt.Errorf("解析话题信息失败: %w", errDecode)
+		response, err := fetchLotteryTopic(ctx, topicId)
+		if err != nil {
+			return err
 		}
 
 		hasLotteryTag := slices.ContainsFunc(response.Tags, func(tag TopicTag) bool {
@@ -246,9 +272,9 @@ func (p *Project) CreateItems(ctx context.Context, tx *gorm.DB, items []string,
 				}
 				mergedContent += fmt.Sprintf("中奖码%d: %s", i+1, items[idx])
 			}
-			item := ProjectItem{
-				ProjectID: p.ID,
-				Content:   mergedContent,
+			item, err := newProjectItem(p.ID, mergedContent)
+			if err != nil {
+				return err
 			}
 			winnerItems = append(winnerItems, winnerItem{
 				username: winner,
@@ -273,12 +299,29 @@ func (p *Project) CreateItems(ctx context.Context, tx *gorm.DB, items []string,
 		if err := db.Redis.HSet(ctx, p.ItemsKey(), itemUserMap).Err(); err != nil {
 			return err
 		}
+	} else if p.DistributionType == DistributionTypeDraw {
+		// 平台抽奖:item 在开奖时才分配给中奖者写入 Redis
+		projectItems := make([]ProjectItem, len(items))
+		for i, content := range items {
+			item, err := newProjectItem(p.ID, content)
+			if err != nil {
+				return err
+			}
+			projectItems[i] = item
+		}
+		if err := tx.CreateInBatches(&projectItems, projectItemInsertBatchSize).Error; err != nil {
+			return err
+		}
 	} else {
 		// create items
 		projectItems := make([]ProjectItem, len(items))
 
 		for i, content := range items {
-			projectItems[i] = ProjectItem{ProjectID: p.ID, Content: content}
+			item, err := newProjectItem(p.ID, content)
+			if err != nil {
+				return err
+			}
+			projectItems[i] = item
 		}
 
 		if err := tx.CreateInBatches(&projectItems, projectItemInsertBatchSize).Error; err != nil {
@@ -305,24 +348,20 @@ func (p *Project) CreateItemsWithFilter(ctx context.Context, tx *gorm.DB, items
 
 	filteredItems := items
 	if enableFilter {
-		// Get existing items for this project
-		var existingItems []string
-		if err := tx.Model(&ProjectItem{}).
-			Where("project_id = ?", p.ID).
-			Pluck("content", &existingItems).Error; err != nil {
+		// Get existing content hashes for this project
+		existingSet, err := p.existingContentHashes(tx)
+		if err != nil {
 			return err
 		}
 
-		// Create a set of existing items for O(1) lookup
-		existingSet := make(map[string]bool)
-		for _, item := range existingItems {
-			existingSet[item] = true
-		}
-
 		// Filter out duplicates
 		filteredItems = make([]string, 0, len(items))
 		for _, item := range items {
-			if !existingSet[item] {
+			hash, err := ItemContentHash(item)
+			if err != nil {
+				return err
+			}
+			if !existingSet[hash] {
 				filteredItems = append(filteredItems, item)
 			}
 		}
@@ -342,24 +381,20 @@ func (p *Project) GetFilteredItemsCount(ctx context.Context, tx *gorm.DB, items
 		return int64(len(items)), nil
 	}
 
-	// Get existing items for this project
-	var existingItems []string
-	if err := tx.Model(&ProjectItem{}).
-		Where("project_id = ?", p.ID).
-		Pluck("content", &existingItems).Error; err != nil {
+	// Get existing content hashes for this project
+	existingSet, err := p.existingContentHashes(tx)
+	if err != nil {
 		return 0, err
 	}
 
-	// Create a set of existing items for O(1) lookup
-	existingSet := make(map[string]bool)
-	for _, item := range existingItems {
-		existingSet[item] = true
-	}
-
 	// Count unique items
 	uniqueCount := int64(0)
 	for _, item := range items {
-		if !existingSet[item] {
+		hash, err := ItemContentHash(item)
+		if err != nil {
+			return 0, err
+		}
+		if !existingSet[hash] {
 			uniqueCount++
 		}
 	}
@@ -371,7 +406,7 @@ func (p *Project) PrepareReceive(ctx context.Context, userName string) (uint64,
 	var val string
 	var err error
 
-	if p.DistributionType == DistributionTypeLottery {
+	if p.IsWinnerBased() {
 		val, err = db.Redis.HGet(ctx, p.ItemsKey(), userName).Result()
 	} else {
 		val, err = db.Redis.LPop(ctx, p.ItemsKey()).Result()
@@ -389,19 +424,24 @@ func (p *Project) SameIPCacheKey(ip string) string {
 	return fmt.Sprintf("project:%s:receive:ip:%s", p.ID, ip)
 }
 
-func (p *Project) CheckSameIPReceived(ctx context.Context, ip string) (bool, error) {
+// CheckSameIPReceived 检查该 IP 是否已被其他用户用于领取;同一用户在配额内多次领取不受限制。
+// 锁的值为首个领取人的用户 ID,无法解析的旧值一律视为其他用户。
+func (p *Project) CheckSameIPReceived(ctx context.Context, ip string, receiverID uint64) (bool, error) {
 	if p.AllowSameIP {
 		return false, nil
 	}
-	if count, err := db.Redis.Exists(ctx, p.SameIPCacheKey(ip)).Result(); err != nil {
+	val, err := db.Redis.Get(ctx, p.SameIPCacheKey(ip)).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	} else if err != nil {
 		return false, err
-	} else {
-		return count > 0, nil
 	}
+	holder, err := strconv.ParseUint(val, 10, 64)
+	return err != nil || holder != receiverID, nil
 }
 
 func (p *Project) Stock(ctx context.Context) (int64, error) {
-	if p.DistributionType == DistributionTypeLottery {
+	if p.IsWinnerBased() {
 		return db.Redis.HLen(ctx, p.ItemsKey()).Result()
 	}
 	return db.Redis.LLen(ctx, p.ItemsKey()).Result()
@@ -436,8 +476,12 @@ func (p *Project) IsReceivable(ctx context.Context, now time.Time, user *oauth.U
 	if err := p.ValidateRequirement(user); err != nil {
 		return err
 	}
+	// check claim quota
+	if err := p.CheckClaimQuota(ctx, user); err != nil {
+		return err
+	}
 	// check same ip
-	if sameIPReceived, err := p.CheckSameIPReceived(ctx, ip); err != nil {
+	if sameIPReceived, err := p.CheckSameIPReceived(ctx, ip, user.ID); err != nil {
 		return err
 	} else if sameIPReceived {
 		return errors.New(SameIPReceived)
@@ -460,14 +504,23 @@ type ProjectTag struct {
 
 type ProjectItem struct {
 	ID         uint64      `json:"id" gorm:"primaryKey,autoIncrement"`
-	ProjectID  string      `json:"project_id" gorm:"size:64;index;uniqueIndex:idx_project_receiver"`
+	ProjectID  string      `json:"project_id" gorm:"size:64;index"`
 	Project    Project     `json:"-" gorm:"foreignKey:ProjectID"`
-	ReceiverID *uint64     `json:"receiver_id" gorm:"index;uniqueIndex:idx_project_receiver"`
+	ReceiverID *uint64     `json:"receiver_id" gorm:"index"`
 	Receiver   *oauth.User `json:"-" gorm:"foreignKey:ReceiverID"`
-	Content    string      `json:"content" gorm:"size:1024"`
-	CreatedAt  time.Time   `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt  time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
-	ReceivedAt *time.Time  `json:"received_at" gorm:"index"`
+	// Content 启用内容加密时为 enc:<密钥版本>:<密文>,展示前需经 PlainContent 解密
+	Content     string     `json:"content" gorm:"size:6144"`
+	ContentHash string     `json:"-" gorm:"size:64;index"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	ReceivedAt  *time.Time `json:"received_at" gorm:"index"`
+	// 已领取的内容被创建者替换时记录，供领取人感知
+	ReplacedAt    *time.Time `json:"replaced_at"`
+	ReplaceReason string     `json:"replace_reason" gorm:"size:255"`
+	// Reserved 周期项目预备池中尚未投放的 item,不在 Redis 库存中
+	Reserved bool `json:"reserved" gorm:"default:false;index"`
+	// RevokedAt 退款时被收回作废的时间，作废的 item 不再回到库存，也不计入 total_items
+	RevokedAt *time.Time `json:"revoked_at"`
 }
 
 func (p *ProjectItem) Exact(tx *gorm.DB, id uint64) error {
@@ -477,38 +530,63 @@ func (p *ProjectItem) Exact(tx *gorm.DB, id uint64) error {
 	return nil
 }
 
-// FulfillForReceiver 执行领取结算事务:将 item 标记为已领取、库存耗尽则标记项目完成、
-// 若不允许同 IP 领取则写 Redis SetNX 锁、抽奖模式从 Redis HDel 用户。
+// FulfillForReceiver 执行领取结算事务:将 item 标记为已领取并登记领取记录(超出每人上限则失败)、库存耗尽则标记项目完成、
+// 若不允许同 IP 领取则写 Redis SetNX 锁(值为领取人 ID)、抽奖模式(含平台抽奖)从 Redis HDel 用户。
 // 由免费领取与付费回调两条路径共用;失败时上游需决定是否回退 itemID。
 func (p *Project) FulfillForReceiver(ctx context.Context, tx *gorm.DB, item *ProjectItem, receiverID uint64, clientIP string) error {
+	var user oauth.User
+	if err := user.Exact(tx, receiverID); err != nil {
+		return err
+	}
+
 	now := time.Now()
 	item.ReceiverID = &receiverID
 	item.ReceivedAt = &now
 	if err := tx.Save(item).Error; err != nil {
 		return err
 	}
+	if err := p.createClaim(tx, &user, item.ID); err != nil {
+		return err
+	}
+	if err := p.fulfillWaitlist(tx, receiverID, item.ID); err != nil {
+		return err
+	}
+
+	webhook.Emit(ctx, tx, p.CreatorID, webhook.EventItemReceived, webhook.ItemReceivedData{
+		ProjectID:        p.ID,
+		ProjectName:      p.Name,
+		ItemID:           item.ID,
+		ReceiverID:       receiverID,
+		ReceiverUsername: user.Username,
+		ReceivedAt:       now,
+	})
 
 	if hasStock, err := p.HasStock(ctx); err != nil {
 		return err
 	} else if !hasStock {
+		completed := p.IsCompleted
 		p.IsCompleted = true
 		if err := tx.Save(p).Error; err != nil {
 			return err
 		}
+		if !completed {
+			webhook.Emit(ctx, tx, p.CreatorID, webhook.EventProjectCompleted, webhook.ProjectCompletedData{
+				ProjectID:   p.ID,
+				ProjectName: p.Name,
+				TotalItems:  p.TotalItems,
+				CompletedAt: now,
+			})
+		}
 	}
 
 	if !p.AllowSameIP && clientIP != "" {
-		if err := db.Redis.SetNX(ctx, p.SameIPCacheKey(clientIP), clientIP, p.EndTime.Sub(now)).Err(); err != nil {
+		if err := db.Redis.SetNX(ctx, p.SameIPCacheKey(clientIP), receiverID, p.EndTime.Sub(now)).Err(); err != nil {
 			return err
 		}
 	}
 
-	if p.DistributionType == DistributionTypeLottery {
-		// 付费领取限定 OneForEach,此处保留仅为免费 Lottery 路径的兼容
-		var user oauth.User
-		if err := user.Exact(tx, receiverID); err != nil {
-			return err
-		}
+	if p.IsWinnerBased() {
+		// 付费领取限定 OneForEach,此处保留仅为免费 Lottery/Draw 路径的兼容
 		if err := db.Redis.HDel(ctx, p.ItemsKey(), user.Username).Err(); err != nil {
 			return err
 		}
@@ -517,9 +595,13 @@ func (p *Project) FulfillForReceiver(ctx context.Context, tx *gorm.DB, item *Pro
 	return nil
 }
 
+// GetReceivedItem 返回用户在项目下最近领取的 item,未领取返回 nil
 func (p *Project) GetReceivedItem(ctx context.Context, userID uint64) (*ProjectItem, error) {
 	item := &ProjectItem{}
-	err := db.DB(ctx).Where("project_id = ? AND receiver_id = ?", p.ID, userID).First(item).Error
+	err := db.DB(ctx).
+		Where("project_id = ? AND receiver_id = ?", p.ID, userID).
+		Order("received_at DESC, id DESC").
+		First(item).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -550,11 +632,13 @@ func (p *Project) ResetCompletedStatusIfHasStock(ctx context.Context, tx *gorm.D
 		Update("is_completed", false).Error
 }
 
+// ProjectReport 项目举报记录,ReviewedAt 为管理员审核该举报并通知举报人的时间，未审核时为空
 type ProjectReport struct {
-	ID         uint64    `json:"id" gorm:"primaryKey,autoIncrement"`
-	ProjectID  string    `json:"project_id" gorm:"size:64;index;uniqueIndex:idx_project_reporter"`
-	ReporterID uint64    `json:"reporter_id" gorm:"index;uniqueIndex:idx_project_reporter"`
-	Reason     string    `json:"reason" gorm:"size:255"`
-	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID         uint64     `json:"id" gorm:"primaryKey,autoIncrement"`
+	ProjectID  string     `json:"project_id" gorm:"size:64;index;uniqueIndex:idx_project_reporter"`
+	ReporterID uint64     `json:"reporter_id" gorm:"index;uniqueIndex:idx_project_reporter"`
+	Reason     string     `json:"reason" gorm:"size:255"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 }
@@ -0,0 +1,157 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/shopspring/decimal"
+)
+
+// PriceStep 库存阶梯:剩余库存(含本次购买)不超过 Remaining 时单价为 Price
+type PriceStep struct {
+	Remaining int64           `json:"remaining"`
+	Price     decimal.Decimal `json:"price"`
+}
+
+// PricingRules 付费项目的动态定价规则，各项均可选。
+// 早鸟期内使用早鸟价，否则使用匹配的库存阶梯价(无匹配时为 Price),最后按信任等级折扣百分比叠加。
+type PricingRules struct {
+	EarlyBirdPrice      *decimal.Decimal         `json:"early_bird_price"`
+	EarlyBirdUntil      *time.Time               `json:"early_bird_until"`
+	TrustLevelDiscounts map[oauth.TrustLevel]int `json:"trust_level_discounts"`
+	StockSteps          []PriceStep              `json:"stock_steps"`
+}
+
+func (r *PricingRules) Scan(value interface{}) error {
+	if value == nil {
+		*r = PricingRules{}
+		return nil
+	}
+	bytesValue, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("invalid value: %v", value)
+	}
+	return json.Unmarshal(bytesValue, r)
+}
+
+func (r PricingRules) Value() (driver.Value, error) {
+	if r.IsEmpty() {
+		return nil, nil
+	}
+	return json.Marshal(r)
+}
+
+// IsEmpty 是否未设置任何定价规则
+func (r PricingRules) IsEmpty() bool {
+	return r.EarlyBirdPrice == nil && r.EarlyBirdUntil == nil && len(r.TrustLevelDiscounts) <= 0 && len(r.StockSteps) <= 0
+}
+
+// Validate 校验定价规则，规则中的金额与 Price 遵循相同的币种精度与上限要求
+func (r PricingRules) Validate(price decimal.Decimal, currency Currency) error {
+	if r.IsEmpty() {
+		return nil
+	}
+	if !price.IsPositive() {
+		return errors.New(PricingRequiresPrice)
+	}
+	if (r.EarlyBirdPrice == nil) != (r.EarlyBirdUntil == nil) {
+		return errors.New(PricingEarlyBirdInvalid)
+	}
+	if r.EarlyBirdPrice != nil {
+		if err := checkRulePrice(*r.EarlyBirdPrice, currency); err != nil {
+			return err
+		}
+	}
+	for level, discount := range r.TrustLevelDiscounts {
+		if level < oauth.TrustLevelNewUser || level > oauth.TrustLevelLeader {
+			return fmt.Errorf(MaxPerUserLevelInvalid, level)
+		}
+		if discount < 1 || discount > 99 {
+			return errors.New(PricingDiscountInvalid)
+		}
+	}
+	if len(r.StockSteps) > maxPriceSteps {
+		return fmt.Errorf(PricingTooManySteps, maxPriceSteps)
+	}
+	seen := make(map[int64]struct{}, len(r.StockSteps))
+	for _, step := range r.StockSteps {
+		if _, ok := seen[step.Remaining]; ok || step.Remaining <= 0 {
+			return errors.New(PricingStepInvalid)
+		}
+		seen[step.Remaining] = struct{}{}
+		if err := checkRulePrice(step.Price, currency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRulePrice 规则中的金额必须为正，且满足 Price 的精度与上限要求
+func checkRulePrice(price decimal.Decimal, currency Currency) error {
+	if !price.IsPositive() {
+		return errors.New(PricingPriceNotPositive)
+	}
+	return checkPriceAmount(price, currency)
+}
+
+// resolve 按规则计算单价，折扣结果按币种精度取整且不低于最小计价单位，避免付费项目产生 0 元订单
+func (r PricingRules) resolve(base decimal.Decimal, currency Currency, level oauth.TrustLevel, now time.Time, remaining int64) decimal.Decimal {
+	price := base
+	if r.EarlyBirdPrice != nil && r.EarlyBirdUntil != nil && now.Before(*r.EarlyBirdUntil) {
+		price = *r.EarlyBirdPrice
+	} else {
+		// 取 Remaining 最小的匹配档位，即库存越少越靠后的档位
+		matched := int64(-1)
+		for _, step := range r.StockSteps {
+			if remaining <= step.Remaining && (matched < 0 || step.Remaining < matched) {
+				matched, price = step.Remaining, step.Price
+			}
+		}
+	}
+	if discount, ok := r.TrustLevelDiscounts[level]; ok && discount > 0 {
+		price = price.Mul(decimal.NewFromInt(int64(100 - discount))).Div(decimal.NewFromInt(100))
+		price = currency.Round(price)
+		if minUnit := currency.MinUnit(); price.LessThan(minUnit) {
+			price = minUnit
+		}
+	}
+	return price
+}
+
+// ResolvePrice 计算用户当前应付的单价,remaining 为含本次购买在内的剩余库存。
+// 结果由调用方写入订单金额，之后规则或库存变化均不影响已创建的订单。
+func (p *Project) ResolvePrice(user *oauth.User, now time.Time, remaining int64) (decimal.Decimal, error) {
+	price := p.PricingRules.resolve(p.Price, p.Currency, user.TrustLevel, now, remaining)
+	if err := checkPriceAmount(price, p.Currency); err != nil {
+		return decimal.Zero, err
+	}
+	return price, nil
+}
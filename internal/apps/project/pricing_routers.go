@@ -0,0 +1,84 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/shopspring/decimal"
+)
+
+type GetProjectPriceResponseData struct {
+	BasePrice decimal.Decimal `json:"base_price"`
+	Price     decimal.Decimal `json:"price"`
+	Currency  Currency        `json:"currency"`
+}
+
+type GetProjectPriceResponse struct {
+	ErrorMsg string                      `json:"error_msg"`
+	Data     GetProjectPriceResponseData `json:"data"`
+}
+
+// GetProjectPrice 按定价规则计算当前用户此刻的单价，仅供展示，实际金额以下单时固化到订单的为准
+// @Tags project
+// @Produce json
+// @Param id path string true "项目ID"
+// @Success 200 {object} GetProjectPriceResponse
+// @Router /api/v1/projects/{id}/price [get]
+func GetProjectPrice(c *gin.Context) {
+	// load user
+	user, _ := oauth.GetUserFromContext(c)
+
+	// load project
+	project := &Project{}
+	if err := project.Exact(db.DB(c.Request.Context()), c.Param("id"), true); err != nil {
+		c.JSON(http.StatusNotFound, GetProjectPriceResponse{ErrorMsg: err.Error()})
+		return
+	}
+	if !project.IsPaid() {
+		c.JSON(http.StatusOK, GetProjectPriceResponse{})
+		return
+	}
+
+	// resolve price
+	remaining, err := project.Stock(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, GetProjectPriceResponse{ErrorMsg: err.Error()})
+		return
+	}
+	price, err := project.ResolvePrice(user, time.Now(), remaining)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, GetProjectPriceResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetProjectPriceResponse{
+		Data: GetProjectPriceResponseData{BasePrice: project.Price, Price: price, Currency: project.Currency.OrDefault()},
+	})
+}
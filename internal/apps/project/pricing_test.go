@@ -0,0 +1,116 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"testing"
+	"time"
+
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/shopspring/decimal"
+)
+
+func TestPricingRulesResolve(t *testing.T) {
+	now := time.Now()
+	future := now.Add(time.Hour)
+	early := decimal.RequireFromString("5")
+	rules := PricingRules{
+		EarlyBirdPrice:      &early,
+		EarlyBirdUntil:      &future,
+		TrustLevelDiscounts: map[oauth.TrustLevel]int{oauth.TrustLevelLeader: 10},
+		StockSteps: []PriceStep{
+			{Remaining: 50, Price: decimal.RequireFromString("12")},
+			{Remaining: 10, Price: decimal.RequireFromString("15")},
+		},
+	}
+	base := decimal.RequireFromString("10")
+
+	cases := []struct {
+		name      string
+		now       time.Time
+		level     oauth.TrustLevel
+		remaining int64
+		want      string
+	}{
+		{"early bird", now, oauth.TrustLevelUser, 5, "5"},
+		{"base", future, oauth.TrustLevelUser, 100, "10"},
+		{"first step", future, oauth.TrustLevelUser, 50, "12"},
+		{"last step", future, oauth.TrustLevelUser, 3, "15"},
+		{"discount", future, oauth.TrustLevelLeader, 3, "13.5"},
+	}
+	for _, c := range cases {
+		got := rules.resolve(base, CurrencyLDC, c.level, c.now, c.remaining)
+		if !got.Equal(decimal.RequireFromString(c.want)) {
+			t.Fatalf("%s: want %s, got %s", c.name, c.want, got)
+		}
+	}
+
+	// 折后金额不低于币种的最小计价单位
+	cheap := PricingRules{TrustLevelDiscounts: map[oauth.TrustLevel]int{oauth.TrustLevelUser: 99}}
+	if got := cheap.resolve(decimal.RequireFromString("0.01"), CurrencyLDC, oauth.TrustLevelUser, now, 1); !got.Equal(decimal.RequireFromString("0.01")) {
+		t.Fatalf("want floor price, got %s", got)
+	}
+	if got := cheap.resolve(decimal.RequireFromString("1"), CurrencyCredit, oauth.TrustLevelUser, now, 1); !got.Equal(decimal.NewFromInt(1)) {
+		t.Fatalf("want integer floor price, got %s", got)
+	}
+
+	// 整数币种的折扣结果按整数取整
+	if got := rules.resolve(base, CurrencyCredit, oauth.TrustLevelLeader, future, 3); !got.Equal(decimal.NewFromInt(14)) {
+		t.Fatalf("want integer discount price, got %s", got)
+	}
+}
+
+func TestPricingRulesValidate(t *testing.T) {
+	price := decimal.RequireFromString("10")
+	if err := (PricingRules{}).Validate(decimal.Zero, CurrencyLDC); err != nil {
+		t.Fatalf("want empty rules valid, got %v", err)
+	}
+	if err := (PricingRules{TrustLevelDiscounts: map[oauth.TrustLevel]int{oauth.TrustLevelUser: 10}}).Validate(decimal.Zero, CurrencyLDC); err == nil {
+		t.Fatalf("want rules on free project rejected")
+	}
+	early := decimal.RequireFromString("1.234")
+	until := time.Now()
+	if err := (PricingRules{EarlyBirdPrice: &early, EarlyBirdUntil: &until}).Validate(price, CurrencyLDC); err == nil {
+		t.Fatalf("want early bird price precision rejected")
+	}
+	credit := decimal.RequireFromString("1.5")
+	if err := (PricingRules{EarlyBirdPrice: &credit, EarlyBirdUntil: &until}).Validate(price, CurrencyCredit); err == nil {
+		t.Fatalf("want fractional credit price rejected")
+	}
+	if err := (PricingRules{EarlyBirdPrice: &price}).Validate(price, CurrencyLDC); err == nil {
+		t.Fatalf("want early bird without deadline rejected")
+	}
+	if err := (PricingRules{TrustLevelDiscounts: map[oauth.TrustLevel]int{oauth.TrustLevelUser: 100}}).Validate(price, CurrencyLDC); err == nil {
+		t.Fatalf("want full discount rejected")
+	}
+	steps := []PriceStep{{Remaining: 5, Price: price}, {Remaining: 5, Price: price}}
+	if err := (PricingRules{StockSteps: steps}).Validate(price, CurrencyLDC); err == nil {
+		t.Fatalf("want duplicated step rejected")
+	}
+	large := []PriceStep{{Remaining: 5, Price: decimal.RequireFromString("100000000")}}
+	if err := (PricingRules{StockSteps: large}).Validate(price, CurrencyLDC); err == nil {
+		t.Fatalf("want step price above ceiling rejected")
+	}
+}
@@ -0,0 +1,217 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/cdk/internal/db"
+	"gorm.io/gorm"
+)
+
+type RecurrenceRequestBody struct {
+	Cron          string `json:"cron" binding:"required,max=64"`
+	RoundSize     int    `json:"round_size" binding:"required,min=1,max=10000"`
+	RoundDuration int    `json:"round_duration" binding:"required,min=1,max=10080"`
+}
+
+type RecurrenceResponseData struct {
+	Project      Project `json:"project"`
+	ReserveCount int64   `json:"reserve_count"`
+}
+
+type RecurrenceResponse struct {
+	ErrorMsg string                 `json:"error_msg"`
+	Data     RecurrenceResponseData `json:"data"`
+}
+
+// GetProjectRecurrence 获取项目周期规则及预备池剩余数量
+// @Tags project
+// @Produce json
+// @Param id path string true "项目ID"
+// @Success 200 {object} RecurrenceResponse
+// @Router /api/v1/projects/{id}/recurrence [get]
+func GetProjectRecurrence(c *gin.Context) {
+	// load project
+	project, _ := GetProjectFromContext(c)
+
+	reserveCount, err := project.ReserveCount(db.DB(c.Request.Context()))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, RecurrenceResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, RecurrenceResponse{Data: RecurrenceResponseData{Project: *project, ReserveCount: reserveCount}})
+}
+
+// SetProjectRecurrence 设置项目周期规则,cron 为标准 5 段表达式(Asia/Shanghai),round_duration 单位为分钟
+// @Tags project
+// @Accept json
+// @Produce json
+// @Param id path string true "项目ID"
+// @Param recurrence body RecurrenceRequestBody true "周期规则"
+// @Success 200 {object} RecurrenceResponse
+// @Router /api/v1/projects/{id}/recurrence [put]
+func SetProjectRecurrence(c *gin.Context) {
+	// validate req
+	var req RecurrenceRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, RecurrenceResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// load project
+	project, _ := GetProjectFromContext(c)
+
+	// save recurrence
+	if err := project.SetRecurrence(db.DB(c.Request.Context()), req.Cron, req.RoundSize, req.RoundDuration); err != nil {
+		c.JSON(http.StatusBadRequest, RecurrenceResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	reserveCount, err := project.ReserveCount(db.DB(c.Request.Context()))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, RecurrenceResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, RecurrenceResponse{Data: RecurrenceResponseData{Project: *project, ReserveCount: reserveCount}})
+}
+
+// ClearProjectRecurrence 取消项目周期规则
+// @Tags project
+// @Produce json
+// @Param id path string true "项目ID"
+// @Success 200 {object} ProjectResponse
+// @Router /api/v1/projects/{id}/recurrence [delete]
+func ClearProjectRecurrence(c *gin.Context) {
+	// load project
+	project, _ := GetProjectFromContext(c)
+
+	if err := project.ClearRecurrence(db.DB(c.Request.Context())); err != nil {
+		c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ProjectResponse{})
+}
+
+type AddReserveItemsRequestBody struct {
+	Items        []string `json:"items" binding:"required,min=1,max=10000,dive,min=1,max=1024"`
+	EnableFilter bool     `json:"enable_filter"`
+}
+
+// AddProjectReserveItems 向周期项目的预备池追加 item,开启新一轮时按 round_size 补足库存
+// @Tags project
+// @Accept json
+// @Produce json
+// @Param id path string true "项目ID"
+// @Param items body AddReserveItemsRequestBody true "预备 item"
+// @Success 200 {object} ProjectResponse{data=int}
+// @Router /api/v1/projects/{id}/reserve [post]
+func AddProjectReserveItems(c *gin.Context) {
+	// validate req
+	var req AddReserveItemsRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// load project
+	project, _ := GetProjectFromContext(c)
+	if project.DistributionType != DistributionTypeOneForEach {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: RecurrenceOneForEachOnly})
+		return
+	}
+
+	// create reserve items
+	var added int
+	if err := db.DB(c.Request.Context()).Transaction(
+		func(tx *gorm.DB) error {
+			var err error
+			added, err = project.AddReserveItems(tx, req.Items, req.EnableFilter)
+			return err
+		},
+	); err != nil {
+		c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ProjectResponse{Data: added})
+}
+
+type ListProjectRoundsRequest struct {
+	Current int `json:"current" form:"current" binding:"min=1"`
+	Size    int `json:"size" form:"size" binding:"min=1,max=100"`
+}
+
+type ListProjectRoundsResponseData struct {
+	Total   int64          `json:"total"`
+	Results []ProjectRound `json:"results"`
+}
+
+type ListProjectRoundsResponse struct {
+	ErrorMsg string                        `json:"error_msg"`
+	Data     ListProjectRoundsResponseData `json:"data"`
+}
+
+// ListProjectRounds 获取周期项目已结束轮次的历史，仅项目创建者可查看
+// @Tags project
+// @Produce json
+// @Param id path string true "项目ID"
+// @Param request query ListProjectRoundsRequest true "request query"
+// @Success 200 {object} ListProjectRoundsResponse
+// @Router /api/v1/projects/{id}/rounds [get]
+func ListProjectRounds(c *gin.Context) {
+	// validate req
+	req := &ListProjectRoundsRequest{}
+	if err := c.ShouldBindQuery(req); err != nil {
+		c.JSON(http.StatusBadRequest, ListProjectRoundsResponse{ErrorMsg: err.Error()})
+		return
+	}
+	offset := (req.Current - 1) * req.Size
+
+	// load project
+	project, _ := GetProjectFromContext(c)
+
+	query := db.DB(c.Request.Context()).Model(&ProjectRound{}).Where("project_id = ?", project.ID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ListProjectRoundsResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	var rounds []ProjectRound
+	if err := query.Order("round DESC").Offset(offset).Limit(req.Size).Find(&rounds).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ListProjectRoundsResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListProjectRoundsResponse{
+		Data: ListProjectRoundsResponseData{Total: total, Results: rounds},
+	})
+}
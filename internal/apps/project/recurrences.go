@@ -0,0 +1,289 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/logger"
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProjectRound 周期项目已结束轮次的历史记录，在开启下一轮时归档
+type ProjectRound struct {
+	ID             uint64    `json:"id" gorm:"primaryKey,autoIncrement"`
+	ProjectID      string    `json:"project_id" gorm:"size:64;uniqueIndex:idx_project_round"`
+	Round          int       `json:"round" gorm:"uniqueIndex:idx_project_round"`
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	ReceivedCount  int64     `json:"received_count"`
+	RemainingStock int64     `json:"remaining_stock"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// recurrenceLocation 周期表达式的时区，与调度器保持一致
+var recurrenceLocation = func() *time.Location {
+	location, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return time.FixedZone("CST", 8*3600)
+	}
+	return location
+}()
+
+// parseRecurrence 解析标准 5 段 cron 表达式，并校验单轮时长不超过两次开启的间隔，避免轮次重叠
+func parseRecurrence(expr string, duration time.Duration) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, fmt.Errorf(RecurrenceInvalid, err)
+	}
+	first := schedule.Next(time.Now().In(recurrenceLocation))
+	if first.IsZero() {
+		return nil, fmt.Errorf(RecurrenceInvalid, "no upcoming time")
+	}
+	if second := schedule.Next(first); second.Sub(first) < duration {
+		return nil, errors.New(RecurrenceOverlap)
+	}
+	return schedule, nil
+}
+
+// IsRecurring 是否为周期项目:按 RecurrenceCron 在 NextRoundAt 开启新一轮,Round 为当前轮次(从 0 开始)
+func (p *Project) IsRecurring() bool {
+	return p.RecurrenceCron != ""
+}
+
+// RoundDurationTime 单轮持续时长
+func (p *Project) RoundDurationTime() time.Duration {
+	return time.Duration(p.RoundDuration) * time.Minute
+}
+
+// ReserveCount 统计预备池中尚未投放的 item 数量
+func (p *Project) ReserveCount(tx *gorm.DB) (int64, error) {
+	var count int64
+	err := tx.Model(&ProjectItem{}).
+		Where("project_id = ? AND reserved = ?", p.ID, true).
+		Count(&count).Error
+	return count, err
+}
+
+// SetRecurrence 设置周期规则，下一轮在当前轮次结束后的首个匹配时间开启
+func (p *Project) SetRecurrence(tx *gorm.DB, expr string, roundSize int, roundDuration int) error {
+	if p.DistributionType != DistributionTypeOneForEach {
+		return errors.New(RecurrenceOneForEachOnly)
+	}
+	schedule, err := parseRecurrence(expr, time.Duration(roundDuration)*time.Minute)
+	if err != nil {
+		return err
+	}
+
+	from := time.Now()
+	if p.EndTime.After(from) {
+		from = p.EndTime
+	}
+	nextRoundAt := schedule.Next(from.In(recurrenceLocation))
+	p.RecurrenceCron = expr
+	p.RoundSize = roundSize
+	p.RoundDuration = roundDuration
+	p.NextRoundAt = &nextRoundAt
+	return tx.Model(&Project{}).Where("id = ?", p.ID).Updates(map[string]interface{}{
+		"recurrence_cron": expr,
+		"round_size":      roundSize,
+		"round_duration":  roundDuration,
+		"next_round_at":   &nextRoundAt,
+	}).Error
+}
+
+// ClearRecurrence 取消周期规则，当前轮次与预备池保持不变
+func (p *Project) ClearRecurrence(tx *gorm.DB) error {
+	p.RecurrenceCron = ""
+	p.NextRoundAt = nil
+	return tx.Model(&Project{}).Where("id = ?", p.ID).Updates(map[string]interface{}{
+		"recurrence_cron": "",
+		"next_round_at":   nil,
+	}).Error
+}
+
+// AddReserveItems 向预备池追加 item,仅落库不进入 Redis 库存，开启新一轮时再按需投放
+func (p *Project) AddReserveItems(tx *gorm.DB, items []string, enableFilter bool) (int, error) {
+	var existingSet map[string]bool
+	if enableFilter {
+		var err error
+		if existingSet, err = p.existingContentHashes(tx); err != nil {
+			return 0, err
+		}
+	}
+
+	projectItems := make([]ProjectItem, 0, len(items))
+	for _, content := range items {
+		item, err := newProjectItem(p.ID, content)
+		if err != nil {
+			return 0, err
+		}
+		if enableFilter {
+			if existingSet[item.ContentHash] {
+				continue
+			}
+			existingSet[item.ContentHash] = true
+		}
+		item.Reserved = true
+		projectItems = append(projectItems, item)
+	}
+	if len(projectItems) <= 0 {
+		return 0, nil
+	}
+	if err := tx.CreateInBatches(&projectItems, projectItemInsertBatchSize).Error; err != nil {
+		return 0, err
+	}
+	return len(projectItems), nil
+}
+
+// OpenRound 开启下一轮:归档当前轮次，从预备池补足库存至 RoundSize,并设置新的起止时间。
+// 通过行锁与 NextRoundAt 判断保证同一轮只会被开启一次。
+func (p *Project) OpenRound(ctx context.Context, now time.Time) error {
+	opened := false
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", p.ID, ProjectStatusNormal).
+			First(p).Error; err != nil {
+			return err
+		}
+		if !p.IsRecurring() || p.NextRoundAt == nil || p.NextRoundAt.After(now) {
+			return nil
+		}
+		schedule, err := cron.ParseStandard(p.RecurrenceCron)
+		if err != nil {
+			return err
+		}
+
+		// 停机等原因错过整轮时直接顺延到下一个匹配时间
+		startTime := *p.NextRoundAt
+		endTime := startTime.Add(p.RoundDurationTime())
+		if !endTime.After(now) {
+			nextRoundAt := schedule.Next(now.In(recurrenceLocation))
+			logger.InfoF(ctx, "周期项目[%s]错过轮次，顺延至 %s", p.ID, nextRoundAt.Format(time.RFC3339))
+			return tx.Model(&Project{}).Where("id = ?", p.ID).Update("next_round_at", &nextRoundAt).Error
+		}
+
+		// archive current round
+		stock, err := p.Stock(ctx)
+		if err != nil {
+			return err
+		}
+		var receivedCount int64
+		if err := tx.Model(&ProjectClaim{}).
+			Where("project_id = ? AND round = ?", p.ID, p.Round).
+			Count(&receivedCount).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&ProjectRound{
+			ProjectID:      p.ID,
+			Round:          p.Round,
+			StartTime:      p.StartTime,
+			EndTime:        p.EndTime,
+			ReceivedCount:  receivedCount,
+			RemainingStock: stock,
+		}).Error; err != nil {
+			return err
+		}
+
+		// release reserve items
+		var itemIDs []uint64
+		if need := int64(p.RoundSize) - stock; need > 0 {
+			if err := tx.Model(&ProjectItem{}).
+				Where("project_id = ? AND reserved = ?", p.ID, true).
+				Order("id ASC").
+				Limit(int(need)).
+				Pluck("id", &itemIDs).Error; err != nil {
+				return err
+			}
+		}
+		if len(itemIDs) > 0 {
+			if err := tx.Model(&ProjectItem{}).
+				Where("id IN ?", itemIDs).
+				Update("reserved", false).Error; err != nil {
+				return err
+			}
+		}
+
+		nextRoundAt := schedule.Next(endTime.In(recurrenceLocation))
+		if err := tx.Model(&Project{}).Where("id = ?", p.ID).Updates(map[string]interface{}{
+			"round":         p.Round + 1,
+			"start_time":    startTime,
+			"end_time":      endTime,
+			"is_completed":  stock+int64(len(itemIDs)) <= 0,
+			"total_items":   gorm.Expr("total_items + ?", len(itemIDs)),
+			"next_round_at": &nextRoundAt,
+		}).Error; err != nil {
+			return err
+		}
+
+		// push items to redis
+		if len(itemIDs) > 0 {
+			values := make([]interface{}, len(itemIDs))
+			for i, id := range itemIDs {
+				values[i] = id
+			}
+			if err := db.Redis.RPush(ctx, p.ItemsKey(), values...).Err(); err != nil {
+				return err
+			}
+		}
+		logger.InfoF(ctx, "周期项目[%s]开启第 %d 轮，投放 %d 个，结余 %d 个", p.ID, p.Round+1, len(itemIDs), stock)
+		opened = true
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if opened {
+		EnqueueWaitlistOffer(ctx, p.ID)
+	}
+	return nil
+}
+
+// HandleOpenRecurringRounds 扫描到期的周期项目并开启新一轮，单个项目失败不影响其余项目
+func HandleOpenRecurringRounds(ctx context.Context, _ *asynq.Task) error {
+	now := time.Now()
+	var projectIDs []string
+	if err := db.DB(ctx).Model(&Project{}).
+		Where("next_round_at <= ? AND recurrence_cron != '' AND status = ?", now, ProjectStatusNormal).
+		Limit(200).
+		Pluck("id", &projectIDs).Error; err != nil {
+		return err
+	}
+
+	for _, projectID := range projectIDs {
+		p := &Project{ID: projectID}
+		if err := p.OpenRound(ctx, now); err != nil {
+			logger.ErrorF(ctx, "周期项目[%s]开启新一轮失败: %v", projectID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/db"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	// 每周一 20:00,单轮 2 小时
+	schedule, err := parseRecurrence("0 20 * * 1", 2*time.Hour)
+	if err != nil {
+		t.Fatalf("want valid, got %v", err)
+	}
+	next := schedule.Next(time.Date(2025, 6, 1, 0, 0, 0, 0, recurrenceLocation))
+	if want := time.Date(2025, 6, 2, 20, 0, 0, 0, recurrenceLocation); !next.Equal(want) {
+		t.Fatalf("want next %s, got %s", want, next)
+	}
+
+	if _, err := parseRecurrence("not a cron", time.Hour); err == nil {
+		t.Fatalf("want invalid expression rejected")
+	}
+
+	// 每小时开启一轮但单轮 2 小时会重叠
+	if _, err := parseRecurrence("0 * * * *", 2*time.Hour); err == nil {
+		t.Fatalf("want overlapping rounds rejected")
+	}
+}
+
+// reloadProject 重新读取项目的最新状态
+func reloadProject(t *testing.T, p *Project) {
+	t.Helper()
+	if err := db.DB(context.Background()).Where("id = ?", p.ID).First(p).Error; err != nil {
+		t.Fatalf("reload project: %v", err)
+	}
+}
+
+func TestOpenRound(t *testing.T) {
+	setupItemStore(t)
+	ctx := context.Background()
+	if err := db.DB(ctx).AutoMigrate(&ProjectRound{}); err != nil {
+		t.Fatalf("migrate rounds: %v", err)
+	}
+	alice := &oauth.User{ID: 1, Username: "alice", Score: oauth.BaseUserScore}
+	if err := db.DB(ctx).Create(alice).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	// 每小时一轮，每轮补足 2 个，单轮 30 分钟，每人每轮限领 1 个
+	now := time.Now()
+	firstRoundAt := now.Add(-time.Minute)
+	p := &Project{
+		DistributionType: DistributionTypeOneForEach,
+		MaxPerUser:       1,
+		AllowSameIP:      true,
+		RecurrenceCron:   "0 * * * *",
+		RoundSize:        2,
+		RoundDuration:    30,
+		NextRoundAt:      &firstRoundAt,
+	}
+	createStockedProject(t, p, "A", "B")
+	if _, err := p.AddReserveItems(db.DB(ctx), []string{"R1", "R2", "R3"}, false); err != nil {
+		t.Fatalf("add reserve: %v", err)
+	}
+	claimNext(t, p, alice.ID, "203.0.113.7")
+
+	assertRound := func(round int, archivedReceived, archivedRemaining, reserve int64) {
+		t.Helper()
+		reloadProject(t, p)
+		if p.Round != round {
+			t.Fatalf("want round %d, got %d", round, p.Round)
+		}
+		var archived ProjectRound
+		if err := db.DB(ctx).Where("project_id = ? AND round = ?", p.ID, round-1).First(&archived).Error; err != nil {
+			t.Fatalf("load archived round: %v", err)
+		}
+		if archived.ReceivedCount != archivedReceived || archived.RemainingStock != archivedRemaining {
+			t.Fatalf("unexpected archived round %+v", archived)
+		}
+		if count, err := p.ReserveCount(db.DB(ctx)); err != nil || count != reserve {
+			t.Fatalf("want %d reserve items, got %d (%v)", reserve, count, err)
+		}
+		if stock, err := p.Stock(ctx); err != nil || stock != int64(p.RoundSize) {
+			t.Fatalf("want stock topped up to %d, got %d (%v)", p.RoundSize, stock, err)
+		}
+		// 新一轮领取额度重置
+		if err := p.CheckClaimQuota(ctx, alice); err != nil {
+			t.Fatalf("want quota reset in round %d, got %v", round, err)
+		}
+	}
+
+	// 第一轮：结余 1 个，从预备池投放 1 个
+	if err := p.OpenRound(ctx, now); err != nil {
+		t.Fatalf("open round 1: %v", err)
+	}
+	assertRound(1, 1, 1, 2)
+	if p.StartTime.Unix() != firstRoundAt.Unix() || !p.EndTime.Equal(p.StartTime.Add(30*time.Minute)) || !p.NextRoundAt.After(p.EndTime) {
+		t.Fatalf("unexpected round window start=%s end=%s next=%s", p.StartTime, p.EndTime, p.NextRoundAt)
+	}
+
+	// 未到下一轮时间时不重复开启
+	if err := p.OpenRound(ctx, now); err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	reloadProject(t, p)
+	if p.Round != 1 {
+		t.Fatalf("want round unchanged, got %d", p.Round)
+	}
+
+	// 第二轮：本轮再领 1 个后开启，同样补足库存
+	claimNext(t, p, alice.ID, "203.0.113.7")
+	if err := p.OpenRound(ctx, p.NextRoundAt.Add(time.Minute)); err != nil {
+		t.Fatalf("open round 2: %v", err)
+	}
+	assertRound(2, 1, 1, 1)
+}
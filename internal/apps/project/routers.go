@@ -32,7 +32,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/linux-do/cdk/internal/apps/forum"
+	"github.com/linux-do/cdk/internal/apps/notification"
 	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/apps/webhook"
 	"github.com/linux-do/cdk/internal/config"
 	"github.com/linux-do/cdk/internal/db"
 	"github.com/linux-do/cdk/internal/utils"
@@ -45,18 +48,28 @@ type ProjectResponse struct {
 	Data     interface{} `json:"data"`
 }
 
+// ProjectSettings 项目的可复用配置(不含时间与库存),供克隆与模板复用
+type ProjectSettings struct {
+	Name                string           `json:"name" binding:"required,min=1,max=32"`
+	Description         string           `json:"description" binding:"max=1024"`
+	ProjectTags         []string         `json:"project_tags" binding:"dive,min=1,max=16"`
+	MinimumTrustLevel   oauth.TrustLevel `json:"minimum_trust_level" binding:"oneof=0 1 2 3 4"`
+	AllowSameIP         bool             `json:"allow_same_ip"`
+	RiskLevel           int8             `json:"risk_level" binding:"min=0,max=100"`
+	HideFromExplore     bool             `json:"hide_from_explore"`
+	Price               decimal.Decimal  `json:"price"`
+	Currency            Currency         `json:"currency" binding:"omitempty,oneof=LDC CNY CREDIT"`
+	MaxPerUser          int              `json:"max_per_user" binding:"min=0,max=100"`
+	MaxPerUserOverrides TrustLevelQuota  `json:"max_per_user_overrides"`
+	PricingRules        PricingRules     `json:"pricing_rules"`
+}
+
 type ProjectRequest struct {
-	Name              string           `json:"name" binding:"required,min=1,max=32"`
-	Description       string           `json:"description" binding:"max=1024"`
-	ProjectTags       []string         `json:"project_tags" binding:"dive,min=1,max=16"`
-	StartTime         time.Time        `json:"start_time" binding:"required"`
-	EndTime           time.Time        `json:"end_time" binding:"required,gtfield=StartTime"`
-	MinimumTrustLevel oauth.TrustLevel `json:"minimum_trust_level" binding:"oneof=0 1 2 3 4"`
-	AllowSameIP       bool             `json:"allow_same_ip"`
-	RiskLevel         int8             `json:"risk_level" binding:"min=0,max=100"`
-	HideFromExplore   bool             `json:"hide_from_explore"`
-	Price             decimal.Decimal  `json:"price"`
+	ProjectSettings
+	StartTime time.Time `json:"start_time" binding:"required"`
+	EndTime   time.Time `json:"end_time" binding:"required,gtfield=StartTime"`
 }
+
 type GetProjectResponseData struct {
 	Project             `json:",inline"` // 内嵌所有 Project 字段
 	CreatorUsername     string           `json:"creator_username"`
@@ -65,6 +78,12 @@ type GetProjectResponseData struct {
 	AvailableItemsCount int64            `json:"available_items_count"`
 	IsReceived          bool             `json:"is_received"`
 	ReceivedContent     string           `json:"received_content"`
+	ReceivedContents    []string         `json:"received_contents"`
+	ReceivedCount       int              `json:"received_count"`
+	ClaimQuota          int              `json:"claim_quota"`
+	ReceivedReplacedAt  *time.Time       `json:"received_replaced_at"`
+	IsEntered           bool             `json:"is_entered"`
+	DrawSeed            string           `json:"draw_seed"`
 }
 
 // GetProject
@@ -114,16 +133,47 @@ func GetProject(c *gin.Context) {
 	}
 	availableItemsCount := stock
 
-	isReceived := false
 	receivedContent := ""
-	item, err := project.GetReceivedItem(c.Request.Context(), oauth.GetUserIDFromContext(c))
+	var receivedReplacedAt *time.Time
+	items, err := project.GetReceivedItems(c.Request.Context(), oauth.GetUserIDFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+	receivedContents := make([]string, len(items))
+	for i := range items {
+		if receivedContents[i], err = items[i].PlainContent(); err != nil {
+			c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
+			return
+		}
+	}
+	if len(items) > 0 {
+		receivedContent = receivedContents[0]
+		receivedReplacedAt = items[0].ReplacedAt
+	}
+
+	// 周期项目按轮次限领，领取状态以当前轮次为准
+	roundClaimCount, err := project.ClaimCount(db.DB(c.Request.Context()), oauth.GetUserIDFromContext(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
 		return
 	}
-	if item != nil {
-		isReceived = true
-		receivedContent = item.Content
+
+	// 平台抽奖:报名状态及开奖后公开的种子
+	isEntered := false
+	drawSeed := ""
+	if project.DistributionType == DistributionTypeDraw {
+		var entryCount int64
+		if err := db.DB(c.Request.Context()).Model(&ProjectEntry{}).
+			Where("project_id = ? AND user_id = ?", project.ID, oauth.GetUserIDFromContext(c)).
+			Count(&entryCount).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
+			return
+		}
+		isEntered = entryCount > 0
+		if project.IsDrawn() {
+			drawSeed = project.DrawSeed
+		}
 	}
 
 	creatorNickname := user.Nickname
@@ -137,8 +187,14 @@ func GetProject(c *gin.Context) {
 		CreatorNickname:     creatorNickname,
 		Tags:                tags,
 		AvailableItemsCount: availableItemsCount,
-		IsReceived:          isReceived,
+		IsReceived:          roundClaimCount > 0,
 		ReceivedContent:     receivedContent,
+		ReceivedContents:    receivedContents,
+		ReceivedCount:       int(roundClaimCount),
+		ClaimQuota:          project.QuotaFor(currentUser),
+		ReceivedReplacedAt:  receivedReplacedAt,
+		IsEntered:           isEntered,
+		DrawSeed:            drawSeed,
 	}
 
 	c.JSON(http.StatusOK, ProjectResponse{Data: responseData})
@@ -146,9 +202,13 @@ func GetProject(c *gin.Context) {
 
 type CreateProjectRequestBody struct {
 	ProjectRequest
-	DistributionType DistributionType `json:"distribution_type" binding:"oneof=0 1"`
+	DistributionType DistributionType `json:"distribution_type" binding:"oneof=0 1 2 3"`
 	ProjectItems     []string         `json:"project_items" binding:"required,min=1,dive,min=1,max=1024"`
 	TopicId          uint64           `json:"topic_id" binding:"omitempty,gt=0"`
+	InviteCount      int              `json:"invite_count" binding:"min=0,max=10000"`
+	InviteExpireAt   time.Time        `json:"invite_expire_at"`
+	AnnounceToTopic  bool             `json:"announce_to_topic"`
+	AnnounceProgress bool             `json:"announce_progress"`
 }
 
 // CreateProject
@@ -166,11 +226,49 @@ func CreateProject(c *gin.Context) {
 		return
 	}
 
+	createProject(c, &req)
+}
+
+// createProject 校验并创建项目，供直接创建、克隆与模板创建共用，保证各入口遵循同一套规则
+func createProject(c *gin.Context, req *CreateProjectRequestBody) {
 	// init session
 	currentUser, _ := oauth.GetUserFromContext(c)
 
 	// validate price
-	if err := validateProjectPrice(c.Request.Context(), req.Price, req.DistributionType, currentUser.ID); err != nil {
+	currency := req.Currency.OrDefault()
+	if err := validateProjectPrice(c.Request.Context(), req.Price, currency, req.DistributionType, currentUser.ID); err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+	if err := req.PricingRules.Validate(req.Price, currency); err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// validate invite
+	if req.InviteCount > 0 && req.DistributionType != DistributionTypeInvite {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: InviteOnlyForInvite})
+		return
+	}
+
+	// validate topic announcement
+	if (req.AnnounceToTopic || req.AnnounceProgress) && (req.DistributionType != DistributionTypeLottery || req.TopicId == 0) {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: AnnounceOnlyForLottery})
+		return
+	}
+	if req.AnnounceToTopic || req.AnnounceProgress {
+		if err := verifyAnnounceTopic(c.Request.Context(), req.TopicId, currentUser.ID); err != nil {
+			if err.Error() == AnnounceTopicNotOwned {
+				c.JSON(http.StatusForbidden, ProjectResponse{ErrorMsg: err.Error()})
+			} else {
+				c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
+			}
+			return
+		}
+	}
+
+	// validate claim quota
+	if err := req.MaxPerUserOverrides.Validate(); err != nil {
 		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
 		return
 	}
@@ -181,6 +279,7 @@ func CreateProject(c *gin.Context) {
 		Name:              req.Name,
 		Description:       req.Description,
 		DistributionType:  req.DistributionType,
+		TopicID:           req.TopicId,
 		TotalItems:        int64(len(req.ProjectItems)),
 		StartTime:         req.StartTime,
 		EndTime:           req.EndTime,
@@ -191,6 +290,31 @@ func CreateProject(c *gin.Context) {
 		IsCompleted:       false,
 		HideFromExplore:   req.HideFromExplore,
 		Price:             req.Price,
+		Currency:          currency,
+		PricingRules:      req.PricingRules,
+		AnnounceToTopic:   req.AnnounceToTopic,
+		AnnounceProgress:  req.AnnounceProgress,
+	}
+	project.applyClaimQuota(req.MaxPerUser, req.MaxPerUserOverrides)
+
+	// 邀请码项目不在广场展示
+	if project.IsInviteOnly() {
+		project.HideFromExplore = true
+	}
+	inviteExpireAt := req.InviteExpireAt
+	if inviteExpireAt.IsZero() {
+		inviteExpireAt = req.EndTime
+	}
+
+	// 平台抽奖:创建时公开种子承诺值,开奖后公开种子
+	if project.DistributionType == DistributionTypeDraw {
+		seed, commitment, err := genDrawSeed()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
+			return
+		}
+		project.DrawSeed = seed
+		project.DrawSeedHash = commitment
 	}
 
 	// create project
@@ -208,12 +332,24 @@ func CreateProject(c *gin.Context) {
 			if err := project.CreateItems(c.Request.Context(), tx, req.ProjectItems, req.TopicId); err != nil {
 				return err
 			}
+			// create invites
+			if _, err := project.CreateInvites(c.Request.Context(), tx, req.InviteCount, inviteExpireAt); err != nil {
+				return err
+			}
+			// schedule draw
+			if project.DistributionType == DistributionTypeDraw {
+				return project.EnqueueDraw(c.Request.Context())
+			}
 			return nil
 		},
 	); err != nil {
 		c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
 		return
 	}
+	if project.DistributionType == DistributionTypeLottery {
+		project.NotifyWinners(c.Request.Context())
+		project.AnnounceToLotteryTopic(c.Request.Context())
+	}
 
 	// response
 	c.JSON(http.StatusOK, ProjectResponse{
@@ -246,13 +382,30 @@ func UpdateProject(c *gin.Context) {
 	// load project
 	project, _ := GetProjectFromContext(c)
 
-	// validate price (复用创建者 ID + 原分发类型)
-	if err := validateProjectPrice(c.Request.Context(), req.Price, project.DistributionType, project.CreatorID); err != nil {
+	// validate price (复用创建者 ID + 原分发类型;未传币种时沿用项目原币种)
+	currency := req.Currency
+	if currency == "" {
+		currency = project.Currency.OrDefault()
+	}
+	if err := validateProjectPrice(c.Request.Context(), req.Price, currency, project.DistributionType, project.CreatorID); err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+	if err := req.PricingRules.Validate(req.Price, currency); err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// validate claim quota
+	if err := req.MaxPerUserOverrides.Validate(); err != nil {
 		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
 		return
 	}
 
 	// init project
+	startTimeChanged := !project.StartTime.Equal(req.StartTime)
+	endTimeChanged := !project.EndTime.Equal(req.EndTime)
+	originalTotalItems := project.TotalItems
 	project.Name = req.Name
 	project.Description = req.Description
 	project.StartTime = req.StartTime
@@ -260,16 +413,30 @@ func UpdateProject(c *gin.Context) {
 	project.MinimumTrustLevel = req.MinimumTrustLevel
 	project.AllowSameIP = req.AllowSameIP
 	project.RiskLevel = req.RiskLevel
-	project.HideFromExplore = req.HideFromExplore
+	project.HideFromExplore = req.HideFromExplore || project.IsInviteOnly()
 	project.Price = req.Price
+	project.Currency = currency
+	project.PricingRules = req.PricingRules
+	project.applyClaimQuota(req.MaxPerUser, req.MaxPerUserOverrides)
 
-	if project.DistributionType == DistributionTypeLottery {
+	if project.IsWinnerBased() {
 		// save project
 		if err := db.DB(c.Request.Context()).Save(&project).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
-		} else {
-			c.JSON(http.StatusOK, ProjectResponse{})
+			return
+		}
+		// 未开奖的平台抽奖按新的开始时间重新下发开奖任务
+		if project.DistributionType == DistributionTypeDraw && !project.IsDrawn() && startTimeChanged {
+			if err := project.EnqueueDraw(c.Request.Context()); err != nil {
+				c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
+				return
+			}
 		}
+		// 结束时间提前时按新的时间下发进度回帖任务，推迟的情况由任务自行顺延
+		if endTimeChanged {
+			project.EnqueueProgressAnnouncement(c.Request.Context())
+		}
+		c.JSON(http.StatusOK, ProjectResponse{})
 		return
 	}
 
@@ -307,6 +474,11 @@ func UpdateProject(c *gin.Context) {
 		return
 	}
 
+	// 补充库存后分配给候补用户
+	if project.TotalItems > originalTotalItems && project.IsWaitlistable() {
+		EnqueueWaitlistOffer(c.Request.Context(), project.ID)
+	}
+
 	// response
 	c.JSON(http.StatusOK, ProjectResponse{})
 }
@@ -346,12 +518,24 @@ func DeleteProject(c *gin.Context) {
 			if err := tx.Where("project_id = ?", project.ID).Delete(&ProjectItem{}).Error; err != nil {
 				return err
 			}
+			// delete project invites
+			if err := project.DeleteInvites(c.Request.Context(), tx); err != nil {
+				return err
+			}
+			// delete project entries
+			if err := tx.Where("project_id = ?", project.ID).Delete(&ProjectEntry{}).Error; err != nil {
+				return err
+			}
+			// delete project rounds
+			if err := tx.Where("project_id = ?", project.ID).Delete(&ProjectRound{}).Error; err != nil {
+				return err
+			}
 			// delete project
 			if err := tx.Where("id = ?", project.ID).Delete(&Project{}).Error; err != nil {
 				return err
 			}
 			// delete items cache
-			if err := db.Redis.Del(c.Request.Context(), project.ItemsKey()).Err(); err != nil {
+			if err := db.Redis.Del(c.Request.Context(), project.ItemsKey(), project.ReturnedItemsKey()).Err(); err != nil {
 				return err
 			}
 			return nil
@@ -399,15 +583,20 @@ func ListProjectReceivers(c *gin.Context) {
 		Where("project_items.project_id = ?", project.ID)
 
 	if req.Search != "" {
+		searchHash, err := ItemContentHash(strings.TrimSpace(req.Search))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
+			return
+		}
 		searchPattern := strings.TrimSpace(req.Search) + "%"
 		if len(searchPattern) > 21 {
 			query = query.Where(
-				"users.nickname LIKE ? OR project_items.content LIKE ?",
-				"%"+searchPattern, "%"+searchPattern)
+				"users.nickname LIKE ? OR project_items.content LIKE ? OR project_items.content_hash = ?",
+				"%"+searchPattern, "%"+searchPattern, searchHash)
 		} else {
 			query = query.Where(
-				"users.username LIKE ? OR users.nickname LIKE ? OR project_items.content LIKE ?",
-				searchPattern, "%"+searchPattern, "%"+searchPattern)
+				"users.username LIKE ? OR users.nickname LIKE ? OR project_items.content LIKE ? OR project_items.content_hash = ?",
+				searchPattern, "%"+searchPattern, "%"+searchPattern, searchHash)
 		}
 	}
 
@@ -424,11 +613,25 @@ func ListProjectReceivers(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
 		return
 	}
+	for i := range receivers {
+		content, err := DecryptItemContent(receivers[i].Content)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
+			return
+		}
+		receivers[i].Content = content
+	}
 
 	// response
 	c.JSON(http.StatusOK, ProjectResponse{Data: receivers})
 }
 
+// ReceiveProjectRequestBody 领取请求体,所有字段可选
+type ReceiveProjectRequestBody struct {
+	InviteToken string `json:"invite_token" binding:"max=64"`
+	CouponCode  string `json:"coupon_code" binding:"max=32"`
+}
+
 type ReportProjectRequestBody struct {
 	Reason string `json:"reason" binding:"required,min=1,max=255"`
 }
@@ -458,6 +661,8 @@ func ReportProject(c *gin.Context) {
 
 	// init session
 	userID := oauth.GetUserIDFromContext(c)
+	version := project.UpdatedAt
+	hidden := false
 
 	if err := db.DB(c.Request.Context()).Transaction(
 		func(tx *gorm.DB) error {
@@ -485,12 +690,33 @@ func ReportProject(c *gin.Context) {
 				}
 				return err
 			}
+			// notify creator
+			if err := tx.Select("report_count", "status").Where("id = ?", project.ID).First(project).Error; err != nil {
+				return err
+			}
+			webhook.Emit(c.Request.Context(), tx, project.CreatorID, webhook.EventProjectReported, webhook.ProjectReportedData{
+				ProjectID:   project.ID,
+				ProjectName: project.Name,
+				Source:      webhook.ReportSourceReport,
+				Status:      uint8(project.Status),
+				ReportCount: project.ReportCount,
+				Reason:      req.Reason,
+			})
+			// 举报数恰好达到阈值的这次举报触发了隐藏
+			hidden = project.Status == ProjectStatusHidden && project.ReportCount == config.Config.ProjectApp.HiddenThreshold
+			if hidden {
+				return notification.Send(tx, notification.ProjectHidden(project.ID, project.Name, project.ReportCount), project.CreatorID)
+			}
 			return nil
 		},
 	); err != nil {
 		c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
 		return
 	}
+	if hidden {
+		forum.EnqueuePrivateMessage(c.Request.Context(), forum.KindHidden, forum.ReviewKey(forum.KindHidden, project.ID, version),
+			project.Creator.Username, project.ID, project.Name)
+	}
 
 	c.JSON(http.StatusOK, ProjectResponse{})
 }
@@ -508,6 +734,8 @@ type ListReceiveHistoryResponseDataResult struct {
 	ProjectCreatorNickname string     `json:"project_creator_nickname"`
 	Content                string     `json:"content"`
 	ReceivedAt             *time.Time `json:"received_at"`
+	ReplacedAt             *time.Time `json:"replaced_at"`
+	ReplaceReason          string     `json:"replace_reason"`
 }
 
 type ListReceiveHistoryResponseData struct {
@@ -577,7 +805,9 @@ func ListReceiveHistory(c *gin.Context) {
             users.username as project_creator,
             COALESCE(NULLIF(users.nickname, ''), users.username) as project_creator_nickname,
             project_items.content,
-            project_items.received_at
+            project_items.received_at,
+            project_items.replaced_at,
+            project_items.replace_reason
         `).
 		Order("project_items.received_at DESC, project_items.id DESC").
 		Offset(offset).
@@ -586,6 +816,14 @@ func ListReceiveHistory(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, ListReceiveHistoryResponse{ErrorMsg: err.Error()})
 		return
 	}
+	for i := range results {
+		content, err := DecryptItemContent(results[i].Content)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ListReceiveHistoryResponse{ErrorMsg: err.Error()})
+			return
+		}
+		results[i].Content = content
+	}
 
 	c.JSON(
 		http.StatusOK,
@@ -642,6 +880,7 @@ type ListProjectsResponseDataResult struct {
 	RiskLevel         int8              `json:"risk_level"`
 	HideFromExplore   bool              `json:"hide_from_explore"`
 	Price             decimal.Decimal   `json:"price"`
+	Currency          Currency          `json:"currency"`
 	Tags              utils.StringArray `json:"tags"`
 	CreatedAt         time.Time         `json:"created_at"`
 }
@@ -0,0 +1,92 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/cdk/internal/db"
+)
+
+// updateProject 以创建者身份调用 UpdateProject
+func updateProject(t *testing.T, p *Project, body map[string]interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	raw, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/projects/"+p.ID, bytes.NewReader(raw))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(ProjectObjKey, p)
+	UpdateProject(c)
+	return w
+}
+
+func TestUpdateProjectKeepsCurrency(t *testing.T) {
+	setupItemStore(t)
+	ctx := context.Background()
+	if err := db.DB(ctx).AutoMigrate(&ProjectTag{}); err != nil {
+		t.Fatalf("migrate tags: %v", err)
+	}
+	p := &Project{DistributionType: DistributionTypeOneForEach, Currency: CurrencyCredit}
+	createStockedProject(t, p, "a")
+
+	now := time.Now()
+	body := map[string]interface{}{
+		"name":       "renamed",
+		"start_time": now.Add(-time.Hour),
+		"end_time":   now.Add(2 * time.Hour),
+	}
+
+	// 未传币种时保留原币种
+	if w := updateProject(t, p, body); w.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", w.Code, w.Body.String())
+	}
+	var got Project
+	if err := db.DB(ctx).Where("id = ?", p.ID).First(&got).Error; err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if got.Name != "renamed" || got.Currency != CurrencyCredit {
+		t.Fatalf("want currency kept as CREDIT, got name=%q currency=%q", got.Name, got.Currency)
+	}
+
+	// 显式传入时按请求修改
+	body["currency"] = CurrencyLDC
+	if w := updateProject(t, &got, body); w.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", w.Code, w.Body.String())
+	}
+	if err := db.DB(ctx).Where("id = ?", p.ID).First(&got).Error; err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if got.Currency != CurrencyLDC {
+		t.Fatalf("want currency changed to LDC, got %q", got.Currency)
+	}
+}
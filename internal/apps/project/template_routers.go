@@ -0,0 +1,270 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/db"
+	"gorm.io/gorm"
+)
+
+// CloneProject 复制项目的配置与标签创建新项目，需补充新的时间与库存
+// @Tags project
+// @Accept json
+// @Produce json
+// @Param id path string true "项目ID"
+// @Param project body CreateFromSettingsRequestBody true "新项目的时间与库存"
+// @Success 200 {object} ProjectResponse
+// @Router /api/v1/projects/{id}/clone [post]
+func CloneProject(c *gin.Context) {
+	// validate req
+	var body CreateFromSettingsRequestBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// load source project
+	source, _ := GetProjectFromContext(c)
+	settings, err := source.Settings(db.DB(c.Request.Context()))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// build create request
+	req, err := body.toCreateRequest(settings, source.DistributionType)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	createProject(c, req)
+}
+
+type ListProjectTemplatesResponse struct {
+	ErrorMsg string            `json:"error_msg"`
+	Data     []ProjectTemplate `json:"data"`
+}
+
+// ListProjectTemplates 获取当前用户保存的项目模板
+// @Tags project
+// @Produce json
+// @Success 200 {object} ListProjectTemplatesResponse
+// @Router /api/v1/project-templates [get]
+func ListProjectTemplates(c *gin.Context) {
+	var templates []ProjectTemplate
+	if err := db.DB(c.Request.Context()).
+		Where("user_id = ?", oauth.GetUserIDFromContext(c)).
+		Order("id DESC").
+		Find(&templates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ListProjectTemplatesResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListProjectTemplatesResponse{Data: templates})
+}
+
+type ProjectTemplateRequestBody struct {
+	Title            string           `json:"title" binding:"required,min=1,max=64"`
+	DistributionType DistributionType `json:"distribution_type" binding:"oneof=0 1 2 3"`
+	Settings         ProjectSettings  `json:"settings"`
+}
+
+// validate 保存模板时提前执行与创建项目一致的配置校验
+func (r *ProjectTemplateRequestBody) validate(c *gin.Context) error {
+	if err := r.Settings.MaxPerUserOverrides.Validate(); err != nil {
+		return err
+	}
+	currency := r.Settings.Currency.OrDefault()
+	if err := r.Settings.PricingRules.Validate(r.Settings.Price, currency); err != nil {
+		return err
+	}
+	return validateProjectPrice(c.Request.Context(), r.Settings.Price, currency, r.DistributionType, oauth.GetUserIDFromContext(c))
+}
+
+// CreateProjectTemplate 保存项目模板
+// @Tags project
+// @Accept json
+// @Produce json
+// @Param template body ProjectTemplateRequestBody true "模板信息"
+// @Success 200 {object} ProjectResponse{data=ProjectTemplate}
+// @Router /api/v1/project-templates [post]
+func CreateProjectTemplate(c *gin.Context) {
+	// validate req
+	var req ProjectTemplateRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+	if err := req.validate(c); err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// check limit
+	userID := oauth.GetUserIDFromContext(c)
+	var count int64
+	if err := db.DB(c.Request.Context()).Model(&ProjectTemplate{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+	if count >= maxTemplatesPerUser {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: TemplateLimitReached})
+		return
+	}
+
+	// create template
+	template := ProjectTemplate{
+		UserID:           userID,
+		Title:            req.Title,
+		DistributionType: req.DistributionType,
+		Settings:         req.Settings,
+	}
+	if err := db.DB(c.Request.Context()).Create(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ProjectResponse{Data: template})
+}
+
+// loadOwnTemplate 加载当前用户的模板
+func loadOwnTemplate(c *gin.Context) (*ProjectTemplate, int, error) {
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	template := &ProjectTemplate{}
+	if err := db.DB(c.Request.Context()).
+		Where("id = ? AND user_id = ?", templateID, oauth.GetUserIDFromContext(c)).
+		First(template).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, http.StatusNotFound, errors.New(TemplateNotFound)
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	return template, http.StatusOK, nil
+}
+
+// UpdateProjectTemplate 更新项目模板
+// @Tags project
+// @Accept json
+// @Produce json
+// @Param id path int true "模板ID"
+// @Param template body ProjectTemplateRequestBody true "模板信息"
+// @Success 200 {object} ProjectResponse
+// @Router /api/v1/project-templates/{id} [put]
+func UpdateProjectTemplate(c *gin.Context) {
+	// validate req
+	var req ProjectTemplateRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+	if err := req.validate(c); err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// load template
+	template, status, err := loadOwnTemplate(c)
+	if err != nil {
+		c.JSON(status, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// save template
+	template.Title = req.Title
+	template.DistributionType = req.DistributionType
+	template.Settings = req.Settings
+	if err := db.DB(c.Request.Context()).Save(template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ProjectResponse{})
+}
+
+// DeleteProjectTemplate 删除项目模板
+// @Tags project
+// @Produce json
+// @Param id path int true "模板ID"
+// @Success 200 {object} ProjectResponse
+// @Router /api/v1/project-templates/{id} [delete]
+func DeleteProjectTemplate(c *gin.Context) {
+	// load template
+	template, status, err := loadOwnTemplate(c)
+	if err != nil {
+		c.JSON(status, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// do delete
+	if err := db.DB(c.Request.Context()).Delete(template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ProjectResponse{})
+}
+
+// CreateProjectFromTemplate 基于模板创建项目，需补充时间与库存
+// @Tags project
+// @Accept json
+// @Produce json
+// @Param id path int true "模板ID"
+// @Param project body CreateFromSettingsRequestBody true "新项目的时间与库存"
+// @Success 200 {object} ProjectResponse
+// @Router /api/v1/project-templates/{id}/projects [post]
+func CreateProjectFromTemplate(c *gin.Context) {
+	// validate req
+	var body CreateFromSettingsRequestBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// load template
+	template, status, err := loadOwnTemplate(c)
+	if err != nil {
+		c.JSON(status, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// build create request
+	req, err := body.toCreateRequest(template.Settings, template.DistributionType)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	createProject(c, req)
+}
@@ -0,0 +1,205 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/db"
+)
+
+// callHandler 以指定用户(及路由加载的项目)调用处理函数
+func callHandler(handler gin.HandlerFunc, method string, params gin.Params, user *oauth.User, p *Project, body interface{}) *httptest.ResponseRecorder {
+	raw, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, "/", bytes.NewReader(raw))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	c.Set(oauth.UserObjKey, user)
+	if p != nil {
+		c.Set(ProjectObjKey, p)
+	}
+	handler(c)
+	return w
+}
+
+// createdProjectID 解析创建接口返回的项目 ID
+func createdProjectID(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data struct {
+			ProjectID string `json:"projectId"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Data.ProjectID == "" {
+		t.Fatalf("parse response %s: %v", w.Body.String(), err)
+	}
+	return resp.Data.ProjectID
+}
+
+// setupTemplateStore 在 item 存储之外迁移标签与模板表，并创建两个用户
+func setupTemplateStore(t *testing.T) (alice, bob *oauth.User) {
+	t.Helper()
+	setupItemStore(t)
+	ctx := context.Background()
+	if err := db.DB(ctx).AutoMigrate(&ProjectTag{}, &ProjectTemplate{}, &ProjectInvite{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	alice = &oauth.User{ID: 1, Username: "alice", Score: oauth.BaseUserScore}
+	bob = &oauth.User{ID: 2, Username: "bob", Score: oauth.BaseUserScore}
+	if err := db.DB(ctx).Create([]*oauth.User{alice, bob}).Error; err != nil {
+		t.Fatalf("create users: %v", err)
+	}
+	return alice, bob
+}
+
+func TestCloneProject(t *testing.T) {
+	alice, bob := setupTemplateStore(t)
+	ctx := context.Background()
+
+	source := &Project{
+		Name:             "源项目",
+		Description:      "每人两份",
+		DistributionType: DistributionTypeOneForEach,
+		CreatorID:        alice.ID,
+		MaxPerUser:       2,
+		RiskLevel:        30,
+		TopicID:          123,
+		AllowSameIP:      true,
+	}
+	createStockedProject(t, source, "OLD-1", "OLD-2")
+	if err := source.RefreshTags(db.DB(ctx), []string{"游戏"}); err != nil {
+		t.Fatalf("tags: %v", err)
+	}
+	claimNext(t, source, bob.ID, "203.0.113.7")
+
+	now := time.Now()
+	w := callHandler(CloneProject, http.MethodPost, gin.Params{{Key: "id", Value: source.ID}}, alice, source, map[string]interface{}{
+		"start_time":    now,
+		"end_time":      now.Add(time.Hour),
+		"project_items": []string{"NEW-1"},
+	})
+	cloneID := createdProjectID(t, w)
+
+	// 复制配置与标签
+	var clone Project
+	if err := db.DB(ctx).Where("id = ?", cloneID).First(&clone).Error; err != nil {
+		t.Fatalf("load clone: %v", err)
+	}
+	if clone.Name != source.Name || clone.Description != source.Description || clone.MaxPerUser != 2 ||
+		clone.RiskLevel != 30 || !clone.AllowSameIP || clone.CreatorID != alice.ID {
+		t.Fatalf("want settings copied, got %+v", clone)
+	}
+	if tags, err := clone.GetTags(db.DB(ctx)); err != nil || len(tags) != 1 || tags[0] != "游戏" {
+		t.Fatalf("want tags copied, got %v (%v)", tags, err)
+	}
+
+	// 不复制库存、领取记录与话题
+	if clone.TopicID != 0 || clone.TotalItems != 1 {
+		t.Fatalf("want no topic and only new items, got topic=%d total=%d", clone.TopicID, clone.TotalItems)
+	}
+	var items []ProjectItem
+	db.DB(ctx).Where("project_id = ?", cloneID).Find(&items)
+	if len(items) != 1 {
+		t.Fatalf("want 1 item in clone, got %d", len(items))
+	}
+	if content, _ := items[0].PlainContent(); content != "NEW-1" || items[0].ReceiverID != nil {
+		t.Fatalf("want fresh NEW-1 item, got %q receiver=%v", content, items[0].ReceiverID)
+	}
+	var claims int64
+	db.DB(ctx).Model(&ProjectClaim{}).Where("project_id = ?", cloneID).Count(&claims)
+	if claims != 0 {
+		t.Fatalf("want no claims copied, got %d", claims)
+	}
+}
+
+func TestProjectTemplateOwnership(t *testing.T) {
+	alice, bob := setupTemplateStore(t)
+	ctx := context.Background()
+
+	w := callHandler(CreateProjectTemplate, http.MethodPost, nil, alice, nil, map[string]interface{}{
+		"title":             "周常",
+		"distribution_type": DistributionTypeOneForEach,
+		"settings": map[string]interface{}{
+			"name":         "模板项目",
+			"max_per_user": 3,
+		},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("create template: %d %s", w.Code, w.Body.String())
+	}
+	var template ProjectTemplate
+	if err := db.DB(ctx).Where("user_id = ?", alice.ID).First(&template).Error; err != nil {
+		t.Fatalf("load template: %v", err)
+	}
+	params := gin.Params{{Key: "id", Value: strconv.FormatUint(template.ID, 10)}}
+	now := time.Now()
+	body := map[string]interface{}{
+		"start_time":    now,
+		"end_time":      now.Add(time.Hour),
+		"project_items": []string{"T-1"},
+	}
+
+	// 其他用户的模板不可使用、修改或删除
+	if w := callHandler(CreateProjectFromTemplate, http.MethodPost, params, bob, nil, body); w.Code != http.StatusNotFound {
+		t.Fatalf("want 404 for another user's template, got %d %s", w.Code, w.Body.String())
+	}
+	if w := callHandler(UpdateProjectTemplate, http.MethodPut, params, bob, nil, map[string]interface{}{
+		"title": "改", "distribution_type": DistributionTypeOneForEach, "settings": map[string]interface{}{"name": "x"},
+	}); w.Code != http.StatusNotFound {
+		t.Fatalf("want 404 when updating another user's template, got %d", w.Code)
+	}
+	if w := callHandler(DeleteProjectTemplate, http.MethodDelete, params, bob, nil, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("want 404 when deleting another user's template, got %d", w.Code)
+	}
+	var count int64
+	db.DB(ctx).Model(&Project{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("want no project created from another user's template, got %d", count)
+	}
+
+	// 创建者可基于模板创建项目
+	projectID := createdProjectID(t, callHandler(CreateProjectFromTemplate, http.MethodPost, params, alice, nil, body))
+	var created Project
+	if err := db.DB(ctx).Where("id = ?", projectID).First(&created).Error; err != nil {
+		t.Fatalf("load project: %v", err)
+	}
+	if created.Name != "模板项目" || created.MaxPerUser != 3 || created.CreatorID != alice.ID || created.TotalItems != 1 {
+		t.Fatalf("want project built from template, got %+v", created)
+	}
+}
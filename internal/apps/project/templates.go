@@ -0,0 +1,103 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// ProjectTemplate 用户保存的项目模板，仅保存可复用配置与标签，不含时间与库存
+type ProjectTemplate struct {
+	ID               uint64           `json:"id" gorm:"primaryKey,autoIncrement"`
+	UserID           uint64           `json:"user_id" gorm:"index"`
+	Title            string           `json:"title" gorm:"size:64"`
+	DistributionType DistributionType `json:"distribution_type"`
+	Settings         ProjectSettings  `json:"settings" gorm:"type:json;serializer:json"`
+	CreatedAt        time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Settings 提取项目的可复用配置
+func (p *Project) Settings(tx *gorm.DB) (ProjectSettings, error) {
+	tags, err := p.GetTags(tx)
+	if err != nil {
+		return ProjectSettings{}, err
+	}
+	return ProjectSettings{
+		Name:                p.Name,
+		Description:         p.Description,
+		ProjectTags:         tags,
+		MinimumTrustLevel:   p.MinimumTrustLevel,
+		AllowSameIP:         p.AllowSameIP,
+		RiskLevel:           p.RiskLevel,
+		HideFromExplore:     p.HideFromExplore,
+		Price:               p.Price,
+		Currency:            p.Currency,
+		MaxPerUser:          p.MaxPerUser,
+		MaxPerUserOverrides: p.MaxPerUserOverrides,
+		PricingRules:        p.PricingRules,
+	}, nil
+}
+
+// CreateFromSettingsRequestBody 基于已有配置(克隆或模板)创建项目时需补充的时间与库存
+type CreateFromSettingsRequestBody struct {
+	Name             string    `json:"name" binding:"max=32"`
+	StartTime        time.Time `json:"start_time" binding:"required"`
+	EndTime          time.Time `json:"end_time" binding:"required,gtfield=StartTime"`
+	ProjectItems     []string  `json:"project_items" binding:"required,min=1,dive,min=1,max=1024"`
+	TopicId          uint64    `json:"topic_id" binding:"omitempty,gt=0"`
+	InviteCount      int       `json:"invite_count" binding:"min=0,max=10000"`
+	InviteExpireAt   time.Time `json:"invite_expire_at"`
+	AnnounceToTopic  bool      `json:"announce_to_topic"`
+	AnnounceProgress bool      `json:"announce_progress"`
+}
+
+// toCreateRequest 合并配置与补充信息为创建请求，并按创建接口的绑定规则重新校验
+func (r *CreateFromSettingsRequestBody) toCreateRequest(settings ProjectSettings, dt DistributionType) (*CreateProjectRequestBody, error) {
+	if r.Name != "" {
+		settings.Name = r.Name
+	}
+	req := &CreateProjectRequestBody{
+		ProjectRequest: ProjectRequest{
+			ProjectSettings: settings,
+			StartTime:       r.StartTime,
+			EndTime:         r.EndTime,
+		},
+		DistributionType: dt,
+		ProjectItems:     r.ProjectItems,
+		TopicId:          r.TopicId,
+		InviteCount:      r.InviteCount,
+		InviteExpireAt:   r.InviteExpireAt,
+		AnnounceToTopic:  r.AnnounceToTopic,
+		AnnounceProgress: r.AnnounceProgress,
+	}
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
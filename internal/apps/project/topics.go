@@ -0,0 +1,144 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/cdk/internal/apps/forum"
+	"github.com/linux-do/cdk/internal/config"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/logger"
+	"github.com/linux-do/cdk/internal/task"
+	"github.com/linux-do/cdk/internal/task/schedule"
+	"gorm.io/gorm"
+)
+
+// announceTaskPayload 领取进度回帖任务参数
+type announceTaskPayload struct {
+	ProjectID string `json:"project_id"`
+}
+
+// canAnnounce 是否可回帖到原抽奖话题
+func (p *Project) canAnnounce() bool {
+	return p.DistributionType == DistributionTypeLottery && p.TopicID > 0 && config.Config.ForumNotify.AnnounceEnabled
+}
+
+// verifyAnnounceTopic 开启回帖公告前校验话题作者为项目创建者，避免借机器人账号向他人的话题回帖
+func verifyAnnounceTopic(ctx context.Context, topicID, creatorID uint64) error {
+	topic, err := fetchLotteryTopic(ctx, topicID)
+	if err != nil {
+		return err
+	}
+	if topic.UserID != creatorID {
+		return errors.New(AnnounceTopicNotOwned)
+	}
+	return nil
+}
+
+// AnnounceToLotteryTopic 项目创建后回帖到原抽奖话题公布领取链接，并按需下发结束时的进度回帖任务;需在创建事务提交后调用
+func (p *Project) AnnounceToLotteryTopic(ctx context.Context) {
+	if !p.canAnnounce() {
+		return
+	}
+	if p.AnnounceToTopic {
+		forum.EnqueueTopicReply(ctx, forum.KindAnnounce, forum.AnnounceKey(p.ID), p.TopicID, forum.TemplateData{
+			ProjectName: p.Name,
+			ProjectURL:  forum.ProjectURL(p.ID),
+		})
+	}
+	p.EnqueueProgressAnnouncement(ctx)
+}
+
+// EnqueueProgressAnnouncement 下发在 EndTime 执行的领取进度回帖任务;回帖按项目去重，重复下发无副作用
+func (p *Project) EnqueueProgressAnnouncement(ctx context.Context) {
+	if !p.AnnounceProgress || !p.canAnnounce() {
+		return
+	}
+	payload, _ := json.Marshal(announceTaskPayload{ProjectID: p.ID})
+	if _, err := schedule.AsynqClient.EnqueueContext(
+		ctx,
+		asynq.NewTask(task.AnnounceProgressTask, payload),
+		asynq.ProcessAt(p.EndTime),
+		asynq.MaxRetry(5),
+	); err != nil {
+		logger.ErrorF(ctx, "下发项目[%s]进度回帖任务失败: %v", p.ID, err)
+		return
+	}
+	logger.InfoF(ctx, "下发项目[%s]进度回帖任务成功，执行时间 %s", p.ID, p.EndTime.Format(time.RFC3339))
+}
+
+// ClaimProgress 统计已领取与全部 item 数量(不含退款作废的 item);抽奖项目同一中奖者的多份奖品合并为一个 item,因此不使用 TotalItems
+func (p *Project) ClaimProgress(tx *gorm.DB) (claimed int64, total int64, err error) {
+	err = tx.Model(&ProjectItem{}).
+		Select("COUNT(receiver_id), COUNT(*)").
+		Where("project_id = ? AND revoked_at IS NULL", p.ID).
+		Row().Scan(&claimed, &total)
+	return claimed, total, err
+}
+
+// HandleAnnounceProgress 处理领取进度回帖任务：项目结束后回帖公布 x/y 领取进度
+func HandleAnnounceProgress(ctx context.Context, t *asynq.Task) error {
+	var payload announceTaskPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("解析任务参数失败: %w", err)
+	}
+
+	// 仅正常状态的项目回帖，被隐藏或判定违规的项目不再公开
+	var p Project
+	if err := db.DB(ctx).Where("id = ? AND status = ?", payload.ProjectID, ProjectStatusNormal).First(&p).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.InfoF(ctx, "项目[%s]不存在或已不可见，跳过进度回帖", payload.ProjectID)
+			return nil
+		}
+		return err
+	}
+	if !p.AnnounceProgress || !p.canAnnounce() {
+		return nil
+	}
+
+	// EndTime 被推迟时按新的时间重新下发
+	if time.Now().Before(p.EndTime) {
+		p.EnqueueProgressAnnouncement(ctx)
+		return nil
+	}
+
+	claimed, total, err := p.ClaimProgress(db.DB(ctx))
+	if err != nil {
+		return err
+	}
+	forum.EnqueueTopicReply(ctx, forum.KindProgress, forum.ProgressKey(p.ID), p.TopicID, forum.TemplateData{
+		ProjectName: p.Name,
+		ProjectURL:  forum.ProjectURL(p.ID),
+		Claimed:     claimed,
+		Total:       total,
+	})
+	return nil
+}
@@ -0,0 +1,213 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/cdk/internal/apps/forum"
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/config"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/task"
+)
+
+// topicReply 话题回帖任务参数
+type topicReply struct {
+	Key     string             `json:"key"`
+	TopicID uint64             `json:"topic_id"`
+	Data    forum.TemplateData `json:"data"`
+}
+
+// enableTopicAnnounce 开启回帖公告，论坛接口指向返回指定作者抽奖话题的测试服务
+func enableTopicAnnounce(t *testing.T, topicID, authorID uint64) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != fmt.Sprintf("/t/%d.json", topicID) {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"user_id": authorID,
+			"tags":    []map[string]string{{"slug": "lottery"}},
+			"closed":  true,
+			"post_stream": map[string]interface{}{
+				"posts": []map[string]string{{"username": "lottery_bot", "raw": "### 以下为中奖佬友及对应楼层：\n@bob 2楼\n"}},
+			},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	linuxDo, forumNotify := config.Config.LinuxDo, config.Config.ForumNotify
+	t.Cleanup(func() { config.Config.LinuxDo, config.Config.ForumNotify = linuxDo, forumNotify })
+	config.Config.LinuxDo.BaseURL = srv.URL
+	config.Config.ForumNotify.AnnounceEnabled = true
+}
+
+// topicTasks 已下发的话题回帖任务与进度回帖任务
+func topicTasks(t *testing.T, mr *miniredis.Miniredis) (replies []topicReply, progress []*asynq.TaskInfo) {
+	t.Helper()
+	inspector := asynq.NewInspector(asynq.RedisClientOpt{Addr: mr.Addr()})
+	defer func() { _ = inspector.Close() }()
+	// 尚未下发任何任务时队列不存在
+	pending, err := inspector.ListPendingTasks("default")
+	if err != nil && !errors.Is(err, asynq.ErrQueueNotFound) {
+		t.Fatalf("list pending tasks: %v", err)
+	}
+	scheduled, err := inspector.ListScheduledTasks("default")
+	if err != nil && !errors.Is(err, asynq.ErrQueueNotFound) {
+		t.Fatalf("list scheduled tasks: %v", err)
+	}
+	for _, info := range append(pending, scheduled...) {
+		switch info.Type {
+		case task.SendForumTopicReplyTask:
+			var reply topicReply
+			if err := json.Unmarshal(info.Payload, &reply); err != nil {
+				t.Fatalf("parse reply task: %v", err)
+			}
+			replies = append(replies, reply)
+		case task.AnnounceProgressTask:
+			progress = append(progress, info)
+		}
+	}
+	return replies, progress
+}
+
+// runAnnounceProgress 执行项目的进度回帖任务
+func runAnnounceProgress(t *testing.T, projectID string) {
+	t.Helper()
+	payload, _ := json.Marshal(announceTaskPayload{ProjectID: projectID})
+	if err := HandleAnnounceProgress(context.Background(), asynq.NewTask(task.AnnounceProgressTask, payload)); err != nil {
+		t.Fatalf("announce progress: %v", err)
+	}
+}
+
+func TestCreateProjectAnnouncesToOwnTopic(t *testing.T) {
+	mr := setupItemStore(t)
+	ctx := context.Background()
+	if err := db.DB(ctx).AutoMigrate(&ProjectTag{}, &ProjectInvite{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	alice := &oauth.User{ID: 1, Username: "alice", Score: oauth.BaseUserScore}
+	bob := &oauth.User{ID: 2, Username: "bob", Score: oauth.BaseUserScore}
+	if err := db.DB(ctx).Create([]*oauth.User{alice, bob}).Error; err != nil {
+		t.Fatalf("create users: %v", err)
+	}
+	enableTopicAnnounce(t, 42, alice.ID)
+
+	now := time.Now()
+	body := map[string]interface{}{
+		"name":              "抽奖",
+		"distribution_type": DistributionTypeLottery,
+		"project_items":     []string{"PRIZE"},
+		"topic_id":          42,
+		"announce_to_topic": true,
+		"announce_progress": true,
+		"start_time":        now,
+		"end_time":          now.Add(time.Hour),
+	}
+
+	// 非话题作者不能借机器人向他人的话题回帖
+	if w := callHandler(CreateProject, http.MethodPost, nil, bob, nil, body); w.Code != http.StatusForbidden {
+		t.Fatalf("want 403 for another user's topic, got %d %s", w.Code, w.Body.String())
+	}
+	var count int64
+	db.DB(ctx).Model(&Project{}).Count(&count)
+	if replies, progress := topicTasks(t, mr); count != 0 || len(replies) != 0 || len(progress) != 0 {
+		t.Fatalf("want nothing created or announced, got projects=%d replies=%v progress=%d", count, replies, len(progress))
+	}
+
+	// 话题作者创建后持久化话题 ID,公布领取链接并在结束时间下发进度回帖
+	projectID := createdProjectID(t, callHandler(CreateProject, http.MethodPost, nil, alice, nil, body))
+	var p Project
+	if err := db.DB(ctx).Where("id = ?", projectID).First(&p).Error; err != nil {
+		t.Fatalf("load project: %v", err)
+	}
+	if p.TopicID != 42 || !p.AnnounceToTopic || !p.AnnounceProgress {
+		t.Fatalf("want topic settings persisted, got topic=%d announce=%v progress=%v", p.TopicID, p.AnnounceToTopic, p.AnnounceProgress)
+	}
+	replies, progress := topicTasks(t, mr)
+	if len(replies) != 1 || replies[0].TopicID != 42 || replies[0].Key != forum.AnnounceKey(projectID) {
+		t.Fatalf("want announce reply to topic 42, got %+v", replies)
+	}
+	if len(progress) != 1 || !progress[0].NextProcessAt.Equal(p.EndTime.Truncate(time.Second)) {
+		t.Fatalf("want progress announcement scheduled at end time, got %+v", progress)
+	}
+}
+
+func TestHandleAnnounceProgress(t *testing.T) {
+	mr := setupItemStore(t)
+	ctx := context.Background()
+	enableTopicAnnounce(t, 42, 1)
+	now := time.Now()
+
+	// 结束后回帖公布领取进度，作废的 item 不计入已领取与总数
+	ended := &Project{ID: "ended", DistributionType: DistributionTypeLottery, TopicID: 42, AnnounceProgress: true,
+		StartTime: now.Add(-2 * time.Hour), EndTime: now.Add(-time.Minute)}
+	items := createStockedProject(t, ended, "A", "B", "C")
+	receiver := uint64(2)
+	db.DB(ctx).Model(&items[0]).Update("receiver_id", receiver)
+	db.DB(ctx).Model(&items[2]).Updates(map[string]interface{}{"receiver_id": receiver, "revoked_at": now})
+	if claimed, total, err := ended.ClaimProgress(db.DB(ctx)); err != nil || claimed != 1 || total != 2 {
+		t.Fatalf("want progress 1/2 excluding revoked item, got %d/%d (%v)", claimed, total, err)
+	}
+	runAnnounceProgress(t, ended.ID)
+	replies, _ := topicTasks(t, mr)
+	if len(replies) != 1 || replies[0].Key != forum.ProgressKey(ended.ID) || replies[0].TopicID != 42 ||
+		replies[0].Data.Claimed != 1 || replies[0].Data.Total != 2 {
+		t.Fatalf("want progress reply 1/2, got %+v", replies)
+	}
+
+	// 结束时间被推迟时按新的时间重新下发，不提前回帖
+	extended := &Project{ID: "extended", DistributionType: DistributionTypeLottery, TopicID: 42, AnnounceProgress: true,
+		EndTime: now.Add(time.Hour)}
+	createStockedProject(t, extended, "D")
+	runAnnounceProgress(t, extended.ID)
+	replies, progress := topicTasks(t, mr)
+	if len(replies) != 1 {
+		t.Fatalf("want no reply before the new end time, got %+v", replies)
+	}
+	if len(progress) != 1 || !progress[0].NextProcessAt.Equal(extended.EndTime.Truncate(time.Second)) {
+		t.Fatalf("want progress announcement re-enqueued at new end time, got %+v", progress)
+	}
+
+	// 被隐藏的项目不再回帖
+	hidden := &Project{ID: "hidden", DistributionType: DistributionTypeLottery, TopicID: 42, AnnounceProgress: true,
+		Status: ProjectStatusHidden, StartTime: now.Add(-2 * time.Hour), EndTime: now.Add(-time.Minute)}
+	createStockedProject(t, hidden, "E")
+	runAnnounceProgress(t, hidden.ID)
+	if replies, progress := topicTasks(t, mr); len(replies) != 1 || len(progress) != 1 {
+		t.Fatalf("want hidden project skipped, got replies=%+v progress=%d", replies, len(progress))
+	}
+}
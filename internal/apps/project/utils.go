@@ -27,8 +27,11 @@ package project
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/linux-do/cdk/internal/apps/oauth"
 	"github.com/linux-do/cdk/internal/config"
 	"github.com/linux-do/cdk/internal/db"
@@ -52,6 +55,36 @@ func SetProjectToContext(c *gin.Context, project *Project) {
 	c.Set(ProjectObjKey, project)
 }
 
+// GetInviteFromContext 从Context中获取已校验的邀请码
+func GetInviteFromContext(c *gin.Context) (*ProjectInvite, bool) {
+	invite, exists := c.Get(ProjectInviteObjKey)
+	if !exists {
+		return nil, false
+	}
+	i, ok := invite.(*ProjectInvite)
+	return i, ok
+}
+
+// SetInviteToContext 将已校验的邀请码存储到Context中
+func SetInviteToContext(c *gin.Context, invite *ProjectInvite) {
+	c.Set(ProjectInviteObjKey, invite)
+}
+
+// GetReceiveRequestFromContext 解析领取请求体并缓存到Context,空请求体视为无附加参数
+func GetReceiveRequestFromContext(c *gin.Context) (*ReceiveProjectRequestBody, error) {
+	if value, exists := c.Get(ReceiveRequestObjKey); exists {
+		if req, ok := value.(*ReceiveProjectRequestBody); ok {
+			return req, nil
+		}
+	}
+	req := &ReceiveProjectRequestBody{}
+	if err := c.ShouldBindBodyWith(req, binding.JSON); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	c.Set(ReceiveRequestObjKey, req)
+	return req, nil
+}
+
 // ProjectWithTags 返回项目及其标签
 type ProjectWithTags struct {
 	Project
@@ -71,17 +104,17 @@ func ListProjectsWithTags(ctx context.Context, offset, limit int, tags []string,
 	getTotalCountSql := `SELECT COUNT(DISTINCT p.id) as total
 			FROM projects p
 			LEFT JOIN project_tags pt ON p.id = pt.project_id
-			WHERE p.end_time > ? AND p.is_completed = false AND p.status = ? AND p.minimum_trust_level <= ? AND p.risk_level >= ? AND p.hide_from_explore = false AND NOT EXISTS ( SELECT 1 FROM project_items pi WHERE pi.project_id = p.id AND pi.receiver_id = ?)`
+			WHERE p.end_time > ? AND p.is_completed = false AND p.status = ? AND p.minimum_trust_level <= ? AND p.risk_level >= ? AND p.hide_from_explore = false AND ( SELECT COUNT(*) FROM project_claims pc WHERE pc.project_id = p.id AND pc.round = p.round AND pc.user_id = ?) < ` + claimQuotaSQL
 
 	getProjectWithTagsSql := `SELECT
     			p.id,p.name,p.description,p.distribution_type,p.total_items,
-       			p.start_time,p.end_time,p.minimum_trust_level,p.allow_same_ip,p.risk_level,p.price,p.created_at,
+       			p.start_time,p.end_time,p.minimum_trust_level,p.allow_same_ip,p.risk_level,p.price,p.currency,p.created_at,
 				IF(COUNT(pt.tag) = 0, NULL, JSON_ARRAYAGG(pt.tag)) AS tags
 			FROM projects p
 			LEFT JOIN project_tags pt ON p.id = pt.project_id
-			WHERE p.end_time > ? AND p.is_completed = false AND p.status = ? AND p.minimum_trust_level <= ? AND p.risk_level >= ? AND p.hide_from_explore = false AND NOT EXISTS ( SELECT 1 FROM project_items pi WHERE pi.project_id = p.id AND pi.receiver_id = ?)`
+			WHERE p.end_time > ? AND p.is_completed = false AND p.status = ? AND p.minimum_trust_level <= ? AND p.risk_level >= ? AND p.hide_from_explore = false AND ( SELECT COUNT(*) FROM project_claims pc WHERE pc.project_id = p.id AND pc.round = p.round AND pc.user_id = ?) < ` + claimQuotaSQL
 
-	var parameters = []interface{}{now, ProjectStatusNormal, currentUser.TrustLevel, currentUser.RiskLevel(), currentUser.ID}
+	var parameters = []interface{}{now, ProjectStatusNormal, currentUser.TrustLevel, currentUser.RiskLevel(), currentUser.ID, quotaJSONPath(currentUser.TrustLevel)}
 	if len(tags) > 0 {
 		getTotalCountSql += ` AND pt.tag IN (?)`
 		getProjectWithTagsSql += ` AND pt.tag IN (?)`
@@ -126,7 +159,7 @@ func ListMyProjectsWithTags(ctx context.Context, creatorID uint64, offset, limit
 
 	getMyProjectWithTagsSql := `SELECT
 				p.id,p.name,p.description,p.distribution_type,p.total_items,
-				p.start_time,p.end_time,p.minimum_trust_level,p.allow_same_ip,p.risk_level,p.hide_from_explore,p.price,p.created_at,
+				p.start_time,p.end_time,p.minimum_trust_level,p.allow_same_ip,p.risk_level,p.hide_from_explore,p.price,p.currency,p.created_at,
 				IF(COUNT(pt.tag) = 0, NULL, JSON_ARRAYAGG(pt.tag)) AS tags
 			FROM projects p
 			LEFT JOIN project_tags pt ON p.id = pt.project_id
@@ -172,21 +205,34 @@ func ListMyProjectsWithTags(ctx context.Context, creatorID uint64, offset, limit
 // maxProjectPrice 付费项目的最大单价上限
 var maxProjectPrice = decimal.RequireFromString("99999999.99")
 
-// validateProjectPrice 校验 Price 字段合法性。
-// 规则:
-//   - Price 必须非负,最多 2 位小数,不超过上限
-//   - Price > 0 仅允许 DistributionTypeOneForEach
-//   - Price > 0 时必须确认全局支付功能已启用且创建者已配置 clientID/clientSecret
-func validateProjectPrice(ctx context.Context, price decimal.Decimal, dt DistributionType, creatorID uint64) error {
+// checkPriceAmount 校验金额非负，小数位数不超过币种精度且不超过上限
+func checkPriceAmount(price decimal.Decimal, currency Currency) error {
 	if price.IsNegative() {
 		return errors.New(InvalidPrice)
 	}
-	if price.Exponent() < -2 {
-		return errors.New(InvalidPriceDecimals)
+	if !price.Equal(currency.Round(price)) {
+		return fmt.Errorf(InvalidPriceDecimals, currency.OrDefault(), currency.Precision())
 	}
 	if price.GreaterThan(maxProjectPrice) {
 		return errors.New(PriceTooLarge)
 	}
+	return nil
+}
+
+// validateProjectPrice 校验 Price 字段合法性。
+// 规则:
+//   - Currency 必须为支持的币种
+//   - Price 必须非负,小数位数不超过币种精度,不超过上限
+//   - Price > 0 仅允许 DistributionTypeOneForEach
+//   - Price > 0 时必须确认全局支付功能已启用且创建者已配置 clientID/clientSecret 并通过渠道校验
+//   - Price > 0 时项目币种需与创建者支付配置的币种一致
+func validateProjectPrice(ctx context.Context, price decimal.Decimal, currency Currency, dt DistributionType, creatorID uint64) error {
+	if !currency.IsValid() {
+		return errors.New(CurrencyInvalid)
+	}
+	if err := checkPriceAmount(price, currency); err != nil {
+		return err
+	}
 	if price.IsZero() {
 		return nil
 	}
@@ -196,12 +242,21 @@ func validateProjectPrice(ctx context.Context, price decimal.Decimal, dt Distrib
 	if !config.Config.Payment.Enabled {
 		return errors.New(PaymentDisabled)
 	}
-	var cnt int64
-	if err := db.DB(ctx).Table("user_payment_configs").Where("user_id = ?", creatorID).Count(&cnt).Error; err != nil {
+	var creatorConfigs []struct {
+		Currency   Currency
+		VerifiedAt *time.Time
+	}
+	if err := db.DB(ctx).Table("user_payment_configs").Select("currency, verified_at").Where("user_id = ?", creatorID).Limit(1).Scan(&creatorConfigs).Error; err != nil {
 		return err
 	}
-	if cnt == 0 {
+	if len(creatorConfigs) == 0 {
 		return errors.New(CreatorNotConfigured)
 	}
+	if creatorConfigs[0].VerifiedAt == nil {
+		return errors.New(CreatorNotVerified)
+	}
+	if creatorConfigs[0].Currency.OrDefault() != currency.OrDefault() {
+		return fmt.Errorf(CurrencyMismatch, creatorConfigs[0].Currency.OrDefault())
+	}
 	return nil
 }
@@ -0,0 +1,165 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/db"
+)
+
+type GetProjectWaitlistResponseData struct {
+	Joined   bool             `json:"joined"`
+	Position int64            `json:"position"`
+	Entry    *ProjectWaitlist `json:"entry"`
+}
+
+type GetProjectWaitlistResponse struct {
+	ErrorMsg string                         `json:"error_msg"`
+	Data     GetProjectWaitlistResponseData `json:"data"`
+}
+
+// GetProjectWaitlist 获取当前用户的候补状态;获得预占后 entry.status 为 1,付费项目可通过待支付订单完成支付
+// @Tags project
+// @Produce json
+// @Param id path string true "项目ID"
+// @Success 200 {object} GetProjectWaitlistResponse
+// @Router /api/v1/projects/{id}/waitlist [get]
+func GetProjectWaitlist(c *gin.Context) {
+	// load project
+	project := &Project{}
+	if err := project.Exact(db.DB(c.Request.Context()), c.Param("id"), true); err != nil {
+		c.JSON(http.StatusNotFound, GetProjectWaitlistResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// load entry
+	entry, err := project.GetActiveWaitlist(db.DB(c.Request.Context()), oauth.GetUserIDFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, GetProjectWaitlistResponse{ErrorMsg: err.Error()})
+		return
+	}
+	if entry == nil {
+		c.JSON(http.StatusOK, GetProjectWaitlistResponse{})
+		return
+	}
+
+	data := GetProjectWaitlistResponseData{Joined: true, Entry: entry}
+	if entry.Status == WaitlistStatusWaiting {
+		if data.Position, err = project.WaitlistPosition(db.DB(c.Request.Context()), entry); err != nil {
+			c.JSON(http.StatusInternalServerError, GetProjectWaitlistResponse{ErrorMsg: err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, GetProjectWaitlistResponse{Data: data})
+}
+
+// JoinProjectWaitlist 无库存时加入候补队列
+// @Tags project
+// @Produce json
+// @Param id path string true "项目ID"
+// @Success 200 {object} ProjectResponse{data=ProjectWaitlist}
+// @Router /api/v1/projects/{id}/waitlist [post]
+func JoinProjectWaitlist(c *gin.Context) {
+	// load user
+	user, _ := oauth.GetUserFromContext(c)
+
+	// load project
+	project := &Project{}
+	if err := project.Exact(db.DB(c.Request.Context()), c.Param("id"), true); err != nil {
+		c.JSON(http.StatusNotFound, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// do join
+	entry, err := project.JoinWaitlist(c.Request.Context(), user, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ProjectResponse{Data: entry})
+}
+
+// LeaveProjectWaitlist 退出候补队列，已保留的免费库存会被释放
+// @Tags project
+// @Produce json
+// @Param id path string true "项目ID"
+// @Success 200 {object} ProjectResponse
+// @Router /api/v1/projects/{id}/waitlist [delete]
+func LeaveProjectWaitlist(c *gin.Context) {
+	// load project
+	project := &Project{}
+	if err := project.Exact(db.DB(c.Request.Context()), c.Param("id"), true); err != nil {
+		c.JSON(http.StatusNotFound, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// do leave
+	if err := project.LeaveWaitlist(c.Request.Context(), oauth.GetUserIDFromContext(c)); err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ProjectResponse{})
+}
+
+type ClaimProjectWaitlistResponseData struct {
+	ItemContent string `json:"itemContent"`
+}
+
+// ClaimProjectWaitlist 领取免费项目中为当前用户保留的库存
+// @Tags project
+// @Produce json
+// @Param id path string true "项目ID"
+// @Success 200 {object} ProjectResponse{data=ClaimProjectWaitlistResponseData}
+// @Router /api/v1/projects/{id}/waitlist/claim [post]
+func ClaimProjectWaitlist(c *gin.Context) {
+	// load user
+	user, _ := oauth.GetUserFromContext(c)
+
+	// load project
+	project := &Project{}
+	if err := project.Exact(db.DB(c.Request.Context()), c.Param("id"), true); err != nil {
+		c.JSON(http.StatusNotFound, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	// do claim
+	item, err := project.ClaimWaitlistHold(c.Request.Context(), user, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+	content, err := item.PlainContent()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ProjectResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ProjectResponse{Data: ClaimProjectWaitlistResponseData{ItemContent: content}})
+}
@@ -0,0 +1,415 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/cdk/internal/apps/notification"
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/config"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/logger"
+	"github.com/linux-do/cdk/internal/task"
+	"github.com/linux-do/cdk/internal/task/schedule"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WaitlistStatus int8
+
+const (
+	WaitlistStatusWaiting WaitlistStatus = iota
+	WaitlistStatusOffered
+	WaitlistStatusFulfilled
+	WaitlistStatusExpired
+	WaitlistStatusCancelled
+)
+
+// ProjectWaitlist 无库存时的 FIFO 候补记录。
+// 库存归还后按 ID 顺序为候补用户预占 item:免费项目保留 HoldExpireAt 前可领取，
+// 付费项目则直接创建待支付订单(OutTradeNo),过期由订单清理任务释放。
+//
+// 联合索引：
+//   - idx_project_waitlist_status (project_id, status)：按项目查询候补队列
+type ProjectWaitlist struct {
+	ID           uint64         `json:"id" gorm:"primaryKey,autoIncrement"`
+	ProjectID    string         `json:"project_id" gorm:"size:64;index:idx_project_waitlist_status,priority:1"`
+	UserID       uint64         `json:"user_id" gorm:"index"`
+	Status       WaitlistStatus `json:"status" gorm:"default:0;index:idx_project_waitlist_status,priority:2"`
+	ItemID       uint64         `json:"item_id"`
+	OutTradeNo   string         `json:"out_trade_no" gorm:"size:64;index"`
+	HoldExpireAt *time.Time     `json:"hold_expire_at"`
+	ClientIP     string         `json:"-" gorm:"size:64"`
+	CreatedAt    time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// IsWaitlistable 是否支持候补:仅公开的一码一用项目
+func (p *Project) IsWaitlistable() bool {
+	return p.DistributionType == DistributionTypeOneForEach
+}
+
+// WaitlistHoldDuration 候补预占的保留时长
+func WaitlistHoldDuration() time.Duration {
+	minutes := config.Config.ProjectApp.WaitlistHoldMinutes
+	if minutes <= 0 {
+		minutes = 10
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// GetActiveWaitlist 返回用户在项目下等待中或已获得预占的候补记录，不存在返回 nil
+func (p *Project) GetActiveWaitlist(tx *gorm.DB, userID uint64) (*ProjectWaitlist, error) {
+	entry := &ProjectWaitlist{}
+	err := tx.Where("project_id = ? AND user_id = ? AND status IN ?", p.ID, userID,
+		[]WaitlistStatus{WaitlistStatusWaiting, WaitlistStatusOffered}).
+		Order("id DESC").
+		First(entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// WaitlistPosition 返回等待中的候补记录在队列中的位置(从 1 开始)
+func (p *Project) WaitlistPosition(tx *gorm.DB, entry *ProjectWaitlist) (int64, error) {
+	var ahead int64
+	err := tx.Model(&ProjectWaitlist{}).
+		Where("project_id = ? AND status = ? AND id < ?", p.ID, WaitlistStatusWaiting, entry.ID).
+		Count(&ahead).Error
+	return ahead + 1, err
+}
+
+// JoinWaitlist 加入候补队列:仅在项目进行中、无库存且用户未达到领取上限时允许
+func (p *Project) JoinWaitlist(ctx context.Context, user *oauth.User, clientIP string) (*ProjectWaitlist, error) {
+	if !p.IsWaitlistable() {
+		return nil, errors.New(WaitlistNotSupported)
+	}
+	now := time.Now()
+	if p.EndTime.Before(now) {
+		return nil, errors.New(TimeTooLate)
+	}
+	if err := p.ValidateRequirement(user); err != nil {
+		return nil, err
+	}
+	if err := p.CheckClaimQuota(ctx, user); err != nil {
+		return nil, err
+	}
+	if hasStock, err := p.HasStock(ctx); err != nil {
+		return nil, err
+	} else if hasStock {
+		return nil, errors.New(WaitlistHasStock)
+	}
+
+	entry := &ProjectWaitlist{ProjectID: p.ID, UserID: user.ID, ClientIP: clientIP}
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁定用户行，串行化同一用户的重复加入
+		var lockUser oauth.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("id = ?", user.ID).
+			First(&lockUser).Error; err != nil {
+			return err
+		}
+		if active, err := p.GetActiveWaitlist(tx, user.ID); err != nil {
+			return err
+		} else if active != nil {
+			return errors.New(WaitlistAlreadyJoined)
+		}
+		return tx.Create(entry).Error
+	}); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// LeaveWaitlist 退出候补队列;已获得免费预占的记录会归还库存
+func (p *Project) LeaveWaitlist(ctx context.Context, userID uint64) error {
+	return db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		entry, err := p.GetActiveWaitlist(tx, userID)
+		if err != nil {
+			return err
+		}
+		if entry == nil {
+			return errors.New(WaitlistNotJoined)
+		}
+		if entry.OutTradeNo != "" {
+			// 付费预占以待支付订单存在，由订单过期流程释放
+			return errors.New(WaitlistOrderPending)
+		}
+		result := tx.Model(&ProjectWaitlist{}).
+			Where("id = ? AND status = ?", entry.ID, entry.Status).
+			Update("status", WaitlistStatusCancelled)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 || entry.Status != WaitlistStatusOffered {
+			return nil
+		}
+		return p.releaseWaitlistHold(ctx, tx, entry)
+	})
+}
+
+// releaseWaitlistHold 归还免费预占的 item 并触发下一位候补
+func (p *Project) releaseWaitlistHold(ctx context.Context, tx *gorm.DB, entry *ProjectWaitlist) error {
+	return p.ReturnItem(ctx, tx, entry.ItemID)
+}
+
+// ReturnedItemsKey 存在候补时归还的 item 暂存队列，不对外开放领取，由候补分配任务优先取用
+func (p *Project) ReturnedItemsKey() string {
+	return fmt.Sprintf("project:%s:items:returned", p.ID)
+}
+
+// ReturnItem 在调用方事务中归还 item:项目存在等待中的候补时暂存至归还队列，保证候补优先于公开领取;
+// 否则放回公开库存并重置项目完成状态。两种情况下候补项目都会触发一次分配任务。
+func (p *Project) ReturnItem(ctx context.Context, tx *gorm.DB, itemID uint64) error {
+	if !p.IsWaitlistable() {
+		if err := db.Redis.RPush(ctx, p.ItemsKey(), itemID).Err(); err != nil {
+			return err
+		}
+		return p.ResetCompletedStatusIfHasStock(ctx, tx)
+	}
+
+	var waiting int64
+	if err := tx.Model(&ProjectWaitlist{}).
+		Where("project_id = ? AND status = ?", p.ID, WaitlistStatusWaiting).
+		Limit(1).
+		Count(&waiting).Error; err != nil {
+		return err
+	}
+	if waiting > 0 {
+		if err := db.Redis.RPush(ctx, p.ReturnedItemsKey(), itemID).Err(); err != nil {
+			return err
+		}
+	} else {
+		if err := db.Redis.RPush(ctx, p.ItemsKey(), itemID).Err(); err != nil {
+			return err
+		}
+		if err := p.ResetCompletedStatusIfHasStock(ctx, tx); err != nil {
+			return err
+		}
+	}
+	EnqueueWaitlistOffer(ctx, p.ID)
+	return nil
+}
+
+// PrepareWaitlistOffer 为候补分配预占 item:优先取归还队列，再取公开库存;fromReturned 供分配失败时归还原处
+func (p *Project) PrepareWaitlistOffer(ctx context.Context) (itemID uint64, fromReturned bool, err error) {
+	val, err := db.Redis.LPop(ctx, p.ReturnedItemsKey()).Result()
+	if errors.Is(err, redis.Nil) {
+		itemID, err = p.PrepareReceive(ctx, "")
+		return itemID, false, err
+	} else if err != nil {
+		return 0, false, err
+	}
+	itemID, err = strconv.ParseUint(val, 10, 64)
+	return itemID, true, err
+}
+
+// ReleaseReturnedItems 将归还队列中剩余的 item 移入公开库存，在候补分配结束或无法继续时调用
+func (p *Project) ReleaseReturnedItems(ctx context.Context) error {
+	moved := 0
+	for {
+		if err := db.Redis.LMove(ctx, p.ReturnedItemsKey(), p.ItemsKey(), "LEFT", "RIGHT").Err(); errors.Is(err, redis.Nil) {
+			break
+		} else if err != nil {
+			return err
+		}
+		moved++
+	}
+	if moved <= 0 {
+		return nil
+	}
+	return p.ResetCompletedStatusIfHasStock(ctx, db.DB(ctx))
+}
+
+// OfferWaitlistHold 在调用方事务中把已预占的 item 分配给候补记录;outTradeNo 非空表示以待支付订单形式预占。
+func (p *Project) OfferWaitlistHold(tx *gorm.DB, entry *ProjectWaitlist, itemID uint64, outTradeNo string, holdExpireAt time.Time) error {
+	result := tx.Model(&ProjectWaitlist{}).
+		Where("id = ? AND status = ?", entry.ID, WaitlistStatusWaiting).
+		Updates(map[string]interface{}{
+			"status":         WaitlistStatusOffered,
+			"item_id":        itemID,
+			"out_trade_no":   outTradeNo,
+			"hold_expire_at": &holdExpireAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(WaitlistNotJoined)
+	}
+	entry.Status = WaitlistStatusOffered
+	entry.ItemID = itemID
+	entry.OutTradeNo = outTradeNo
+	entry.HoldExpireAt = &holdExpireAt
+	return notification.Send(tx, notification.WaitlistOffered(p.ID, p.Name, outTradeNo != "", holdExpireAt), entry.UserID)
+}
+
+// NextWaitlist 锁定并返回队首的等待中候补记录，队列为空返回 nil
+func (p *Project) NextWaitlist(tx *gorm.DB) (*ProjectWaitlist, error) {
+	entry := &ProjectWaitlist{}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("project_id = ? AND status = ?", p.ID, WaitlistStatusWaiting).
+		Order("id ASC").
+		First(entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// CancelWaitlist 取消不再具备领取资格的候补记录
+func CancelWaitlist(tx *gorm.DB, entryID uint64) error {
+	return tx.Model(&ProjectWaitlist{}).
+		Where("id = ? AND status = ?", entryID, WaitlistStatusWaiting).
+		Update("status", WaitlistStatusCancelled).Error
+}
+
+// ExpireWaitlistOrder 付费预占的待支付订单过期时同步置候补记录为过期
+func ExpireWaitlistOrder(tx *gorm.DB, outTradeNo string) error {
+	return tx.Model(&ProjectWaitlist{}).
+		Where("out_trade_no = ? AND status = ?", outTradeNo, WaitlistStatusOffered).
+		Update("status", WaitlistStatusExpired).Error
+}
+
+// fulfillWaitlist 领取成功时完成对应的候补预占
+func (p *Project) fulfillWaitlist(tx *gorm.DB, userID uint64, itemID uint64) error {
+	return tx.Model(&ProjectWaitlist{}).
+		Where("project_id = ? AND user_id = ? AND status = ? AND item_id = ?", p.ID, userID, WaitlistStatusOffered, itemID).
+		Update("status", WaitlistStatusFulfilled).Error
+}
+
+// ClaimWaitlistHold 领取免费项目中为当前用户保留的 item
+func (p *Project) ClaimWaitlistHold(ctx context.Context, user *oauth.User, clientIP string) (*ProjectItem, error) {
+	if time.Now().After(p.EndTime) {
+		return nil, errors.New(TimeTooLate)
+	}
+	if sameIPReceived, err := p.CheckSameIPReceived(ctx, clientIP, user.ID); err != nil {
+		return nil, err
+	} else if sameIPReceived {
+		return nil, errors.New(SameIPReceived)
+	}
+
+	item := &ProjectItem{}
+	if err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		entry, err := p.GetActiveWaitlist(tx, user.ID)
+		if err != nil {
+			return err
+		}
+		if entry == nil || entry.Status != WaitlistStatusOffered || entry.OutTradeNo != "" ||
+			entry.HoldExpireAt == nil || entry.HoldExpireAt.Before(time.Now()) {
+			return errors.New(WaitlistNoHold)
+		}
+		if err := item.Exact(tx, entry.ItemID); err != nil {
+			return err
+		}
+		return p.FulfillForReceiver(ctx, tx, item, user.ID, clientIP)
+	}); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// waitlistTaskPayload 候补任务参数
+type waitlistTaskPayload struct {
+	ProjectID string `json:"project_id"`
+	EntryID   uint64 `json:"entry_id,omitempty"`
+}
+
+// EnqueueWaitlistOffer 下发候补分配任务;库存归还的主流程不因下发失败而回滚，仅记录日志
+func EnqueueWaitlistOffer(ctx context.Context, projectID string) {
+	payload, _ := json.Marshal(waitlistTaskPayload{ProjectID: projectID})
+	if _, err := schedule.AsynqClient.EnqueueContext(
+		ctx,
+		asynq.NewTask(task.OfferProjectWaitlistTask, payload),
+		asynq.MaxRetry(3),
+	); err != nil {
+		logger.ErrorF(ctx, "下发项目[%s]候补分配任务失败: %v", projectID, err)
+	}
+}
+
+// EnqueueWaitlistHoldExpiry 下发免费预占到期任务
+func EnqueueWaitlistHoldExpiry(ctx context.Context, entry *ProjectWaitlist) error {
+	payload, _ := json.Marshal(waitlistTaskPayload{ProjectID: entry.ProjectID, EntryID: entry.ID})
+	_, err := schedule.AsynqClient.EnqueueContext(
+		ctx,
+		asynq.NewTask(task.ExpireWaitlistHoldTask, payload),
+		asynq.ProcessAt(*entry.HoldExpireAt),
+		asynq.MaxRetry(5),
+	)
+	return err
+}
+
+// HandleExpireWaitlistHold 处理免费预占到期:未领取则归还 item 并分配给下一位候补
+func HandleExpireWaitlistHold(ctx context.Context, t *asynq.Task) error {
+	var payload waitlistTaskPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("解析任务参数失败: %w", err)
+	}
+
+	var p Project
+	if err := db.DB(ctx).Where("id = ?", payload.ProjectID).First(&p).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		entry := &ProjectWaitlist{}
+		if err := tx.Where("id = ?", payload.EntryID).First(entry).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		result := tx.Model(&ProjectWaitlist{}).
+			Where("id = ? AND status = ? AND out_trade_no = ''", entry.ID, WaitlistStatusOffered).
+			Update("status", WaitlistStatusExpired)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		logger.InfoF(ctx, "项目[%s]候补[%d]预占到期，归还 item %d", p.ID, entry.ID, entry.ItemID)
+		return p.releaseWaitlistHold(ctx, tx, entry)
+	})
+}
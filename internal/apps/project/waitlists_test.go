@@ -0,0 +1,102 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package project
+
+import (
+	"context"
+	"testing"
+
+	"github.com/linux-do/cdk/internal/db"
+)
+
+func TestReturnItemPrefersWaitlist(t *testing.T) {
+	setupItemStore(t)
+	ctx := context.Background()
+
+	p := &Project{DistributionType: DistributionTypeOneForEach, IsCompleted: true}
+	items := createStockedProject(t, p, "KEY-1", "KEY-2")
+	db.Redis.Del(ctx, p.ItemsKey())
+
+	// 无候补时直接放回公开库存并重置完成状态
+	if err := p.ReturnItem(ctx, db.DB(ctx), items[0].ID); err != nil {
+		t.Fatalf("return: %v", err)
+	}
+	if stock := stockIDs(t, p); len(stock) != 1 || !stock[items[0].ID] {
+		t.Fatalf("want item back in public stock, got %v", stock)
+	}
+	var stored Project
+	db.DB(ctx).First(&stored, "id = ?", p.ID)
+	if stored.IsCompleted {
+		t.Fatal("want project reopened after stock returned")
+	}
+
+	// 有等待中的候补时暂存，不进入公开库存
+	if err := db.DB(ctx).Create(&ProjectWaitlist{ProjectID: p.ID, UserID: 1}).Error; err != nil {
+		t.Fatalf("create waitlist: %v", err)
+	}
+	db.Redis.Del(ctx, p.ItemsKey())
+	if err := p.ReturnItem(ctx, db.DB(ctx), items[1].ID); err != nil {
+		t.Fatalf("return: %v", err)
+	}
+	if stock, _ := p.Stock(ctx); stock != 0 {
+		t.Fatalf("returned item must not be publicly claimable while waitlist is waiting, stock=%d", stock)
+	}
+
+	// 候补分配优先取暂存的归还库存
+	db.Redis.RPush(ctx, p.ItemsKey(), items[0].ID)
+	itemID, fromReturned, err := p.PrepareWaitlistOffer(ctx)
+	if err != nil || itemID != items[1].ID || !fromReturned {
+		t.Fatalf("want returned item %d first, got %d (returned=%v, err=%v)", items[1].ID, itemID, fromReturned, err)
+	}
+	itemID, fromReturned, err = p.PrepareWaitlistOffer(ctx)
+	if err != nil || itemID != items[0].ID || fromReturned {
+		t.Fatalf("want public item %d next, got %d (returned=%v, err=%v)", items[0].ID, itemID, fromReturned, err)
+	}
+}
+
+func TestReleaseReturnedItems(t *testing.T) {
+	setupItemStore(t)
+	ctx := context.Background()
+
+	p := &Project{DistributionType: DistributionTypeOneForEach, IsCompleted: true}
+	items := createStockedProject(t, p, "KEY-1", "KEY-2")
+	db.Redis.Del(ctx, p.ItemsKey())
+	db.Redis.RPush(ctx, p.ReturnedItemsKey(), items[0].ID, items[1].ID)
+
+	if err := p.ReleaseReturnedItems(ctx); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if n, _ := db.Redis.LLen(ctx, p.ReturnedItemsKey()).Result(); n != 0 {
+		t.Fatalf("want returned queue drained, got %d", n)
+	}
+	if stock := stockIDs(t, p); len(stock) != 2 {
+		t.Fatalf("want both items in public stock, got %v", stock)
+	}
+	var stored Project
+	db.DB(ctx).First(&stored, "id = ?", p.ID)
+	if stored.IsCompleted {
+		t.Fatal("want project reopened after released stock")
+	}
+}
@@ -0,0 +1,56 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package webhook
+
+import "time"
+
+const (
+	// maxWebhooksPerUser 每个用户的订阅数量上限
+	maxWebhooksPerUser = 10
+	// deliveryMaxRetry 单次投递的最大重试次数，耗尽后进入死信
+	deliveryMaxRetry = 8
+	// deliveryTimeout 单次 HTTP 投递超时
+	deliveryTimeout = 10 * time.Second
+	// deliveryDispatchDelay 投递任务的延迟执行时间，尽量等待事件所在事务提交
+	deliveryDispatchDelay = 3 * time.Second
+	// deliveryMissingRetry 投递记录不存在时的重试次数，耗尽后视为事件所在事务已回滚
+	deliveryMissingRetry = 3
+	// sweepPendingAfter 待投递记录超过该时长未被处理时视为任务丢失，由补偿任务重新下发
+	sweepPendingAfter = 10 * time.Minute
+	// sweepBatchSize 补偿任务单次扫描的记录数
+	sweepBatchSize = 200
+	// retryBaseDelay / retryMaxDelay 指数退避的基准与上限
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = 6 * time.Hour
+	// responseErrorLimit 记录的响应体长度上限
+	responseErrorLimit = 200
+)
+
+const (
+	SignatureHeader = "X-CDK-Signature"
+	TimestampHeader = "X-CDK-Timestamp"
+	EventHeader     = "X-CDK-Event"
+	DeliveryHeader  = "X-CDK-Delivery"
+)
@@ -0,0 +1,36 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package webhook
+
+const (
+	WebhookNotFound      = "Webhook 不存在"
+	DeliveryNotFound     = "投递记录不存在"
+	InvalidWebhookURL    = "Webhook 地址需为 http 或 https 的完整 URL"
+	InvalidWebhookEvents = "订阅事件无效"
+	TooManyWebhooks      = "Webhook 数量已达上限"
+	DeliveryNotRetryable = "仅失败或已进入死信的投递可以重新投递"
+	WebhookInactive      = "Webhook 已停用"
+	PrivateTargetDenied  = "Webhook 地址不能指向内网或本机"
+)
@@ -0,0 +1,164 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package webhook
+
+import (
+	"time"
+
+	"github.com/linux-do/cdk/internal/utils"
+)
+
+// Event 可订阅的事件类型
+type Event string
+
+const (
+	EventItemReceived     Event = "item.received"
+	EventProjectCompleted Event = "project.completed"
+	EventOrderPaid        Event = "order.paid"
+	EventOrderRefunded    Event = "order.refunded"
+	EventProjectReported  Event = "project.reported"
+)
+
+// DeliveryStatus 投递状态
+//
+//	PENDING(0) -> SUCCEEDED(1)
+//	           -> RETRYING(2) -> SUCCEEDED(1) / DEAD(3)   // 重试耗尽进入死信，可手动重新投递
+type DeliveryStatus int8
+
+const (
+	DeliveryStatusPending   DeliveryStatus = 0
+	DeliveryStatusSucceeded DeliveryStatus = 1
+	DeliveryStatusRetrying  DeliveryStatus = 2
+	DeliveryStatusDead      DeliveryStatus = 3
+)
+
+// Webhook 用户的事件订阅,Secret 用于对投递内容做 HMAC-SHA256 签名，仅在创建时返回
+type Webhook struct {
+	ID        uint64            `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint64            `json:"user_id" gorm:"index;not null"`
+	URL       string            `json:"url" gorm:"size:512;not null"`
+	Secret    string            `json:"-" gorm:"size:64;not null"`
+	Events    utils.StringArray `json:"events" gorm:"type:json"`
+	IsActive  bool              `json:"is_active" gorm:"default:true;not null"`
+	CreatedAt time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// WebhookDelivery 单次事件投递记录,Payload 为签名时使用的原始请求体,EventID 在重试与重新投递时保持不变。
+//
+// 联合索引：
+//   - idx_webhook_created (webhook_id, id)：按订阅分页查询投递日志
+type WebhookDelivery struct {
+	ID             uint64         `json:"id" gorm:"primaryKey;autoIncrement;index:idx_webhook_created,priority:2"`
+	WebhookID      uint64         `json:"webhook_id" gorm:"not null;index:idx_webhook_created,priority:1"`
+	EventID        string         `json:"event_id" gorm:"size:64;index;not null"`
+	Event          Event          `json:"event" gorm:"size:32;not null"`
+	Payload        string         `json:"payload" gorm:"type:text"`
+	Status         DeliveryStatus `json:"status" gorm:"default:0;index"`
+	Attempts       int            `json:"attempts" gorm:"default:0;not null"`
+	ResponseStatus int            `json:"response_status"`
+	LastError      string         `json:"last_error" gorm:"size:255"`
+	DeliveredAt    *time.Time     `json:"delivered_at"`
+	CreatedAt      time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 自定义表名
+func (Webhook) TableName() string { return "webhooks" }
+
+// TableName 自定义表名
+func (WebhookDelivery) TableName() string { return "webhook_deliveries" }
+
+// Subscribes 是否订阅了指定事件
+func (w *Webhook) Subscribes(event Event) bool {
+	for _, e := range w.Events {
+		if Event(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidEvent 是否为支持订阅的事件
+func IsValidEvent(event Event) bool {
+	switch event {
+	case EventItemReceived, EventProjectCompleted, EventOrderPaid, EventOrderRefunded, EventProjectReported:
+		return true
+	}
+	return false
+}
+
+// ItemReceivedData item.received 事件内容，不包含 item 明文
+type ItemReceivedData struct {
+	ProjectID        string    `json:"project_id"`
+	ProjectName      string    `json:"project_name"`
+	ItemID           uint64    `json:"item_id"`
+	ReceiverID       uint64    `json:"receiver_id"`
+	ReceiverUsername string    `json:"receiver_username"`
+	ReceivedAt       time.Time `json:"received_at"`
+}
+
+// ProjectCompletedData project.completed 事件内容
+type ProjectCompletedData struct {
+	ProjectID   string    `json:"project_id"`
+	ProjectName string    `json:"project_name"`
+	TotalItems  int64     `json:"total_items"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// OrderData order.paid / order.refunded 事件内容,Amount 按订单币种精度格式化
+type OrderData struct {
+	OutTradeNo string     `json:"out_trade_no"`
+	ProjectID  string     `json:"project_id"`
+	PayerID    uint64     `json:"payer_id"`
+	Amount     string     `json:"amount"`
+	Currency   string     `json:"currency"`
+	PaidAt     *time.Time `json:"paid_at"`
+	RefundedAt *time.Time `json:"refunded_at"`
+}
+
+// ProjectReportedData project.reported 事件内容,Source 为 report(用户举报) 或 review(管理员审核)
+type ProjectReportedData struct {
+	ProjectID   string `json:"project_id"`
+	ProjectName string `json:"project_name"`
+	Source      string `json:"source"`
+	Status      uint8  `json:"status"`
+	ReportCount uint8  `json:"report_count"`
+	Reason      string `json:"reason"`
+}
+
+// 事件来源
+const (
+	ReportSourceReport = "report"
+	ReportSourceReview = "review"
+)
+
+// envelope 投递请求体
+type envelope struct {
+	ID        string      `json:"id"`
+	Event     Event       `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
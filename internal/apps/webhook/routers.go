@@ -0,0 +1,278 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linux-do/cdk/internal/apps/oauth"
+	"github.com/linux-do/cdk/internal/db"
+	"gorm.io/gorm"
+)
+
+type WebhookResponse struct {
+	ErrorMsg string      `json:"error_msg"`
+	Data     interface{} `json:"data"`
+}
+
+// webhookErrStatus 业务校验错误返回 400,其余为 500
+func webhookErrStatus(err error) int {
+	switch err.Error() {
+	case InvalidWebhookURL, InvalidWebhookEvents, TooManyWebhooks, PrivateTargetDenied, DeliveryNotRetryable:
+		return http.StatusBadRequest
+	case WebhookNotFound, DeliveryNotFound:
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// loadWebhookFromParam 按路径参数加载当前用户的订阅
+func loadWebhookFromParam(c *gin.Context) (*Webhook, bool) {
+	webhookID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, WebhookResponse{ErrorMsg: err.Error()})
+		return nil, false
+	}
+	w, err := LoadUserWebhook(c.Request.Context(), oauth.GetUserIDFromContext(c), webhookID)
+	if err != nil {
+		c.JSON(webhookErrStatus(err), WebhookResponse{ErrorMsg: err.Error()})
+		return nil, false
+	}
+	return w, true
+}
+
+type ListWebhooksResponse struct {
+	ErrorMsg string    `json:"error_msg"`
+	Data     []Webhook `json:"data"`
+}
+
+// ListWebhooks 获取当前用户的 Webhook 订阅
+// @Tags webhook
+// @Produce json
+// @Success 200 {object} ListWebhooksResponse
+// @Router /api/v1/webhooks [get]
+func ListWebhooks(c *gin.Context) {
+	var webhooks []Webhook
+	if err := db.DB(c.Request.Context()).
+		Where("user_id = ?", oauth.GetUserIDFromContext(c)).
+		Order("id DESC").
+		Find(&webhooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ListWebhooksResponse{ErrorMsg: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ListWebhooksResponse{Data: webhooks})
+}
+
+type CreateWebhookRequestBody struct {
+	URL    string  `json:"url" binding:"required,max=512"`
+	Events []Event `json:"events" binding:"required,min=1"`
+}
+
+type CreateWebhookResponseData struct {
+	Webhook `json:",inline"`
+	Secret  string `json:"secret"`
+}
+
+type CreateWebhookResponse struct {
+	ErrorMsg string                    `json:"error_msg"`
+	Data     CreateWebhookResponseData `json:"data"`
+}
+
+// CreateWebhook 创建 Webhook 订阅，签名密钥仅在本次响应中返回
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param webhook body CreateWebhookRequestBody true "订阅信息"
+// @Success 200 {object} CreateWebhookResponse
+// @Router /api/v1/webhooks [post]
+func CreateWebhook(c *gin.Context) {
+	var req CreateWebhookRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreateWebhookResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	w, err := CreateUserWebhook(c.Request.Context(), oauth.GetUserIDFromContext(c), req.URL, req.Events)
+	if err != nil {
+		c.JSON(webhookErrStatus(err), CreateWebhookResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, CreateWebhookResponse{Data: CreateWebhookResponseData{Webhook: *w, Secret: w.Secret}})
+}
+
+type UpdateWebhookRequestBody struct {
+	URL      string  `json:"url" binding:"required,max=512"`
+	Events   []Event `json:"events" binding:"required,min=1"`
+	IsActive bool    `json:"is_active"`
+}
+
+// UpdateWebhook 更新 Webhook 订阅
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param webhook body UpdateWebhookRequestBody true "订阅信息"
+// @Success 200 {object} WebhookResponse{data=Webhook}
+// @Router /api/v1/webhooks/{id} [put]
+func UpdateWebhook(c *gin.Context) {
+	var req UpdateWebhookRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, WebhookResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	w, ok := loadWebhookFromParam(c)
+	if !ok {
+		return
+	}
+	if err := UpdateUserWebhook(c.Request.Context(), w, req.URL, req.Events, req.IsActive); err != nil {
+		c.JSON(webhookErrStatus(err), WebhookResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, WebhookResponse{Data: w})
+}
+
+// DeleteWebhook 删除 Webhook 订阅及其投递日志
+// @Tags webhook
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} WebhookResponse
+// @Router /api/v1/webhooks/{id} [delete]
+func DeleteWebhook(c *gin.Context) {
+	w, ok := loadWebhookFromParam(c)
+	if !ok {
+		return
+	}
+	if err := DeleteUserWebhook(c.Request.Context(), w); err != nil {
+		c.JSON(http.StatusInternalServerError, WebhookResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, WebhookResponse{})
+}
+
+type ListWebhookDeliveriesRequest struct {
+	Current int             `json:"current" form:"current" binding:"min=1"`
+	Size    int             `json:"size" form:"size" binding:"min=1,max=100"`
+	Status  *DeliveryStatus `json:"status" form:"status" binding:"omitempty,oneof=0 1 2 3"`
+}
+
+type ListWebhookDeliveriesResponseData struct {
+	Total   int64             `json:"total"`
+	Results []WebhookDelivery `json:"results"`
+}
+
+type ListWebhookDeliveriesResponse struct {
+	ErrorMsg string                            `json:"error_msg"`
+	Data     ListWebhookDeliveriesResponseData `json:"data"`
+}
+
+// ListWebhookDeliveries 分页获取 Webhook 投递日志
+// @Tags webhook
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param request query ListWebhookDeliveriesRequest true "request query"
+// @Success 200 {object} ListWebhookDeliveriesResponse
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func ListWebhookDeliveries(c *gin.Context) {
+	req := &ListWebhookDeliveriesRequest{}
+	if err := c.ShouldBindQuery(req); err != nil {
+		c.JSON(http.StatusBadRequest, ListWebhookDeliveriesResponse{ErrorMsg: err.Error()})
+		return
+	}
+	offset := (req.Current - 1) * req.Size
+
+	w, ok := loadWebhookFromParam(c)
+	if !ok {
+		return
+	}
+
+	query := db.DB(c.Request.Context()).Model(&WebhookDelivery{}).Where("webhook_id = ?", w.ID)
+	if req.Status != nil {
+		query = query.Where("status = ?", *req.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ListWebhookDeliveriesResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	var deliveries []WebhookDelivery
+	if err := query.Order("id DESC").Offset(offset).Limit(req.Size).Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ListWebhookDeliveriesResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListWebhookDeliveriesResponse{
+		Data: ListWebhookDeliveriesResponseData{Total: total, Results: deliveries},
+	})
+}
+
+// RedeliverWebhookDelivery 重新投递失败或已进入死信的事件
+// @Tags webhook
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param delivery_id path int true "投递记录 ID"
+// @Success 200 {object} WebhookResponse{data=WebhookDelivery}
+// @Router /api/v1/webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func RedeliverWebhookDelivery(c *gin.Context) {
+	deliveryID, err := strconv.ParseUint(c.Param("delivery_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, WebhookResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	w, ok := loadWebhookFromParam(c)
+	if !ok {
+		return
+	}
+	if !w.IsActive {
+		c.JSON(http.StatusBadRequest, WebhookResponse{ErrorMsg: WebhookInactive})
+		return
+	}
+
+	d := &WebhookDelivery{}
+	if err := db.DB(c.Request.Context()).Where("id = ? AND webhook_id = ?", deliveryID, w.ID).First(d).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, WebhookResponse{ErrorMsg: DeliveryNotFound})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, WebhookResponse{ErrorMsg: err.Error()})
+		return
+	}
+	if err := Redeliver(c.Request.Context(), d); err != nil {
+		c.JSON(webhookErrStatus(err), WebhookResponse{ErrorMsg: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, WebhookResponse{Data: d})
+}
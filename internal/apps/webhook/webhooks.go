@@ -0,0 +1,419 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/logger"
+	"github.com/linux-do/cdk/internal/task"
+	"github.com/linux-do/cdk/internal/task/schedule"
+	"github.com/linux-do/cdk/internal/utils"
+	"gorm.io/gorm"
+)
+
+// deliveryClient 投递使用的 HTTP 客户端，拒绝连接内网与本机地址
+var deliveryClient = &http.Client{
+	Timeout: deliveryTimeout,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{Timeout: deliveryTimeout, Control: denyPrivateTarget}).DialContext,
+	},
+	CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+}
+
+// denyPrivateTarget 在建立连接前校验解析后的目标地址，防止通过 DNS 指向内网
+func denyPrivateTarget(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || isPrivateIP(ip) {
+		return errors.New(PrivateTargetDenied)
+	}
+	return nil
+}
+
+// isPrivateIP 是否为内网、本机或链路本地地址
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+// validateURL 校验订阅地址格式，字面量为内网 IP 时直接拒绝
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New(InvalidWebhookURL)
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && isPrivateIP(ip) {
+		return errors.New(PrivateTargetDenied)
+	}
+	return nil
+}
+
+// genSecret 生成签名密钥
+func genSecret() (string, error) {
+	var b [24]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b[:]), nil
+}
+
+// Sign 计算投递签名:HMAC-SHA256(secret, "<timestamp>.<body>") 的十六进制，接收方以同样方式校验
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// RetryDelay 投递失败后第 n 次重试的等待时间，按 retryBaseDelay 指数增长并以 retryMaxDelay 封顶
+func RetryDelay(n int) time.Duration {
+	if n < 0 {
+		n = 0
+	}
+	if n > 20 {
+		return retryMaxDelay
+	}
+	delay := retryBaseDelay << n
+	if delay > retryMaxDelay {
+		return retryMaxDelay
+	}
+	return delay
+}
+
+// deliverTaskPayload 投递任务参数
+type deliverTaskPayload struct {
+	DeliveryID uint64 `json:"delivery_id"`
+}
+
+// deliveryTaskID 投递任务 ID,同一投递记录在队列中至多存在一个任务
+func deliveryTaskID(deliveryID uint64) string {
+	return fmt.Sprintf("webhook:delivery:%d", deliveryID)
+}
+
+// enqueueDelivery 下发投递任务，延迟执行以等待事件所在事务提交;任务已在队列中时视为成功
+func enqueueDelivery(ctx context.Context, deliveryID uint64) error {
+	payload, _ := json.Marshal(deliverTaskPayload{DeliveryID: deliveryID})
+	_, err := schedule.AsynqClient.EnqueueContext(
+		ctx,
+		asynq.NewTask(task.DeliverWebhookTask, payload),
+		asynq.TaskID(deliveryTaskID(deliveryID)),
+		asynq.ProcessIn(deliveryDispatchDelay),
+		asynq.MaxRetry(deliveryMaxRetry),
+		asynq.Timeout(2*deliveryTimeout),
+	)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return nil
+	}
+	return err
+}
+
+// Emit 向订阅了该事件的用户 Webhook 投递事件。
+// 投递记录写入传入的 tx,与触发事件的业务变更一同提交或回滚;投递任务延迟执行，读不到记录时重试数次，
+// 仍不存在则视为事务已回滚而跳过。任务下发失败或丢失的记录由 HandleSweepPendingDeliveries 补偿。
+// Webhook 投递不影响业务流程，失败时仅记录日志。
+func Emit(ctx context.Context, tx *gorm.DB, userID uint64, event Event, data interface{}) {
+	var webhooks []Webhook
+	if err := tx.Where("user_id = ? AND is_active = ?", userID, true).Find(&webhooks).Error; err != nil {
+		logger.ErrorF(ctx, "查询用户[%d]的 Webhook 失败: %v", userID, err)
+		return
+	}
+	if len(webhooks) <= 0 {
+		return
+	}
+
+	eventID := uuid.NewString()
+	body, err := json.Marshal(envelope{ID: eventID, Event: event, CreatedAt: time.Now(), Data: data})
+	if err != nil {
+		logger.ErrorF(ctx, "序列化 Webhook 事件[%s]失败: %v", event, err)
+		return
+	}
+
+	for _, w := range webhooks {
+		if !w.Subscribes(event) {
+			continue
+		}
+		delivery := &WebhookDelivery{WebhookID: w.ID, EventID: eventID, Event: event, Payload: string(body)}
+		if err := tx.Create(delivery).Error; err != nil {
+			logger.ErrorF(ctx, "创建 Webhook[%d]投递记录失败: %v", w.ID, err)
+			continue
+		}
+		if err := enqueueDelivery(ctx, delivery.ID); err != nil {
+			logger.ErrorF(ctx, "下发 Webhook[%d]投递任务失败: %v", w.ID, err)
+		}
+	}
+}
+
+// post 发送一次签名请求，返回响应状态码
+func post(ctx context.Context, w *Webhook, d *WebhookDelivery) (int, error) {
+	timestamp := time.Now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader([]byte(d.Payload)))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(d.Event))
+	req.Header.Set(DeliveryHeader, d.EventID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(w.Secret, timestamp, []byte(d.Payload)))
+
+	resp, err := deliveryClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func(body io.ReadCloser) { _ = body.Close() }(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, responseErrorLimit))
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, snippet)
+	}
+	return resp.StatusCode, nil
+}
+
+// deliver 执行一次投递并记录结果;final 表示本次失败后不再重试，需进入死信
+func deliver(ctx context.Context, d *WebhookDelivery, final bool) error {
+	var w Webhook
+	if err := db.DB(ctx).Where("id = ?", d.WebhookID).First(&w).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return markDelivery(ctx, d, DeliveryStatusDead, 0, errors.New(WebhookNotFound))
+		}
+		return err
+	}
+	if !w.IsActive {
+		return markDelivery(ctx, d, DeliveryStatusDead, 0, errors.New(WebhookInactive))
+	}
+
+	status, postErr := post(ctx, &w, d)
+	if postErr == nil {
+		return markDelivery(ctx, d, DeliveryStatusSucceeded, status, nil)
+	}
+	next := DeliveryStatusRetrying
+	if final {
+		next = DeliveryStatusDead
+	}
+	if err := markDelivery(ctx, d, next, status, postErr); err != nil {
+		return err
+	}
+	if final {
+		logger.WarnF(ctx, "Webhook[%d]投递[%d]重试耗尽，进入死信: %v", w.ID, d.ID, postErr)
+		return nil
+	}
+	return postErr
+}
+
+// markDelivery 更新投递结果
+func markDelivery(ctx context.Context, d *WebhookDelivery, status DeliveryStatus, responseStatus int, deliverErr error) error {
+	updates := map[string]interface{}{
+		"status":          status,
+		"attempts":        gorm.Expr("attempts + 1"),
+		"response_status": responseStatus,
+		"last_error":      "",
+	}
+	if deliverErr != nil {
+		msg := []rune(deliverErr.Error())
+		if len(msg) > responseErrorLimit {
+			msg = msg[:responseErrorLimit]
+		}
+		updates["last_error"] = string(msg)
+	}
+	if status == DeliveryStatusSucceeded {
+		now := time.Now()
+		updates["delivered_at"] = &now
+	}
+	return db.DB(ctx).Model(&WebhookDelivery{}).Where("id = ?", d.ID).Updates(updates).Error
+}
+
+// HandleDeliverWebhook 处理 Webhook 投递任务，失败时返回错误交由 asynq 按 RetryDelay 重试
+func HandleDeliverWebhook(ctx context.Context, t *asynq.Task) error {
+	var payload deliverTaskPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("解析任务参数失败: %w", err)
+	}
+
+	var d WebhookDelivery
+	if err := db.DB(ctx).Where("id = ?", payload.DeliveryID).First(&d).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 事件所在事务可能尚未提交，重试数次后再视为已回滚
+			if retried, _ := asynq.GetRetryCount(ctx); retried < deliveryMissingRetry {
+				return fmt.Errorf("投递记录[%d]不存在，等待事件所在事务提交", payload.DeliveryID)
+			}
+			logger.InfoF(ctx, "投递记录[%d]不存在(事件所在事务已回滚)，跳过", payload.DeliveryID)
+			return nil
+		}
+		return err
+	}
+	if d.Status == DeliveryStatusSucceeded || d.Status == DeliveryStatusDead {
+		return nil
+	}
+
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, ok := asynq.GetMaxRetry(ctx)
+	return deliver(ctx, &d, !ok || retried >= maxRetry)
+}
+
+// HandleSweepPendingDeliveries 补偿任务：重新下发长时间处于待投递状态的记录，
+// 覆盖事务提交慢于投递重试、下发任务失败等导致任务丢失的情况;任务仍在队列中时按任务 ID 去重
+func HandleSweepPendingDeliveries(ctx context.Context, _ *asynq.Task) error {
+	var ids []uint64
+	if err := db.DB(ctx).Model(&WebhookDelivery{}).
+		Where("status = ? AND updated_at < ?", DeliveryStatusPending, time.Now().Add(-sweepPendingAfter)).
+		Order("id ASC").
+		Limit(sweepBatchSize).
+		Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := enqueueDelivery(ctx, id); err != nil {
+			return fmt.Errorf("重新下发投递[%d]失败: %w", id, err)
+		}
+	}
+	if len(ids) > 0 {
+		logger.InfoF(ctx, "重新下发 %d 条待投递的 Webhook 记录", len(ids))
+	}
+	return nil
+}
+
+// Redeliver 将失败或死信状态的投递重新放入队列，沿用原事件 ID 与内容
+func Redeliver(ctx context.Context, d *WebhookDelivery) error {
+	if d.Status != DeliveryStatusRetrying && d.Status != DeliveryStatusDead {
+		return errors.New(DeliveryNotRetryable)
+	}
+	result := db.DB(ctx).Model(&WebhookDelivery{}).
+		Where("id = ? AND status = ?", d.ID, d.Status).
+		Update("status", DeliveryStatusPending)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(DeliveryNotRetryable)
+	}
+	d.Status = DeliveryStatusPending
+	return enqueueDelivery(ctx, d.ID)
+}
+
+// validateEvents 校验订阅事件并去重
+func validateEvents(events []Event) (utils.StringArray, error) {
+	seen := make(map[Event]bool, len(events))
+	result := make(utils.StringArray, 0, len(events))
+	for _, e := range events {
+		if !IsValidEvent(e) {
+			return nil, errors.New(InvalidWebhookEvents)
+		}
+		if seen[e] {
+			continue
+		}
+		seen[e] = true
+		result = append(result, string(e))
+	}
+	if len(result) <= 0 {
+		return nil, errors.New(InvalidWebhookEvents)
+	}
+	return result, nil
+}
+
+// CreateUserWebhook 创建订阅并生成签名密钥
+func CreateUserWebhook(ctx context.Context, userID uint64, rawURL string, events []Event) (*Webhook, error) {
+	if err := validateURL(rawURL); err != nil {
+		return nil, err
+	}
+	subscribed, err := validateEvents(events)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := db.DB(ctx).Model(&Webhook{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count >= maxWebhooksPerUser {
+		return nil, errors.New(TooManyWebhooks)
+	}
+
+	secret, err := genSecret()
+	if err != nil {
+		return nil, err
+	}
+	w := &Webhook{UserID: userID, URL: rawURL, Secret: secret, Events: subscribed, IsActive: true}
+	if err := db.DB(ctx).Create(w).Error; err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// UpdateUserWebhook 更新订阅地址、事件与启用状态
+func UpdateUserWebhook(ctx context.Context, w *Webhook, rawURL string, events []Event, isActive bool) error {
+	if err := validateURL(rawURL); err != nil {
+		return err
+	}
+	subscribed, err := validateEvents(events)
+	if err != nil {
+		return err
+	}
+	w.URL = rawURL
+	w.Events = subscribed
+	w.IsActive = isActive
+	return db.DB(ctx).Model(w).Select("url", "events", "is_active").Updates(w).Error
+}
+
+// LoadUserWebhook 加载用户自己的订阅
+func LoadUserWebhook(ctx context.Context, userID, webhookID uint64) (*Webhook, error) {
+	w := &Webhook{}
+	if err := db.DB(ctx).Where("id = ? AND user_id = ?", webhookID, userID).First(w).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(WebhookNotFound)
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
+// DeleteUserWebhook 删除订阅及其投递日志，尚在队列中的任务会因记录不存在而跳过
+func DeleteUserWebhook(ctx context.Context, w *Webhook) error {
+	return db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", w.ID).Delete(&WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(w).Error
+	})
+}
@@ -0,0 +1,211 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/db/dbtest"
+	"github.com/linux-do/cdk/internal/task"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	sig := Sign("secret", 1700000000, body)
+	if sig != Sign("secret", 1700000000, body) {
+		t.Fatal("signature should be deterministic")
+	}
+	if sig == Sign("secret", 1700000001, body) {
+		t.Fatal("signature should cover timestamp")
+	}
+	if sig == Sign("other", 1700000000, body) {
+		t.Fatal("signature should depend on secret")
+	}
+	if len(sig) != len("sha256=")+64 {
+		t.Fatalf("unexpected signature format %q", sig)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	cases := map[int]time.Duration{
+		-1: retryBaseDelay,
+		0:  retryBaseDelay,
+		1:  2 * retryBaseDelay,
+		3:  8 * retryBaseDelay,
+		10: retryMaxDelay,
+		64: retryMaxDelay,
+	}
+	for n, want := range cases {
+		if got := RetryDelay(n); got != want {
+			t.Fatalf("RetryDelay(%d): want %s, got %s", n, want, got)
+		}
+	}
+}
+
+func TestValidateEvents(t *testing.T) {
+	events, err := validateEvents([]Event{EventOrderPaid, EventOrderPaid, EventItemReceived})
+	if err != nil || len(events) != 2 {
+		t.Fatalf("want 2 deduplicated events, got %v (%v)", events, err)
+	}
+	if _, err := validateEvents([]Event{"order.created"}); err == nil {
+		t.Fatal("unknown event should be rejected")
+	}
+	if _, err := validateEvents(nil); err == nil {
+		t.Fatal("empty events should be rejected")
+	}
+
+	w := &Webhook{Events: events}
+	if !w.Subscribes(EventOrderPaid) || w.Subscribes(EventProjectReported) {
+		t.Fatalf("unexpected subscription result for %v", w.Events)
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	cases := map[string]bool{
+		"https://example.com/hook":  true,
+		"http://example.com:8080/h": true,
+		"ftp://example.com/hook":    false,
+		"https:///hook":             false,
+		"example.com/hook":          false,
+		"http://127.0.0.1/hook":     false,
+		"http://10.0.0.8/hook":      false,
+		"http://169.254.169.254/":   false,
+		"http://[::1]/hook":         false,
+	}
+	for raw, ok := range cases {
+		if err := validateURL(raw); (err == nil) != ok {
+			t.Fatalf("%s: want ok=%v, got %v", raw, ok, err)
+		}
+	}
+}
+
+func TestDenyPrivateTarget(t *testing.T) {
+	if err := denyPrivateTarget("tcp", "127.0.0.1:80", nil); err == nil {
+		t.Fatal("loopback should be denied")
+	}
+	if err := denyPrivateTarget("tcp", "192.168.1.1:443", nil); err == nil {
+		t.Fatal("private address should be denied")
+	}
+	if err := denyPrivateTarget("tcp", "93.184.216.34:443", nil); err != nil {
+		t.Fatalf("public address should be allowed: %v", err)
+	}
+}
+
+func TestPost(t *testing.T) {
+	w := &Webhook{Secret: "secret"}
+	d := &WebhookDelivery{EventID: "evt", Event: EventOrderPaid, Payload: `{"id":"evt"}`}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ts, _ := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		if !hmac.Equal([]byte(r.Header.Get(SignatureHeader)), []byte(Sign("secret", ts, body))) {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(EventHeader) != string(EventOrderPaid) || r.Header.Get(DeliveryHeader) != "evt" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	// httptest 监听在本机，测试中绕过内网地址拦截
+	origin := deliveryClient
+	deliveryClient = srv.Client()
+	defer func() { deliveryClient = origin }()
+
+	w.URL = srv.URL
+	if status, err := post(context.Background(), w, d); err != nil || status != http.StatusNoContent {
+		t.Fatalf("want 204, got %d (%v)", status, err)
+	}
+
+	w.Secret = "wrong"
+	if status, err := post(context.Background(), w, d); err == nil || status != http.StatusUnauthorized {
+		t.Fatalf("want 401 error, got %d (%v)", status, err)
+	}
+
+	// 默认客户端拒绝连接本机
+	deliveryClient = origin
+	if _, err := post(context.Background(), w, d); err == nil {
+		t.Fatal("loopback target should be denied")
+	}
+}
+
+func TestHandleDeliverWebhookMissingDelivery(t *testing.T) {
+	dbtest.Setup(t, &Webhook{}, &WebhookDelivery{})
+
+	// 事件所在事务可能尚未提交，记录不存在时返回错误交由 asynq 重试
+	payload, _ := json.Marshal(deliverTaskPayload{DeliveryID: 42})
+	if err := HandleDeliverWebhook(context.Background(), asynq.NewTask(task.DeliverWebhookTask, payload)); err == nil {
+		t.Fatal("want missing delivery retried")
+	}
+}
+
+func TestHandleSweepPendingDeliveries(t *testing.T) {
+	mr := dbtest.Setup(t, &Webhook{}, &WebhookDelivery{})
+	ctx := context.Background()
+
+	stale := time.Now().Add(-2 * sweepPendingAfter)
+	deliveries := []WebhookDelivery{
+		{WebhookID: 1, EventID: "stale", Event: EventOrderPaid, Status: DeliveryStatusPending},
+		{WebhookID: 1, EventID: "fresh", Event: EventOrderPaid, Status: DeliveryStatusPending},
+		{WebhookID: 1, EventID: "done", Event: EventOrderPaid, Status: DeliveryStatusSucceeded},
+	}
+	if err := db.DB(ctx).Create(&deliveries).Error; err != nil {
+		t.Fatalf("create deliveries: %v", err)
+	}
+	if err := db.DB(ctx).Model(&WebhookDelivery{}).Where("event_id IN ?", []string{"stale", "done"}).
+		UpdateColumn("updated_at", stale).Error; err != nil {
+		t.Fatalf("age deliveries: %v", err)
+	}
+
+	// 重复执行时按任务 ID 去重，队列中只保留一个任务
+	for i := 0; i < 2; i++ {
+		if err := HandleSweepPendingDeliveries(ctx, asynq.NewTask(task.SweepPendingWebhooksTask, nil)); err != nil {
+			t.Fatalf("sweep: %v", err)
+		}
+	}
+
+	inspector := asynq.NewInspector(asynq.RedisClientOpt{Addr: mr.Addr()})
+	defer func() { _ = inspector.Close() }()
+	tasks, err := inspector.ListScheduledTasks("default")
+	if err != nil {
+		t.Fatalf("list tasks: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != deliveryTaskID(deliveries[0].ID) {
+		t.Fatalf("want only the stale delivery re-enqueued, got %+v", tasks)
+	}
+}
@@ -25,6 +25,7 @@
 package cmd
 
 import (
+	"github.com/linux-do/cdk/internal/apps/project"
 	"github.com/linux-do/cdk/internal/db/migrator"
 	"github.com/spf13/cobra"
 	"log"
@@ -33,6 +34,9 @@ import (
 var rootCmd = &cobra.Command{
 	Use: "linux-do-cdk",
 	PreRun: func(cmd *cobra.Command, args []string) {
+		if err := project.ValidateItemKeys(); err != nil {
+			log.Fatalf("[CMD] invalid item encryption config: %v\n", err)
+		}
 		migrator.Migrate()
 	},
 	Run: func(cmd *cobra.Command, args []string) {
@@ -47,6 +51,8 @@ var rootCmd = &cobra.Command{
 			schedulerCmd.Run(schedulerCmd, args)
 		case "worker":
 			workerCmd.Run(workerCmd, args)
+		case "rotate-payment-keys":
+			rotatePaymentKeysCmd.Run(rotatePaymentKeysCmd, args)
 		default:
 			log.Fatal("[CMD] unknown app mode\n")
 		}
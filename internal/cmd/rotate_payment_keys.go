@@ -0,0 +1,49 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package cmd
+
+import (
+	"context"
+	"log"
+
+	"github.com/linux-do/cdk/internal/apps/payment"
+	"github.com/spf13/cobra"
+)
+
+// paymentKeyRotateBatchSize 每批重新加密的商户凭据数量
+const paymentKeyRotateBatchSize = 200
+
+var rotatePaymentKeysCmd = &cobra.Command{
+	Use:   "rotate-payment-keys",
+	Short: "Re-encrypt payment client secrets with the current key version",
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Println("[RotatePaymentKeys] 开始使用当前密钥版本重新加密支付凭据")
+		rotated, skipped, err := payment.ReencryptClientSecrets(context.Background(), paymentKeyRotateBatchSize)
+		if err != nil {
+			log.Fatalf("[RotatePaymentKeys] 重新加密失败(已完成 %d 条): %v", rotated, err)
+		}
+		log.Printf("[RotatePaymentKeys] 完成，重新加密 %d 条，跳过 %d 条\n", rotated, skipped)
+	},
+}
@@ -40,6 +40,7 @@ type configModel struct {
 	OpenAPIRisk openAPIRiskConfig `mapstructure:"openapi_risk"`
 	Otel        otelConfig        `mapstructure:"otel"`
 	Payment     PaymentConfig     `mapstructure:"payment"`
+	ForumNotify forumNotifyConfig `mapstructure:"forum_notify"`
 }
 
 // appConfig 应用基本配置
@@ -54,6 +55,8 @@ type appConfig struct {
 	SessionAge        int    `mapstructure:"session_age"`
 	SessionHttpOnly   bool   `mapstructure:"session_http_only"`
 	SessionSecure     bool   `mapstructure:"session_secure"`
+	// TrustedProxies 可信反向代理 IP 或 CIDR,仅信任来自这些地址的 X-Forwarded-For;留空则沿用 gin 默认行为信任任意来源
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 // projectAppConfig 项目相关配置
@@ -64,6 +67,8 @@ type projectAppConfig struct {
 		IntervalSeconds int `mapstructure:"interval_seconds"`
 		MaxCount        int `mapstructure:"max_count"`
 	} `mapstructure:"create_project_rate_limit"`
+	// WaitlistHoldMinutes 候补用户获得库存后的保留时长(分钟),默认 10
+	WaitlistHoldMinutes int `mapstructure:"waitlist_hold_minutes"`
 }
 
 // OAuth2Config OAuth2认证配置
@@ -139,6 +144,9 @@ type scheduleConfig struct {
 	UpdateUserBadgeScoresTaskCron         string `mapstructure:"update_user_badges_scores_task_cron"`
 	UpdateAllBadgesTaskCron               string `mapstructure:"update_all_badges_task_cron"`
 	ExpireStalePaymentOrdersCron          string `mapstructure:"expire_stale_payment_orders_cron"`
+	ReconcilePaymentOrdersCron            string `mapstructure:"reconcile_payment_orders_cron"`
+	OpenRecurringRoundsCron               string `mapstructure:"open_recurring_rounds_cron"`
+	SweepPendingWebhooksCron              string `mapstructure:"sweep_pending_webhooks_cron"`
 }
 
 // workerConfig 工作配置
@@ -149,10 +157,27 @@ type workerConfig struct {
 
 // linuxDoConfig
 type linuxDoConfig struct {
+	BaseURL     string `mapstructure:"base_url"`
 	ApiKey      string `mapstructure:"api_key"`
 	ApiUsername string `mapstructure:"api_username"`
 }
 
+// forumNotifyConfig 论坛通知配置，通过 linuxDo 的 API Key 以 ApiUsername 身份发送私信或回帖;
+// Enabled 控制私信,AnnounceEnabled 控制抽奖项目回帖(还需项目创建者开启)
+type forumNotifyConfig struct {
+	Enabled            bool                           `mapstructure:"enabled"`
+	AnnounceEnabled    bool                           `mapstructure:"announce_enabled"`
+	SiteURL            string                         `mapstructure:"site_url"`
+	RateLimitPerMinute int                            `mapstructure:"rate_limit_per_minute"`
+	Templates          map[string]ForumNotifyTemplate `mapstructure:"templates"`
+}
+
+// ForumNotifyTemplate 论坛消息模板，使用 text/template 语法，可用字段见 forum.TemplateData
+type ForumNotifyTemplate struct {
+	Title string `mapstructure:"title"`
+	Body  string `mapstructure:"body"`
+}
+
 // openAPIRiskConfig OpenAPI 用户风险配置
 type openAPIRiskConfig struct {
 	Enabled          bool     `mapstructure:"enabled"`
@@ -183,7 +208,25 @@ type PaymentConfig struct {
 	RedirectBaseURL string `mapstructure:"redirect_base_url"`
 	// ConfigEncryptionKey 用于加密用户 clientSecret 的密钥,必须是 32 字节长度
 	// 建议直接填 32 字符 ASCII 字符串或 base64 解码得 32 字节
+	// 配置 ConfigEncryptionKeyVersion 后仅用于解密无版本前缀的存量密文
 	ConfigEncryptionKey string `mapstructure:"config_encryption_key"`
+	// ConfigEncryptionKeys 用于加密用户 clientSecret 的密钥环，按版本号索引，每个密钥须恰好 32 字节(原文或 base64)
+	ConfigEncryptionKeys map[string]string `mapstructure:"config_encryption_keys"`
+	// ConfigEncryptionKeyVersion 新写入 clientSecret 使用的密钥版本，留空则沿用 ConfigEncryptionKey;
+	// 轮换时新增版本并切换至该版本，执行 rotate-payment-keys 重新加密存量数据后再移除旧版本
+	ConfigEncryptionKeyVersion string `mapstructure:"config_encryption_key_version"`
+	// ItemEncryptionKeys 用于加密 CDK 内容的密钥,按版本号索引;轮换时新增版本并保留旧版本以解密存量数据
+	ItemEncryptionKeys map[string]string `mapstructure:"item_encryption_keys"`
+	// ItemEncryptionKeyVersion 新写入 CDK 内容使用的密钥版本,留空则不加密;启用加密时必须同时配置 ItemHashKey
+	ItemEncryptionKeyVersion string `mapstructure:"item_encryption_key_version"`
+	// ItemHashKey CDK 内容去重哈希的密钥,须恰好 32 字节(原文或 base64),配置后不可更改也不参与轮换;
+	// 启用内容加密时必须配置，未启用加密时可留空(此时哈希不带密钥)
+	ItemHashKey string `mapstructure:"item_hash_key"`
 	// OrderExpireMinutes 订单 PENDING 状态的最长保留时间(分钟),默认 10
 	OrderExpireMinutes int `mapstructure:"order_expire_minutes"`
+	// NotifyAllowedIPs 支付回调来源 IP 白名单，支持单个 IP 或 CIDR,留空则不限制
+	NotifyAllowedIPs []string `mapstructure:"notify_allowed_ips"`
+	// NotifyReplayWindowSeconds 回调重放保护窗口(秒),0 表示关闭;
+	// 开启后拒绝时间戳超出窗口的回调，窗口内已成功处理的同一回调不再重复处理
+	NotifyReplayWindowSeconds int `mapstructure:"notify_replay_window_seconds"`
 }
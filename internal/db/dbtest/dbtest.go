@@ -0,0 +1,79 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package dbtest
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/glebarez/sqlite"
+	"github.com/hibiken/asynq"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/task/schedule"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Setup 为测试准备临时 SQLite(WAL 模式，允许事务外并发读)与 miniredis,迁移给定模型并替换全局连接，测试结束后自动恢复
+func Setup(t testing.TB, models ...interface{}) *miniredis.Miniredis {
+	t.Helper()
+
+	gormDB, err := gorm.Open(
+		sqlite.Open(fmt.Sprintf("file:%s?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)", filepath.Join(t.TempDir(), "test.db"))),
+		&gorm.Config{
+			DisableForeignKeyConstraintWhenMigrating: true,
+			Logger:                                   logger.Default.LogMode(logger.Silent),
+		},
+	)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		t.Fatalf("load sql db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(models...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	asynqClient := asynq.NewClient(asynq.RedisClientOpt{Addr: mr.Addr()})
+
+	previousClient := schedule.AsynqClient
+	db.SetForTest(gormDB, redisClient)
+	schedule.AsynqClient = asynqClient
+	t.Cleanup(func() {
+		schedule.AsynqClient = previousClient
+		db.SetForTest(nil, nil)
+		_ = asynqClient.Close()
+		_ = redisClient.Close()
+		_ = sqlDB.Close()
+	})
+	return mr
+}
@@ -31,10 +31,13 @@ import (
 	"os"
 	"strings"
 
+	"github.com/linux-do/cdk/internal/apps/notification"
 	"github.com/linux-do/cdk/internal/apps/oauth"
 	"github.com/linux-do/cdk/internal/apps/payment"
 	"github.com/linux-do/cdk/internal/apps/project"
+	"github.com/linux-do/cdk/internal/apps/webhook"
 	"github.com/linux-do/cdk/internal/db"
+	"gorm.io/gorm"
 )
 
 func Migrate() {
@@ -42,19 +45,42 @@ func Migrate() {
 		return
 	}
 
+	// 内容加密上线前的存量明文转义，需在新增 content_hash 列之前执行
+	if err := escapeLegacyItemContents(); err != nil {
+		log.Fatalf("[MySQL] escape legacy item contents failed: %v\n", err)
+	}
+
 	if err := db.DB(context.Background()).AutoMigrate(
 		&oauth.User{},
+		&oauth.APIToken{},
 		&project.Project{},
 		&project.ProjectItem{},
 		&project.ProjectTag{},
 		&project.ProjectReport{},
+		&project.ProjectInvite{},
+		&project.ProjectEntry{},
+		&project.ProjectClaim{},
+		&project.ProjectWaitlist{},
+		&project.ProjectTemplate{},
+		&project.ProjectRound{},
 		&payment.UserPaymentConfig{},
 		&payment.PaymentOrder{},
+		&payment.PaymentDiscrepancy{},
+		&payment.Coupon{},
+		&payment.PaymentNotifyLog{},
+		&webhook.Webhook{},
+		&webhook.WebhookDelivery{},
+		&notification.Notification{},
 	); err != nil {
 		log.Fatalf("[MySQL] auto migrate failed: %v\n", err)
 	}
 	log.Printf("[MySQL] auto migrate success\n")
 
+	// 领取记录迁移
+	if err := migrateProjectClaims(); err != nil {
+		log.Fatalf("[MySQL] migrate project claims failed: %v\n", err)
+	}
+
 	// 创建存储过程
 	if err := createStoredProcedures(); err != nil {
 		log.Fatalf("[MySQL] create stored procedures failed: %v\n", err)
@@ -84,3 +110,46 @@ func createStoredProcedures() error {
 
 	return nil
 }
+
+// escapeLegacyItemContents 内容加密上线前写入的明文若以加密前缀 enc: 开头，转义为 enc::<明文> 以免被当作密文解密;
+// 以 project_items 尚无 content_hash 列判定为加密上线前的表结构，仅执行一次
+func escapeLegacyItemContents() error {
+	tx := db.DB(context.Background())
+	if !tx.Migrator().HasTable(&project.ProjectItem{}) || tx.Migrator().HasColumn(&project.ProjectItem{}, "content_hash") {
+		return nil
+	}
+	// 先放宽 content 列长度，避免转义后超出旧列宽
+	if err := tx.Migrator().AlterColumn(&project.ProjectItem{}, "Content"); err != nil {
+		return err
+	}
+	var rows []struct {
+		ID      uint64
+		Content string
+	}
+	if err := tx.Table("project_items").Select("id, content").Where("content LIKE ?", "enc:%").Scan(&rows).Error; err != nil {
+		return err
+	}
+	return tx.Transaction(func(tx *gorm.DB) error {
+		for _, row := range rows {
+			if err := tx.Table("project_items").Where("id = ?", row.ID).Update("content", "enc::"+row.Content).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// migrateProjectClaims 以 project_claims 取代 project_items 上的 (project_id, receiver_id) 唯一索引:
+// 为存量领取补登记领取记录后删除旧索引，仅在旧索引存在时执行一次
+func migrateProjectClaims() error {
+	tx := db.DB(context.Background())
+	if !tx.Migrator().HasIndex(&project.ProjectItem{}, "idx_project_receiver") {
+		return nil
+	}
+	if err := tx.Exec(`INSERT IGNORE INTO project_claims (project_id, user_id, seq, item_id, created_at)
+		SELECT project_id, receiver_id, 1, id, COALESCE(received_at, updated_at)
+		FROM project_items WHERE receiver_id IS NOT NULL`).Error; err != nil {
+		return err
+	}
+	return tx.Migrator().DropIndex(&project.ProjectItem{}, "idx_project_receiver")
+}
@@ -0,0 +1,71 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2025 linux.do
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package migrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/linux-do/cdk/internal/apps/project"
+	"github.com/linux-do/cdk/internal/db"
+	"github.com/linux-do/cdk/internal/db/dbtest"
+)
+
+func TestEscapeLegacyItemContents(t *testing.T) {
+	dbtest.Setup(t)
+	ctx := context.Background()
+
+	// 加密上线前的表结构，没有 content_hash 列
+	if err := db.DB(ctx).Exec("CREATE TABLE project_items (id INTEGER PRIMARY KEY, project_id VARCHAR(64), content VARCHAR(1024))").Error; err != nil {
+		t.Fatalf("create legacy table: %v", err)
+	}
+	legacy := []string{"enc:abc:xyz", "enc:foo", "CDK-001"}
+	for i, content := range legacy {
+		if err := db.DB(ctx).Exec("INSERT INTO project_items (id, project_id, content) VALUES (?, 'p1', ?)", i+1, content).Error; err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+
+	if err := escapeLegacyItemContents(); err != nil {
+		t.Fatalf("escape: %v", err)
+	}
+	if err := db.DB(ctx).AutoMigrate(&project.ProjectItem{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	// 新表结构下再次执行不会重复转义
+	if err := escapeLegacyItemContents(); err != nil {
+		t.Fatalf("escape again: %v", err)
+	}
+
+	for i, want := range legacy {
+		var item project.ProjectItem
+		if err := db.DB(ctx).First(&item, i+1).Error; err != nil {
+			t.Fatalf("load item: %v", err)
+		}
+		if plain, err := item.PlainContent(); err != nil || plain != want {
+			t.Fatalf("want legacy content %q readable, got %q (stored %q, %v)", want, plain, item.Content, err)
+		}
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"strconv"
 
 	"github.com/gin-contrib/sessions"
@@ -37,9 +38,11 @@ import (
 	"github.com/linux-do/cdk/internal/apps/admin"
 	"github.com/linux-do/cdk/internal/apps/dashboard"
 	"github.com/linux-do/cdk/internal/apps/health"
+	"github.com/linux-do/cdk/internal/apps/notification"
 	"github.com/linux-do/cdk/internal/apps/oauth"
 	"github.com/linux-do/cdk/internal/apps/payment"
 	"github.com/linux-do/cdk/internal/apps/project"
+	"github.com/linux-do/cdk/internal/apps/webhook"
 	"github.com/linux-do/cdk/internal/config"
 	"github.com/linux-do/cdk/internal/otel_trace"
 	swaggerFiles "github.com/swaggo/files"
@@ -59,6 +62,16 @@ func Serve() {
 	r := gin.New()
 	r.Use(gin.Recovery())
 
+	// 配置可信代理后仅信任其传入的 X-Forwarded-For,避免伪造来源 IP 绕过 IP 白名单与同 IP 限制;
+	// 未配置时沿用 gin 默认行为(信任任意来源),兼容现有反向代理部署
+	if len(config.Config.App.TrustedProxies) > 0 {
+		if err := r.SetTrustedProxies(config.Config.App.TrustedProxies); err != nil {
+			log.Fatalf("[API] set trusted proxies failed: %v\n", err)
+		}
+	} else {
+		log.Println("[API] app.trusted_proxies not configured, X-Forwarded-For is trusted from any source")
+	}
+
 	// Session
 	sessionStore, err := redis.NewStoreWithDB(
 		config.Config.Redis.MinIdleConn,
@@ -113,9 +126,29 @@ func Serve() {
 				projectRouter.GET("/mine", project.ListMyProjects)
 				projectRouter.GET("", project.ListProjects)
 				projectRouter.POST("", project.ProjectCreateRateLimitMiddleware(), project.CreateProject)
+				projectRouter.POST("/:id/clone", project.ProjectCreatorPermMiddleware(), project.ProjectCreateRateLimitMiddleware(), project.CloneProject)
 				projectRouter.PUT("/:id", project.ProjectCreatorPermMiddleware(), project.UpdateProject)
 				projectRouter.DELETE("/:id", project.ProjectCreatorPermMiddleware(), project.DeleteProject)
 				projectRouter.GET("/:id/receivers", project.ProjectCreatorPermMiddleware(), project.ListProjectReceivers)
+				projectRouter.POST("/:id/items/import", project.ProjectCreatorPermMiddleware(), project.ImportProjectItems)
+				projectRouter.GET("/:id/items/export", project.ProjectCreatorPermMiddleware(), project.ExportProjectItems)
+				projectRouter.DELETE("/:id/items", project.ProjectCreatorPermMiddleware(), project.DeleteProjectItems)
+				projectRouter.PUT("/:id/items/:item_id", project.ProjectCreatorPermMiddleware(), project.ReplaceProjectItem)
+				projectRouter.GET("/:id/recurrence", project.ProjectCreatorPermMiddleware(), project.GetProjectRecurrence)
+				projectRouter.PUT("/:id/recurrence", project.ProjectCreatorPermMiddleware(), project.SetProjectRecurrence)
+				projectRouter.DELETE("/:id/recurrence", project.ProjectCreatorPermMiddleware(), project.ClearProjectRecurrence)
+				projectRouter.POST("/:id/reserve", project.ProjectCreatorPermMiddleware(), project.AddProjectReserveItems)
+				projectRouter.GET("/:id/rounds", project.ProjectCreatorPermMiddleware(), project.ListProjectRounds)
+				projectRouter.GET("/:id/invites", project.ProjectCreatorPermMiddleware(), project.ListProjectInvites)
+				projectRouter.POST("/:id/invites", project.ProjectCreatorPermMiddleware(), project.CreateProjectInvites)
+				projectRouter.DELETE("/:id/invites/:invite_id", project.ProjectCreatorPermMiddleware(), project.RevokeProjectInvite)
+				projectRouter.POST("/:id/entries", project.EnterProject)
+				projectRouter.GET("/:id/draw", project.GetProjectDraw)
+				projectRouter.GET("/:id/price", project.GetProjectPrice)
+				projectRouter.GET("/:id/waitlist", project.GetProjectWaitlist)
+				projectRouter.POST("/:id/waitlist", project.JoinProjectWaitlist)
+				projectRouter.DELETE("/:id/waitlist", project.LeaveProjectWaitlist)
+				projectRouter.POST("/:id/waitlist/claim", project.ClaimProjectWaitlist)
 				projectRouter.GET("/:id/pending-payment", payment.GetPendingPayment)
 				projectRouter.POST("/:id/receive", project.ReceiveProjectMiddleware(), payment.DispatchReceive)
 				projectRouter.POST("/:id/report", project.ReportProject)
@@ -124,19 +157,77 @@ func Serve() {
 				projectRouter.GET("/:id", project.GetProject)
 			}
 
+			// Project Template
+			templateRouter := apiV1Router.Group("/project-templates")
+			templateRouter.Use(oauth.LoginRequired())
+			{
+				templateRouter.GET("", project.ListProjectTemplates)
+				templateRouter.POST("", project.CreateProjectTemplate)
+				templateRouter.PUT("/:id", project.UpdateProjectTemplate)
+				templateRouter.DELETE("/:id", project.DeleteProjectTemplate)
+				templateRouter.POST("/:id/projects", project.ProjectCreateRateLimitMiddleware(), project.CreateProjectFromTemplate)
+			}
+
 			// User (支付配置等用户级设置)
 			userRouter := apiV1Router.Group("/users")
 			userRouter.Use(oauth.LoginRequired())
 			{
 				userRouter.GET("/payment-config", payment.GetPaymentConfig)
 				userRouter.PUT("/payment-config", payment.UpsertPaymentConfig)
+				userRouter.POST("/payment-config/verify", payment.VerifyPaymentConfig)
 				userRouter.DELETE("/payment-config", payment.DeletePaymentConfig)
+				userRouter.GET("/tokens", oauth.ListAPITokens)
+				userRouter.POST("/tokens", oauth.CreateAPIToken)
+				userRouter.DELETE("/tokens/:id", oauth.RevokeAPIToken)
 			}
 
-			// Payment 回调(易支付 GET 请求,无 session)
+			// Notification 站内通知
+			notificationRouter := apiV1Router.Group("/notifications")
+			notificationRouter.Use(oauth.LoginRequired())
+			{
+				notificationRouter.GET("", notification.ListNotifications)
+				notificationRouter.GET("/unread-count", notification.GetUnreadCount)
+				notificationRouter.PUT("/read-all", notification.MarkAllNotificationsRead)
+				notificationRouter.PUT("/:id/read", notification.MarkNotificationRead)
+			}
+
+			// Webhook 事件订阅
+			webhookRouter := apiV1Router.Group("/webhooks")
+			webhookRouter.Use(oauth.LoginRequired())
+			{
+				webhookRouter.GET("", webhook.ListWebhooks)
+				webhookRouter.POST("", webhook.CreateWebhook)
+				webhookRouter.PUT("/:id", webhook.UpdateWebhook)
+				webhookRouter.DELETE("/:id", webhook.DeleteWebhook)
+				webhookRouter.GET("/:id/deliveries", webhook.ListWebhookDeliveries)
+				webhookRouter.POST("/:id/deliveries/:delivery_id/redeliver", webhook.RedeliverWebhookDelivery)
+			}
+
+			// Payment 回调(易支付 GET 请求,其余渠道按类型区分,无 session)
 			paymentRouter := apiV1Router.Group("/payment")
 			{
 				paymentRouter.GET("/notify", payment.HandleNotifyHTTP)
+				paymentRouter.GET("/notify/:provider", payment.HandleProviderNotifyHTTP)
+				paymentRouter.POST("/notify/:provider", payment.HandleProviderNotifyHTTP)
+			}
+
+			// Payment Order
+			paymentOrderRouter := apiV1Router.Group("/payment/orders")
+			paymentOrderRouter.Use(oauth.LoginRequired())
+			{
+				paymentOrderRouter.GET("/paid", payment.ListPaidOrders)
+				paymentOrderRouter.GET("/sold", payment.ListSoldOrders)
+				paymentOrderRouter.GET("/sold/summary", payment.GetSalesSummary)
+				paymentOrderRouter.POST("/:out_trade_no/refund", payment.RefundOrderHTTP)
+			}
+
+			// Payment Coupon
+			paymentCouponRouter := apiV1Router.Group("/payment/coupons")
+			paymentCouponRouter.Use(oauth.LoginRequired())
+			{
+				paymentCouponRouter.GET("", payment.ListCoupons)
+				paymentCouponRouter.POST("", payment.CreateCoupon)
+				paymentCouponRouter.DELETE("/:id", payment.RevokeCoupon)
 			}
 
 			// Tag
@@ -164,12 +255,25 @@ func Serve() {
 					projectAdminRouter.PUT("/:id/review", admin.ReviewProject)
 				}
 
+				// Payment
+				paymentAdminRouter := adminRouter.Group("/payment")
+				{
+					paymentAdminRouter.GET("/discrepancies", payment.ListDiscrepancies)
+					paymentAdminRouter.PUT("/discrepancies/:id/resolve", payment.ResolveDiscrepancy)
+					paymentAdminRouter.POST("/orders/:out_trade_no/retry-refund", payment.RetryRefundHTTP)
+					paymentAdminRouter.GET("/notify-logs", payment.ListNotifyLogs)
+					paymentAdminRouter.POST("/notify-logs/:id/replay", payment.ReplayNotifyLog)
+				}
+
 				// User
 				userAdminRouter := adminRouter.Group("/users")
 				{
 					userAdminRouter.GET("", admin.ListUsers)
 				}
 			}
+
+			// API Token 可访问的接口及所需授权范围，未列出的接口仅支持 session 登录
+			allowTokenRoutes(apiV1Router.BasePath())
 		}
 	}
 
@@ -178,3 +282,34 @@ func Serve() {
 		log.Fatalf("[API] serve api failed: %v\n", err)
 	}
 }
+
+// allowTokenRoutes 声明 API Token 可访问的接口，路径需与路由注册时保持一致
+func allowTokenRoutes(basePath string) {
+	routes := []struct {
+		method string
+		path   string
+		scope  oauth.TokenScope
+	}{
+		// 项目管理
+		{http.MethodGet, "/projects/mine", oauth.TokenScopeProjectsWrite},
+		{http.MethodGet, "/projects/:id", oauth.TokenScopeProjectsWrite},
+		{http.MethodPost, "/projects", oauth.TokenScopeProjectsWrite},
+		{http.MethodPost, "/projects/:id/clone", oauth.TokenScopeProjectsWrite},
+		{http.MethodPut, "/projects/:id", oauth.TokenScopeProjectsWrite},
+		{http.MethodPost, "/projects/:id/items/import", oauth.TokenScopeProjectsWrite},
+		{http.MethodDelete, "/projects/:id/items", oauth.TokenScopeProjectsWrite},
+		{http.MethodPut, "/projects/:id/items/:item_id", oauth.TokenScopeProjectsWrite},
+		{http.MethodPost, "/projects/:id/reserve", oauth.TokenScopeProjectsWrite},
+		{http.MethodPost, "/project-templates/:id/projects", oauth.TokenScopeProjectsWrite},
+		// 项目内容
+		{http.MethodGet, "/projects/:id/items/export", oauth.TokenScopeItemsRead},
+		{http.MethodGet, "/projects/:id/receivers", oauth.TokenScopeItemsRead},
+		// 订单
+		{http.MethodGet, "/payment/orders/paid", oauth.TokenScopeOrdersRead},
+		{http.MethodGet, "/payment/orders/sold", oauth.TokenScopeOrdersRead},
+		{http.MethodGet, "/payment/orders/sold/summary", oauth.TokenScopeOrdersRead},
+	}
+	for _, route := range routes {
+		oauth.AllowTokenRoute(route.method, basePath+route.path, route.scope)
+	}
+}
@@ -30,4 +30,17 @@ const (
 	UpdateSingleUserBadgeScoreTask = "user:badge:update_single_score_task"
 
 	ExpireStalePaymentOrdersTask = "payment:expire_stale_orders"
+	ReconcilePaymentOrdersTask   = "payment:reconcile_orders"
+
+	DrawProjectLotteryTask   = "project:lottery:draw"
+	OfferProjectWaitlistTask = "project:waitlist:offer"
+	ExpireWaitlistHoldTask   = "project:waitlist:expire_hold"
+	OpenRecurringRoundsTask  = "project:recurrence:open_rounds"
+	AnnounceProgressTask     = "project:topic:announce_progress"
+
+	DeliverWebhookTask       = "webhook:deliver"
+	SweepPendingWebhooksTask = "webhook:sweep_pending"
+
+	SendForumPrivateMessageTask = "forum:pm:send"
+	SendForumTopicReplyTask     = "forum:topic:reply"
 )
@@ -74,6 +74,21 @@ func StartScheduler() error {
 			return
 		}
 
+		// 每分钟向支付渠道核对一次即将过期与退款中的订单
+		if _, err = scheduler.Register(config.Config.Schedule.ReconcilePaymentOrdersCron, asynq.NewTask(task.ReconcilePaymentOrdersTask, nil)); err != nil {
+			return
+		}
+
+		// 每分钟扫描一次到期的周期项目并开启新一轮
+		if _, err = scheduler.Register(config.Config.Schedule.OpenRecurringRoundsCron, asynq.NewTask(task.OpenRecurringRoundsTask, nil)); err != nil {
+			return
+		}
+
+		// 每分钟补偿一次丢失投递任务的 Webhook 记录
+		if _, err = scheduler.Register(config.Config.Schedule.SweepPendingWebhooksCron, asynq.NewTask(task.SweepPendingWebhooksTask, nil)); err != nil {
+			return
+		}
+
 		// 启动调度器
 		err = scheduler.Run()
 	})
@@ -26,9 +26,13 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"github.com/hibiken/asynq"
+	"github.com/linux-do/cdk/internal/apps/forum"
 	"github.com/linux-do/cdk/internal/apps/oauth"
 	"github.com/linux-do/cdk/internal/apps/payment"
+	"github.com/linux-do/cdk/internal/apps/project"
+	"github.com/linux-do/cdk/internal/apps/webhook"
 	"github.com/linux-do/cdk/internal/config"
 	"github.com/linux-do/cdk/internal/db"
 	"github.com/linux-do/cdk/internal/task"
@@ -61,6 +65,8 @@ func StartWorker() error {
 				"low":      1,
 			},
 			StrictPriority: true,
+			RetryDelayFunc: retryDelay,
+			IsFailure:      isFailure,
 		},
 	)
 
@@ -71,6 +77,32 @@ func StartWorker() error {
 	mux.HandleFunc(task.UpdateUserBadgeScoresTask, oauth.HandleUpdateUserBadgeScores)
 	mux.HandleFunc(task.UpdateSingleUserBadgeScoreTask, oauth.HandleUpdateSingleUserBadgeScore)
 	mux.HandleFunc(task.ExpireStalePaymentOrdersTask, payment.HandleExpireStaleOrders)
+	mux.HandleFunc(task.ReconcilePaymentOrdersTask, payment.HandleReconcileOrders)
+	mux.HandleFunc(task.DrawProjectLotteryTask, project.HandleDrawLottery)
+	mux.HandleFunc(task.OfferProjectWaitlistTask, payment.HandleWaitlistOffer)
+	mux.HandleFunc(task.ExpireWaitlistHoldTask, project.HandleExpireWaitlistHold)
+	mux.HandleFunc(task.OpenRecurringRoundsTask, project.HandleOpenRecurringRounds)
+	mux.HandleFunc(task.DeliverWebhookTask, webhook.HandleDeliverWebhook)
+	mux.HandleFunc(task.SweepPendingWebhooksTask, webhook.HandleSweepPendingDeliveries)
+	mux.HandleFunc(task.SendForumPrivateMessageTask, forum.HandleSendPrivateMessage)
+	mux.HandleFunc(task.SendForumTopicReplyTask, forum.HandleSendTopicReply)
+	mux.HandleFunc(task.AnnounceProgressTask, project.HandleAnnounceProgress)
 	// 启动服务器
 	return asynqServer.Run(mux)
 }
+
+// retryDelay Webhook 投递按自身的指数退避重试，论坛消息限流时等待下一窗口，其余任务沿用 asynq 默认策略
+func retryDelay(n int, e error, t *asynq.Task) time.Duration {
+	switch {
+	case t.Type() == task.DeliverWebhookTask:
+		return webhook.RetryDelay(n)
+	case errors.Is(e, forum.ErrRateLimited):
+		return forum.RateLimitDelay()
+	}
+	return asynq.DefaultRetryDelayFunc(n, e, t)
+}
+
+// isFailure 论坛消息限流不计入失败次数
+func isFailure(err error) bool {
+	return !errors.Is(err, forum.ErrRateLimited)
+}